@@ -198,6 +198,9 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		v := ctx.Uint64(utils.OverrideKromaMPT.Name)
 		cfg.Eth.OverrideKromaMPT = &v
 	}
+	if ctx.IsSet(utils.KromaChainConfigFlag.Name) {
+		cfg.Eth.KromaChainConfig = ctx.String(utils.KromaChainConfigFlag.Name)
+	}
 	// [Kroma: END]
 
 	if ctx.IsSet(utils.OverrideVerkle.Name) {
@@ -208,6 +211,36 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	cfg.Eth.CircuitParams = new(params.CircuitParams)
 	cfg.Eth.KromaZKTrie = ctx.Bool(utils.KromaZKTrie.Name)
 	cfg.Eth.DisableMPTMigration = ctx.Bool(utils.DisableMPTMigrationFlag.Name)
+	if ctx.IsSet(utils.MPTMigrationWorkersFlag.Name) {
+		cfg.Eth.MPTMigrationWorkers = ctx.Int(utils.MPTMigrationWorkersFlag.Name)
+	}
+	if ctx.IsSet(utils.MPTMigrationMemoryCapFlag.Name) {
+		cfg.Eth.MPTMigrationMemoryCap = ctx.Int(utils.MPTMigrationMemoryCapFlag.Name)
+	}
+	if ctx.IsSet(utils.MPTMigrationValidationFlag.Name) {
+		cfg.Eth.MPTMigrationValidation = ctx.String(utils.MPTMigrationValidationFlag.Name)
+	}
+	if ctx.IsSet(utils.MPTMigrationSampleAccountsFlag.Name) {
+		cfg.Eth.MPTMigrationSampleAccounts = ctx.Int(utils.MPTMigrationSampleAccountsFlag.Name)
+	}
+	if ctx.IsSet(utils.MPTMigrationSampleSlotsFlag.Name) {
+		cfg.Eth.MPTMigrationSampleSlots = ctx.Int(utils.MPTMigrationSampleSlotsFlag.Name)
+	}
+	if ctx.IsSet(utils.MPTMigrationSampleSeedFlag.Name) {
+		cfg.Eth.MPTMigrationSampleSeed = ctx.Int64(utils.MPTMigrationSampleSeedFlag.Name)
+	}
+	if ctx.IsSet(utils.BlockTraceCacheFlag.Name) {
+		cfg.Eth.BlockTraceCache = ctx.Bool(utils.BlockTraceCacheFlag.Name)
+	}
+	if ctx.IsSet(utils.BlockTraceCacheOnImportFlag.Name) {
+		cfg.Eth.BlockTraceCacheOnImport = ctx.Bool(utils.BlockTraceCacheOnImportFlag.Name)
+	}
+	if ctx.IsSet(utils.BlockTraceCacheRetentionFlag.Name) {
+		cfg.Eth.BlockTraceCacheRetention = ctx.Uint64(utils.BlockTraceCacheRetentionFlag.Name)
+	}
+	if ctx.IsSet(utils.BlockTraceCacheSizeFlag.Name) {
+		cfg.Eth.BlockTraceCacheSize = ctx.Int(utils.BlockTraceCacheSizeFlag.Name)
+	}
 	if ctx.IsSet(utils.MaxTxsFlag.Name) {
 		maxTxs := ctx.Int(utils.MaxTxsFlag.Name)
 		cfg.Eth.CircuitParams.MaxTxs = &maxTxs
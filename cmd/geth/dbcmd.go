@@ -18,6 +18,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -31,12 +32,15 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/migration"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/olekukonko/tablewriter"
 	"github.com/urfave/cli/v2"
@@ -69,6 +73,7 @@ Remove blockchain and state databases`,
 			dbExportCmd,
 			dbMetadataCmd,
 			dbCheckStateContentCmd,
+			dbMigrateZktCmd,
 		},
 	}
 	dbInspectCmd = &cli.Command{
@@ -193,6 +198,27 @@ WARNING: This is a low-level operation which may cause database corruption!`,
 		}, utils.NetworkFlags, utils.DatabaseFlags),
 		Description: "Shows metadata about the chain status.",
 	}
+	dbMigrateZktCmd = &cli.Command{
+		Action:    migrateZkt,
+		Name:      "migrate-zkt",
+		Usage:     "Migrate the ZKTrie state of a stopped node to Merkle Patricia Tree",
+		ArgsUsage: "<block number or hash (optional)>",
+		Flags: flags.Merge([]cli.Flag{
+			utils.SyncModeFlag,
+			utils.MPTMigrationWorkersFlag,
+			utils.MPTMigrationMemoryCapFlag,
+			utils.MPTMigrationValidationFlag,
+			utils.MPTMigrationSampleAccountsFlag,
+			utils.MPTMigrationSampleSlotsFlag,
+			utils.MPTMigrationSampleSeedFlag,
+		}, utils.NetworkFlags, utils.DatabaseFlags),
+		Description: `This command converts the ZKTrie state at the given block (the safe block by
+default) into a Merkle Patricia Tree, validates the result against the ZKTrie state and
+records it as the migrated state. The background validation mode is not supported as
+the command exits once the state has been migrated. The node applies the state changes of the following
+blocks to the migrated state once it is started again. An interrupted migration is
+resumed from the last checkpoint.`,
+	}
 )
 
 func removeDB(ctx *cli.Context) error {
@@ -737,3 +763,99 @@ func showMetaData(ctx *cli.Context) error {
 	table.Render()
 	return nil
 }
+
+// migrationBackend provides the state migrator with the chain of a stopped node.
+type migrationBackend struct {
+	db    ethdb.Database
+	chain *core.BlockChain
+}
+
+func (b *migrationBackend) ChainDb() ethdb.Database      { return b.db }
+func (b *migrationBackend) BlockChain() *core.BlockChain { return b.chain }
+
+func migrateZkt(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		return fmt.Errorf("max 1 argument: %v", ctx.Command.ArgsUsage)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, db := utils.MakeChain(ctx, stack, false)
+	defer db.Close()
+	defer chain.Stop()
+
+	if !chain.Config().Zktrie {
+		return errors.New("the state is not a ZKTrie")
+	}
+	if ref := chain.GetMigratedRef(); ref.Root() != (common.Hash{}) {
+		log.Info("The state has already been migrated", "number", ref.BlockNumber(), "root", ref.Root())
+		return nil
+	}
+	var header *types.Header
+	if ctx.NArg() == 1 {
+		arg := ctx.Args().First()
+		if hashish(arg) {
+			header = chain.GetHeaderByHash(common.HexToHash(arg))
+		} else {
+			number, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid block number %q: %w", arg, err)
+			}
+			header = chain.GetHeaderByNumber(number)
+		}
+		if header == nil {
+			return fmt.Errorf("block %s not found", arg)
+		}
+	} else {
+		header = chain.CurrentSafeBlock()
+		if header == nil {
+			return errors.New("no safe block, specify the block to migrate")
+		}
+	}
+	if chain.Config().IsKromaMPT(header.Time) {
+		return fmt.Errorf("block %d is after the KromaMPT fork", header.Number)
+	}
+	if !chain.HasState(header.Root) {
+		return fmt.Errorf("state of block %d is not available", header.Number)
+	}
+	// Once started, the node applies the state changes of the following blocks to the
+	// migrated state, so they must be available.
+	if head := chain.CurrentBlock(); header.Number.Uint64() < head.Number.Uint64() {
+		if _, err := core.ReadStateChanges(db, header.Number.Uint64()+1); err != nil {
+			return fmt.Errorf("state changes of block %d are not available: %w", header.Number.Uint64()+1, err)
+		}
+	}
+
+	validation := ctx.String(utils.MPTMigrationValidationFlag.Name)
+	if validation == migration.BackgroundValidation {
+		return errors.New("background validation is not supported by the offline migration")
+	}
+	migrator, err := migration.NewStateMigrator(&migrationBackend{db: db, chain: chain}, &migration.Config{
+		Workers:        ctx.Int(utils.MPTMigrationWorkersFlag.Name),
+		MemoryCap:      ctx.Int(utils.MPTMigrationMemoryCapFlag.Name),
+		Validation:     validation,
+		SampleAccounts: ctx.Int(utils.MPTMigrationSampleAccountsFlag.Name),
+		SampleSlots:    ctx.Int(utils.MPTMigrationSampleSlotsFlag.Name),
+		SampleSeed:     ctx.Int64(utils.MPTMigrationSampleSeedFlag.Name),
+	})
+	if err != nil {
+		return err
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+	go func() {
+		<-interrupt
+		log.Info("Interrupted during migration, the progress is kept from the last checkpoint")
+		migrator.Stop()
+	}()
+
+	start := time.Now()
+	if err := migrator.MigrateState(header); err != nil {
+		log.Error("Failed to migrate state", "number", header.Number, "err", err)
+		return err
+	}
+	ref := migrator.MigratedRef()
+	log.Info("Migrated the state", "number", ref.BlockNumber(), "zkroot", header.Root, "root", ref.Root(), "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
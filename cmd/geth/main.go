@@ -73,6 +73,7 @@ var (
 		utils.OverrideOptimismEcotone,
 		utils.OverrideOptimismInterop,
 		utils.OverrideKromaMPT,
+		utils.KromaChainConfigFlag,
 		utils.EnablePersonal,
 		utils.TxPoolLocalsFlag,
 		utils.TxPoolNoLocalsFlag,
@@ -151,21 +152,32 @@ var (
 		utils.GpoMaxGasPriceFlag,
 		utils.GpoIgnoreGasPriceFlag,
 		utils.GpoMinSuggestedPriorityFeeFlag,
-		/* [kroma unsupported]
 		utils.RollupSequencerHTTPFlag,
-		*/
+		utils.RollupSequencerHTTPTimeoutFlag,
+		utils.RollupSequencerHTTPRetriesFlag,
 		utils.RollupHistoricalRPCFlag,
 		utils.RollupHistoricalRPCTimeoutFlag,
-		/* [kroma unsupported]
 		utils.RollupDisableTxPoolGossipFlag,
-		*/
+		utils.RollupEnableTxPoolAdmissionFlag,
 		utils.RollupComputePendingBlock,
-		/* [kroma unsupported]
 		utils.RollupHaltOnIncompatibleProtocolVersionFlag,
+		utils.RollupSequencerTxConditionalEnabledFlag,
+		utils.RollupSequencerTxConditionalCostRateLimitFlag,
+		/* [kroma unsupported]
 		utils.RollupSuperchainUpgradesFlag,
 		*/
 		utils.KromaZKTrie,
 		utils.DisableMPTMigrationFlag,
+		utils.MPTMigrationWorkersFlag,
+		utils.MPTMigrationMemoryCapFlag,
+		utils.MPTMigrationValidationFlag,
+		utils.MPTMigrationSampleAccountsFlag,
+		utils.MPTMigrationSampleSlotsFlag,
+		utils.MPTMigrationSampleSeedFlag,
+		utils.BlockTraceCacheFlag,
+		utils.BlockTraceCacheOnImportFlag,
+		utils.BlockTraceCacheRetentionFlag,
+		utils.BlockTraceCacheSizeFlag,
 		configFileFlag,
 		utils.LogDebugFlag,
 		utils.LogBacktraceAtFlag,
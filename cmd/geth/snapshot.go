@@ -59,6 +59,8 @@ will prune historical state data with the help of the state snapshot.
 All trie nodes and contract codes that do not belong to the specified
 version state will be deleted from the database. After pruning, only
 two version states are available: genesis and the specific one.
+For zktrie states, the preimages of the trie keys which don't belong
+to these states are deleted as well.
 
 The default pruning target is the HEAD-127 state.
 
@@ -174,7 +176,9 @@ func pruneState(ctx *cli.Context) error {
 	chaindb := utils.MakeChainDatabase(ctx, stack, false)
 	defer chaindb.Close()
 
-	if rawdb.ReadStateScheme(chaindb, cfg.Eth.Genesis.Config.Zktrie) != rawdb.HashScheme {
+	// The zktrie states are stored in hash mode as well, keyed by the byte
+	// reversed node hash, so only reject path mode here.
+	if rawdb.ReadStateScheme(chaindb, cfg.Eth.Genesis.Config.Zktrie) == rawdb.PathScheme {
 		log.Crit("Offline pruning is not required for path scheme")
 	}
 	prunerconfig := pruner.Config{
@@ -284,6 +284,11 @@ var (
 		Usage:    "Manually specify the Kroma mpt transition timestamp, overriding the bundled setting",
 		Category: flags.EthCategory,
 	}
+	KromaChainConfigFlag = &cli.StringFlag{
+		Name:     "kroma.chainconfig",
+		Usage:    "JSON file (or directory of JSON files) of the Kroma chain registry, overriding the bundled hardfork times",
+		Category: flags.EthCategory,
+	}
 	// [Kroma: END]
 	SyncModeFlag = &flags.TextMarshalerFlag{
 		Name:     "syncmode",
@@ -873,14 +878,24 @@ var (
 		Category: flags.GasPriceCategory,
 	}
 
-	/* [kroma unsupported]
 	// Rollup Flags
 	RollupSequencerHTTPFlag = &cli.StringFlag{
 		Name:     "rollup.sequencerhttp",
 		Usage:    "HTTP endpoint for the sequencer mempool",
 		Category: flags.RollupCategory,
 	}
-	*/
+	RollupSequencerHTTPTimeoutFlag = &cli.DurationFlag{
+		Name:     "rollup.sequencerhttp.timeout",
+		Usage:    "Timeout for forwarding a transaction to the sequencer",
+		Value:    ethconfig.Defaults.RollupSequencerHTTPTimeout,
+		Category: flags.RollupCategory,
+	}
+	RollupSequencerHTTPRetriesFlag = &cli.IntFlag{
+		Name:     "rollup.sequencerhttp.retries",
+		Usage:    "Number of retries for forwarding a transaction to the sequencer that failed in transport",
+		Value:    ethconfig.Defaults.RollupSequencerHTTPRetries,
+		Category: flags.RollupCategory,
+	}
 
 	RollupHistoricalRPCFlag = &cli.StringFlag{
 		Name:     "rollup.historicalrpc",
@@ -895,7 +910,6 @@ var (
 		Category: flags.RollupCategory,
 	}
 
-	/* [kroma unsupported]
 	RollupDisableTxPoolGossipFlag = &cli.BoolFlag{
 		Name:     "rollup.disabletxpoolgossip",
 		Usage:    "Disable transaction pool gossip.",
@@ -906,18 +920,28 @@ var (
 		Usage:    "Add RPC-submitted transactions to the txpool (on by default if --rollup.sequencerhttp is not set).",
 		Category: flags.RollupCategory,
 	}
-	*/
 	RollupComputePendingBlock = &cli.BoolFlag{
 		Name:     "rollup.computependingblock",
 		Usage:    "By default the pending block equals the latest block to save resources and not leak txs from the tx-pool, this flag enables computing of the pending block from the tx-pool instead.",
 		Category: flags.RollupCategory,
 	}
-	/* [kroma unsupported]
 	RollupHaltOnIncompatibleProtocolVersionFlag = &cli.StringFlag{
 		Name:     "rollup.halt",
 		Usage:    "Opt-in option to halt on incompatible protocol version requirements of the given level (major/minor/patch/none), as signaled through the Engine API by the rollup node",
 		Category: flags.RollupCategory,
 	}
+	RollupSequencerTxConditionalEnabledFlag = &cli.BoolFlag{
+		Name:     "rollup.sequencertxconditionalenabled",
+		Usage:    "Serve the eth_sendRawTransactionConditional endpoint and check the transaction conditionals while building blocks",
+		Category: flags.RollupCategory,
+	}
+	RollupSequencerTxConditionalCostRateLimitFlag = &cli.Float64Flag{
+		Name:     "rollup.sequencertxconditionalcostratelimit",
+		Usage:    "Maximum cost, in number of state lookups, of the transaction conditionals accepted per second",
+		Value:    ethconfig.Defaults.RollupSequencerTxConditionalCostRateLimit,
+		Category: flags.RollupCategory,
+	}
+	/* [kroma unsupported]
 	RollupSuperchainUpgradesFlag = &cli.BoolFlag{
 		Name:     "rollup.superchain-upgrades",
 		Aliases:  []string{"beta.rollup.superchain-upgrades"},
@@ -1034,6 +1058,63 @@ Please note that --` + MetricsHTTPFlag.Name + ` must be set to start the server.
 		Category: flags.EthCategory,
 		Value:    false,
 	}
+	MPTMigrationWorkersFlag = &cli.IntFlag{
+		Name:     "kroma.migration.workers",
+		Usage:    "Number of concurrent workers migrating the ZKTrie state to Merkle Patricia Tree",
+		Category: flags.EthCategory,
+		Value:    ethconfig.Defaults.MPTMigrationWorkers,
+	}
+	MPTMigrationMemoryCapFlag = &cli.IntFlag{
+		Name:     "kroma.migration.memorycap",
+		Usage:    "Megabytes of memory allocated to buffer migrated Merkle Patricia Tree nodes",
+		Category: flags.EthCategory,
+		Value:    ethconfig.Defaults.MPTMigrationMemoryCap,
+	}
+	MPTMigrationValidationFlag = &cli.StringFlag{
+		Name:     "kroma.migration.validation",
+		Usage:    `Validation mode of the migrated state ("full", "sample" or "background")`,
+		Category: flags.EthCategory,
+		Value:    ethconfig.Defaults.MPTMigrationValidation,
+	}
+	MPTMigrationSampleAccountsFlag = &cli.IntFlag{
+		Name:     "kroma.migration.validation.accounts",
+		Usage:    "Number of randomly sampled accounts in the sample validation of the migrated state",
+		Category: flags.EthCategory,
+		Value:    ethconfig.Defaults.MPTMigrationSampleAccounts,
+	}
+	MPTMigrationSampleSlotsFlag = &cli.IntFlag{
+		Name:     "kroma.migration.validation.slots",
+		Usage:    "Number of randomly sampled storage slots per account in the sample validation of the migrated state",
+		Category: flags.EthCategory,
+		Value:    ethconfig.Defaults.MPTMigrationSampleSlots,
+	}
+	MPTMigrationSampleSeedFlag = &cli.Int64Flag{
+		Name:     "kroma.migration.validation.seed",
+		Usage:    "Seed of the sample validation of the migrated state (random if zero)",
+		Category: flags.EthCategory,
+	}
+	BlockTraceCacheFlag = &cli.BoolFlag{
+		Name:     "kroma.tracecache",
+		Usage:    "Store the generated block traces on disk to serve the repeated requests",
+		Category: flags.EthCategory,
+	}
+	BlockTraceCacheOnImportFlag = &cli.BoolFlag{
+		Name:     "kroma.tracecache.import",
+		Usage:    "Generate the block traces of the imported blocks in the background",
+		Category: flags.EthCategory,
+	}
+	BlockTraceCacheRetentionFlag = &cli.Uint64Flag{
+		Name:     "kroma.tracecache.retention",
+		Usage:    "Number of recent blocks whose traces are retained on disk (0 = keep all)",
+		Category: flags.EthCategory,
+		Value:    ethconfig.Defaults.BlockTraceCacheRetention,
+	}
+	BlockTraceCacheSizeFlag = &cli.IntFlag{
+		Name:     "kroma.tracecache.size",
+		Usage:    "Megabytes of disk space allowed for the stored block traces (0 = unlimited)",
+		Category: flags.EthCategory,
+		Value:    ethconfig.Defaults.BlockTraceCacheSize,
+	}
 )
 
 var (
@@ -1888,14 +1969,24 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RollupHistoricalRPCTimeoutFlag.Name) {
 		cfg.RollupHistoricalRPCTimeout = ctx.Duration(RollupHistoricalRPCTimeoutFlag.Name)
 	}
-	/* [kroma unsupported]
 	// Only configure sequencer http flag if we're running in verifier mode i.e. --mine is disabled.
 	if ctx.IsSet(RollupSequencerHTTPFlag.Name) && !ctx.IsSet(MiningEnabledFlag.Name) {
 		cfg.RollupSequencerHTTP = ctx.String(RollupSequencerHTTPFlag.Name)
 	}
-	cfg.RollupDisableTxPoolGossip = ctx.Bool(RollupDisableTxPoolGossipFlag.Name)
+	if ctx.IsSet(RollupSequencerHTTPTimeoutFlag.Name) {
+		cfg.RollupSequencerHTTPTimeout = ctx.Duration(RollupSequencerHTTPTimeoutFlag.Name)
+	}
+	if ctx.IsSet(RollupSequencerHTTPRetriesFlag.Name) {
+		cfg.RollupSequencerHTTPRetries = ctx.Int(RollupSequencerHTTPRetriesFlag.Name)
+	}
 	cfg.RollupDisableTxPoolAdmission = cfg.RollupSequencerHTTP != "" && !ctx.Bool(RollupEnableTxPoolAdmissionFlag.Name)
+	cfg.RollupDisableTxPoolGossip = ctx.Bool(RollupDisableTxPoolGossipFlag.Name)
 	cfg.RollupHaltOnIncompatibleProtocolVersion = ctx.String(RollupHaltOnIncompatibleProtocolVersionFlag.Name)
+	cfg.RollupSequencerTxConditionalEnabled = ctx.Bool(RollupSequencerTxConditionalEnabledFlag.Name)
+	if ctx.IsSet(RollupSequencerTxConditionalCostRateLimitFlag.Name) {
+		cfg.RollupSequencerTxConditionalCostRateLimit = ctx.Float64(RollupSequencerTxConditionalCostRateLimitFlag.Name)
+	}
+	/* [kroma unsupported]
 	cfg.ApplySuperchainUpgrades = ctx.Bool(RollupSuperchainUpgradesFlag.Name)
 	*/
 	// Override any default configs for hard coded networks.
@@ -2040,7 +2131,17 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (ethapi.Backend
 	if err != nil {
 		Fatalf("Failed to register the Ethereum service: %v", err)
 	}
-	stack.RegisterAPIs(tracers.APIs(backend.APIBackend))
+	var traceCache *tracers.BlockTraceCache
+	if cfg.BlockTraceCache {
+		traceCache = tracers.NewBlockTraceCache(backend.APIBackend, tracers.BlockTraceCacheConfig{
+			Retention: cfg.BlockTraceCacheRetention,
+			Size:      uint64(cfg.BlockTraceCacheSize) * 1024 * 1024,
+			OnImport:  cfg.BlockTraceCacheOnImport,
+		})
+		stack.RegisterLifecycle(traceCache)
+	}
+	stack.RegisterAPIs(tracers.APIsWithBlockTraceCache(backend.APIBackend, traceCache))
+	stack.RegisterHandler("Block trace", "/blocktrace", tracers.NewBlockTraceHandler(backend.APIBackend, traceCache))
 	return backend.APIBackend, backend
 }
 
@@ -276,8 +276,25 @@ type ChainOverrides struct {
 	OverrideOptimismInterop *uint64
 
 	// kroma
-	CircuitParams    *params.CircuitParams
-	OverrideKromaMPT *uint64
+	CircuitParams      *params.CircuitParams
+	OverrideKromaMPT   *uint64
+	KromaChainRegistry params.KromaChainRegistry // External Kroma chain configs, preferred over the bundled ones
+}
+
+// checkKromaGenesis ensures that the genesis hash matches with the one of the
+// Kroma chain in the chain registry, if specified.
+func (o *ChainOverrides) checkKromaGenesis(config *params.ChainConfig, hash func() common.Hash) error {
+	if o == nil || config == nil || !config.IsKroma() || config.ChainID == nil || !config.ChainID.IsUint64() {
+		return nil
+	}
+	chain, ok := o.KromaChainRegistry.Lookup(config.ChainID.Uint64())
+	if !ok || chain.GenesisHash == nil {
+		return nil
+	}
+	if have := hash(); have != *chain.GenesisHash {
+		return fmt.Errorf("genesis of kroma chain %d mismatches with the chain registry (have %x, want %x)", config.ChainID, have, *chain.GenesisHash)
+	}
+	return nil
 }
 
 // SetupGenesisBlock writes or updates the genesis block in db.
@@ -317,7 +334,11 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *trie.Database, gen
 
 				// Load the chain-config for the given chain id, and overrides it if it exists.
 				if config.ChainID != nil && config.ChainID.IsUint64() {
-					conf, err := params.LoadKromaChainConfig(config.ChainID.Uint64())
+					var registry params.KromaChainRegistry
+					if overrides != nil {
+						registry = overrides.KromaChainRegistry
+					}
+					conf, err := registry.LoadChainConfig(config.ChainID.Uint64())
 					if err != nil {
 						log.Warn("failed to load chain config from registry, skipping override", "err", err, "chain_id", config.ChainID)
 					} else {
@@ -400,6 +421,9 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *trie.Database, gen
 			log.Info("Writing custom genesis block")
 		}
 		applyOverrides(genesis.Config)
+		if err := overrides.checkKromaGenesis(genesis.Config, func() common.Hash { return genesis.ToBlock().Hash() }); err != nil {
+			return genesis.Config, common.Hash{}, err
+		}
 		triedb.SetBackend(genesis.Config.Zktrie)
 		block, err := genesis.Commit(db, triedb)
 		if err != nil {
@@ -438,6 +462,9 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *trie.Database, gen
 		if hash != stored {
 			return genesis.Config, hash, &GenesisMismatchError{stored, hash}
 		}
+		if err := overrides.checkKromaGenesis(genesis.Config, func() common.Hash { return hash }); err != nil {
+			return genesis.Config, hash, err
+		}
 		block, err := genesis.Commit(db, triedb)
 		if err != nil {
 			return genesis.Config, hash, err
@@ -473,6 +500,9 @@ func SetupGenesisBlockWithOverride(db ethdb.Database, triedb *trie.Database, gen
 		newcfg = storedcfg
 		applyOverrides(newcfg)
 	}
+	if err := overrides.checkKromaGenesis(newcfg, func() common.Hash { return stored }); err != nil {
+		return newcfg, stored, err
+	}
 	// Check config compatibility and write the config. Compatibility errors
 	// are returned to the caller unless we're already at block zero.
 	head := rawdb.ReadHeadHeader(db)
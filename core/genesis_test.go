@@ -328,3 +328,48 @@ func TestVerkleGenesisCommit(t *testing.T) {
 		t.Fatal("could not find node")
 	}
 }
+
+func TestSetupGenesisWithKromaChainRegistry(t *testing.T) {
+	var (
+		chainID  = uint64(12345)
+		registry = params.KromaChainRegistry{
+			chainID: {CanyonTime: u64(10), EcotoneTime: u64(20), KromaMPTTime: u64(30), EIP1559Elasticity: 8},
+		}
+		genesis = func() *Genesis {
+			return &Genesis{
+				Config: &params.ChainConfig{
+					ChainID: new(big.Int).SetUint64(chainID),
+					Kroma:   &params.KromaConfig{EIP1559Elasticity: 6, EIP1559Denominator: 50},
+				},
+				BaseFee: big.NewInt(params.InitialBaseFee),
+				Alloc:   GenesisAlloc{{1}: {Balance: big.NewInt(1)}},
+			}
+		}
+	)
+	db := rawdb.NewMemoryDatabase()
+	config, hash, err := SetupGenesisBlockWithOverride(db, trie.NewDatabase(db, nil), genesis(), &ChainOverrides{KromaChainRegistry: registry})
+	if err != nil {
+		t.Fatalf("failed to setup genesis: %v", err)
+	}
+	if *config.CanyonTime != 10 || *config.EcotoneTime != 20 || *config.KromaMPTTime != 30 || config.Kroma.EIP1559Elasticity != 8 {
+		t.Fatalf("chain registry not applied: %v", config)
+	}
+	// The stored genesis is checked against the one of the chain registry
+	registry[chainID].GenesisHash = &common.Hash{0x01}
+	if _, _, err := SetupGenesisBlockWithOverride(db, trie.NewDatabase(db, nil), nil, &ChainOverrides{KromaChainRegistry: registry}); err == nil {
+		t.Fatal("expected genesis mismatch error")
+	}
+	registry[chainID].GenesisHash = &hash
+	if _, _, err := SetupGenesisBlockWithOverride(db, trie.NewDatabase(db, nil), nil, &ChainOverrides{KromaChainRegistry: registry}); err != nil {
+		t.Fatalf("failed to setup stored genesis: %v", err)
+	}
+	// The new genesis is checked before being committed
+	registry[chainID].GenesisHash = &common.Hash{0x01}
+	db = rawdb.NewMemoryDatabase()
+	if _, _, err := SetupGenesisBlockWithOverride(db, trie.NewDatabase(db, nil), genesis(), &ChainOverrides{KromaChainRegistry: registry}); err == nil {
+		t.Fatal("expected genesis mismatch error")
+	}
+	if stored := rawdb.ReadCanonicalHash(db, 0); stored != (common.Hash{}) {
+		t.Fatalf("mismatching genesis committed: %x", stored)
+	}
+}
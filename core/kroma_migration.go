@@ -13,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 var (
@@ -22,6 +23,7 @@ var (
 	destructChangesPrefix    = []byte("dC-")
 	migratedRootKey          = []byte("MigratedRoot")
 	migratedNumberKey        = []byte("MigratedNumber")
+	migrationCheckpointKey   = []byte("MigrationCheckpoint")
 )
 
 type MigratedRef struct {
@@ -73,6 +75,56 @@ func (mr *MigratedRef) BlockNumber() uint64 {
 	return mr.number
 }
 
+// MigrationCheckpoint records the progress of the historical ZKT to MPT migration, so that
+// an interrupted migration can be resumed instead of restarting from scratch.
+type MigrationCheckpoint struct {
+	BlockNumber uint64      // Number of the block whose state is being migrated
+	ZkRoot      common.Hash // ZK state root of the block being migrated
+	Root        common.Hash // Partially built MPT state root containing all accounts up to AccountKey
+	AccountKey  []byte      // Iterator key of the last fully migrated account, empty if none
+	Accounts    uint64      // Number of accounts migrated so far
+
+	StorageAccountKey []byte      // Iterator key of the account whose storage migration is in progress, empty if none
+	StorageKey        []byte      // Iterator key of the last migrated storage slot of that account
+	StorageRoot       common.Hash // Partially built MPT storage root of that account
+	Slots             uint64      // Number of storage slots of that account migrated so far
+
+	// Partitions holds the iterator key of the last migrated account of every
+	// key space partition, used by the concurrent migration only.
+	Partitions [][]byte `rlp:"optional"`
+}
+
+// ReadMigrationCheckpoint retrieves the checkpoint of the historical migration.
+// It returns nil if no checkpoint is stored.
+func ReadMigrationCheckpoint(db ethdb.KeyValueReader) *MigrationCheckpoint {
+	data, _ := db.Get(migrationCheckpointKey)
+	if len(data) == 0 {
+		return nil
+	}
+	var checkpoint MigrationCheckpoint
+	if err := rlp.DecodeBytes(data, &checkpoint); err != nil {
+		return nil
+	}
+	return &checkpoint
+}
+
+// WriteMigrationCheckpoint stores the checkpoint of the historical migration.
+func WriteMigrationCheckpoint(db ethdb.KeyValueWriter, checkpoint *MigrationCheckpoint) error {
+	data, err := rlp.EncodeToBytes(checkpoint)
+	if err != nil {
+		return err
+	}
+	if err := db.Put(migrationCheckpointKey, data); err != nil {
+		return fmt.Errorf("failed to store migration checkpoint: %w", err)
+	}
+	return nil
+}
+
+// DeleteMigrationCheckpoint removes the checkpoint of the historical migration.
+func DeleteMigrationCheckpoint(db ethdb.KeyValueWriter) error {
+	return db.Delete(migrationCheckpointKey)
+}
+
 // encodeBlockNumber encodes a block number as big endian uint64
 func encodeBlockNumber(number uint64) []byte {
 	enc := make([]byte, 8)
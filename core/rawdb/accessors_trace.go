@@ -0,0 +1,73 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadBlockTrace retrieves the encoded execution trace of the block corresponding
+// to the hash.
+func ReadBlockTrace(db ethdb.KeyValueReader, hash common.Hash, number uint64) []byte {
+	data, _ := db.Get(blockTraceKey(number, hash))
+	return data
+}
+
+// HasBlockTrace verifies the existence of the execution trace of the block
+// corresponding to the hash.
+func HasBlockTrace(db ethdb.KeyValueReader, hash common.Hash, number uint64) bool {
+	has, _ := db.Has(blockTraceKey(number, hash))
+	return has
+}
+
+// WriteBlockTrace stores the encoded execution trace of the block.
+func WriteBlockTrace(db ethdb.KeyValueWriter, hash common.Hash, number uint64, trace []byte) {
+	if err := db.Put(blockTraceKey(number, hash), trace); err != nil {
+		log.Crit("Failed to store block trace", "err", err)
+	}
+}
+
+// DeleteBlockTrace removes the execution trace of the block.
+func DeleteBlockTrace(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
+	if err := db.Delete(blockTraceKey(number, hash)); err != nil {
+		log.Crit("Failed to delete block trace", "err", err)
+	}
+}
+
+// IterateBlockTraces iterates over the stored block traces in ascending block
+// number order, calling fn with the block number, hash and the size of the trace
+// until it returns false.
+func IterateBlockTraces(db ethdb.Iteratee, fn func(number uint64, hash common.Hash, size int) bool) {
+	it := db.NewIterator(blockTracePrefix, nil)
+	defer it.Release()
+
+	keyLength := len(blockTracePrefix) + 8 + common.HashLength
+	for it.Next() {
+		key := it.Key()
+		if len(key) != keyLength {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(blockTracePrefix) : len(blockTracePrefix)+8])
+		if !fn(number, common.BytesToHash(key[len(blockTracePrefix)+8:]), len(it.Value())) {
+			return
+		}
+	}
+}
@@ -132,6 +132,8 @@ var (
 
 	CliqueSnapshotPrefix = []byte("clique-")
 
+	blockTracePrefix = []byte("kroma-trace-") // blockTracePrefix + num (uint64 big endian) + hash -> block trace
+
 	BestUpdateKey         = []byte("update-")    // bigEndian64(syncPeriod) -> RLP(types.LightClientUpdate)  (nextCommittee only referenced by root hash)
 	FixedCommitteeRootKey = []byte("fixedRoot-") // bigEndian64(syncPeriod) -> committee root hash
 	SyncCommitteeKey      = []byte("committee-") // bigEndian64(syncPeriod) -> serialized committee
@@ -190,6 +192,11 @@ func blockReceiptsKey(number uint64, hash common.Hash) []byte {
 	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+// blockTraceKey = blockTracePrefix + num (uint64 big endian) + hash
+func blockTraceKey(number uint64, hash common.Hash) []byte {
+	return append(append(blockTracePrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
 // txLookupKey = txLookupPrefix + hash
 func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
@@ -234,6 +241,15 @@ func preimageKey(hash common.Hash) []byte {
 	return append(PreimagePrefix, hash.Bytes()...)
 }
 
+// IsPreimageKey reports whether the given byte slice is the key of a preimage,
+// if so return the raw preimage hash as well.
+func IsPreimageKey(key []byte) (bool, []byte) {
+	if bytes.HasPrefix(key, PreimagePrefix) && len(key) == common.HashLength+len(PreimagePrefix) {
+		return true, key[len(PreimagePrefix):]
+	}
+	return false, nil
+}
+
 // codeKey = CodePrefix + hash
 func codeKey(hash common.Hash) []byte {
 	return append(CodePrefix, hash.Bytes()...)
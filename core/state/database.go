@@ -144,6 +144,10 @@ type Trie interface {
 	// nodes of the longest existing prefix of the key (at least the root), ending
 	// with the node that proves the absence of the key.
 	Prove(key []byte, proofDb ethdb.KeyValueWriter) error
+
+	// Witness returns a set containing all trie nodes that have been loaded
+	// from the database. The nodes are keyed by their encoded blobs.
+	Witness() map[string]struct{}
 }
 
 // NewDatabase creates a backing store for state. The returned database is safe for
@@ -209,7 +213,7 @@ func (db *cachingDB) OpenTrie(root common.Hash) (Trie, error) {
 // OpenStorageTrie opens the storage trie of an account.
 func (db *cachingDB) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, self Trie) (Trie, error) {
 	if db.triedb.IsKromaZK() {
-		return trie.NewZkMerkleStateTrie(root, db.triedb)
+		return trie.NewZkMerkleStateTrieWithID(trie.StorageTrieID(stateRoot, crypto.Keccak256Hash(address.Bytes()), root), db.triedb)
 	}
 	// [Scroll: START]
 	if db.triedb.IsZk() {
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -71,12 +72,20 @@ type Config struct {
 // the whole pruning work. It's recommended to run this offline tool
 // periodically in order to release the disk usage and improve the
 // disk read performance to some extent.
+//
+// States stored in zk tries are supported as well. In this case the
+// relevant state is reconstructed by iterating the target zk tries
+// instead, and the preimages of the zk trie keys which don't belong
+// to the target state and the genesis state are deleted too. While a
+// ZKT to MPT state migration is in progress, the migrated MPT state
+// and the migrated zk state are kept, and no preimage is deleted.
 type Pruner struct {
 	config      Config
 	chainHeader *types.Header
 	db          ethdb.Database
 	stateBloom  *stateBloom
 	snaptree    *snapshot.Tree
+	zk          bool
 }
 
 // NewPruner creates the pruner instance.
@@ -86,7 +95,8 @@ func NewPruner(db ethdb.Database, config Config) (*Pruner, error) {
 		return nil, errors.New("failed to load head block")
 	}
 	// Offline pruning is only supported in legacy hash based scheme.
-	triedb := trie.NewDatabase(db, trie.HashDefaults)
+	zk := isZkState(db, headBlock.Header())
+	triedb := trie.NewDatabase(db, trie.GetHashDefaults(zk))
 
 	snapconfig := snapshot.Config{
 		CacheSize:  256,
@@ -113,10 +123,11 @@ func NewPruner(db ethdb.Database, config Config) (*Pruner, error) {
 		db:          db,
 		stateBloom:  stateBloom,
 		snaptree:    snaptree,
+		zk:          zk,
 	}, nil
 }
 
-func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, stateBloom *stateBloom, bloomPath string, middleStateRoots map[common.Hash]struct{}, start time.Time) error {
+func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, stateBloom *stateBloom, bloomPath string, middleStateRoots map[common.Hash]struct{}, zk bool, start time.Time) error {
 	// Delete all stale trie nodes in the disk. With the help of state bloom
 	// the trie nodes(and codes) belong to the active state will be filtered
 	// out. A very small part of stale tries will also be filtered because of
@@ -127,6 +138,7 @@ func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, sta
 	var (
 		skipped, count int
 		size           common.StorageSize
+		migrating      = hasMigration(maindb) // The migration reads the preimages of any zk state
 		pstart         = time.Now()
 		logged         = time.Now()
 		batch          = maindb.NewBatch()
@@ -139,12 +151,18 @@ func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, sta
 		// - trie node
 		// - legacy contract code
 		// - new-scheme contract code
+		// - preimage of zk trie key
 		isCode, codeKey := rawdb.IsCodeKey(key)
-		if len(key) == common.HashLength || isCode {
+		isPreimage, preimageKey := rawdb.IsPreimageKey(key)
+		isPreimage = isPreimage && zk && !migrating
+		if len(key) == common.HashLength || isCode || isPreimage {
 			checkKey := key
 			if isCode {
 				checkKey = codeKey
 			}
+			if isPreimage {
+				checkKey = preimageKey
+			}
 			if _, exist := middleStateRoots[common.BytesToHash(checkKey)]; exist {
 				log.Debug("Forcibly delete the middle state roots", "hash", common.BytesToHash(checkKey))
 			} else {
@@ -158,9 +176,9 @@ func prune(snaptree *snapshot.Tree, root common.Hash, maindb ethdb.Database, sta
 			batch.Delete(key)
 
 			var eta time.Duration // Realistically will never remain uninited
-			if done := binary.BigEndian.Uint64(key[:8]); done > 0 {
+			if done := binary.BigEndian.Uint64(checkKey[:8]); done > 0 {
 				var (
-					left  = math.MaxUint64 - binary.BigEndian.Uint64(key[:8])
+					left  = math.MaxUint64 - binary.BigEndian.Uint64(checkKey[:8])
 					speed = done/uint64(time.Since(pstart)/time.Millisecond+1) + 1 // +1s to avoid division by zero
 				)
 				eta = time.Duration(left/speed) * time.Millisecond
@@ -268,7 +286,7 @@ func (p *Pruner) Prune(root common.Hash) error {
 	// Ensure the root is really present. The weak assumption
 	// is the presence of root can indicate the presence of the
 	// entire trie.
-	if !rawdb.HasLegacyTrieNode(p.db, root) {
+	if !hasStateRoot(p.db, root, p.zk) {
 		// The special case is for clique based networks(goerli
 		// and some other private networks), it's possible that two
 		// consecutive blocks will have same root. In this case snapshot
@@ -282,7 +300,7 @@ func (p *Pruner) Prune(root common.Hash) error {
 		// as the pruning target.
 		var found bool
 		for i := len(layers) - 2; i >= 2; i-- {
-			if rawdb.HasLegacyTrieNode(p.db, layers[i].Root()) {
+			if hasStateRoot(p.db, layers[i].Root(), p.zk) {
 				root = layers[i].Root()
 				found = true
 				log.Info("Selecting middle-layer as the pruning target", "root", root, "depth", i)
@@ -309,12 +327,18 @@ func (p *Pruner) Prune(root common.Hash) error {
 		if layer.Root() == root {
 			break
 		}
-		middleRoots[layer.Root()] = struct{}{}
+		middleRoots[trieNodeKey(layer.Root(), p.zk)] = struct{}{}
 	}
 	// Traverse the target state, re-construct the whole state trie and
-	// commit to the given bloom filter.
+	// commit to the given bloom filter. The zk tries can't be regenerated
+	// from the snapshot with the node keys of the database, so iterate the
+	// target state in this case.
 	start := time.Now()
-	if err := snapshot.GenerateTrie(p.snaptree, root, p.db, p.stateBloom); err != nil {
+	if p.zk {
+		if err := extractZkState(p.db, root, p.stateBloom); err != nil {
+			return err
+		}
+	} else if err := snapshot.GenerateTrie(p.snaptree, root, p.db, p.stateBloom); err != nil {
 		return err
 	}
 	// Traverse the genesis, put all genesis state entries into the
@@ -322,6 +346,13 @@ func (p *Pruner) Prune(root common.Hash) error {
 	if err := extractGenesis(p.db, p.stateBloom); err != nil {
 		return err
 	}
+	// Keep the state the ZKT to MPT migration relies on, otherwise an
+	// interrupted migration can't be resumed and the migrated state is lost.
+	if p.zk {
+		if err := extractMigration(p.db, p.stateBloom); err != nil {
+			return err
+		}
+	}
 	filterName := bloomFilterName(p.config.Datadir, root)
 
 	log.Info("Writing state bloom to disk", "name", filterName)
@@ -329,7 +360,7 @@ func (p *Pruner) Prune(root common.Hash) error {
 		return err
 	}
 	log.Info("State bloom filter committed", "name", filterName)
-	return prune(p.snaptree, root, p.db, p.stateBloom, filterName, middleRoots, start)
+	return prune(p.snaptree, root, p.db, p.stateBloom, filterName, middleRoots, p.zk, start)
 }
 
 // RecoverPruning will resume the pruning procedure during the system restart.
@@ -366,7 +397,8 @@ func RecoverPruning(datadir string, db ethdb.Database) error {
 		AsyncBuild: false,
 	}
 	// Offline pruning is only supported in legacy hash based scheme.
-	triedb := trie.NewDatabase(db, trie.HashDefaults)
+	zk := isZkState(db, headBlock.Header())
+	triedb := trie.NewDatabase(db, trie.GetHashDefaults(zk))
 	snaptree, err := snapshot.New(snapconfig, db, triedb, headBlock.Root())
 	if err != nil {
 		return err // The relevant snapshot(s) might not exist
@@ -389,13 +421,13 @@ func RecoverPruning(datadir string, db ethdb.Database) error {
 			found = true
 			break
 		}
-		middleRoots[layer.Root()] = struct{}{}
+		middleRoots[trieNodeKey(layer.Root(), zk)] = struct{}{}
 	}
 	if !found {
 		log.Error("Pruning target state is not existent")
 		return errors.New("non-existent target state")
 	}
-	return prune(snaptree, stateBloomRoot, db, stateBloom, stateBloomPath, middleRoots, time.Now())
+	return prune(snaptree, stateBloomRoot, db, stateBloom, stateBloomPath, middleRoots, zk, time.Now())
 }
 
 // extractGenesis loads the genesis state and commits all the state entries
@@ -409,7 +441,16 @@ func extractGenesis(db ethdb.Database, stateBloom *stateBloom) error {
 	if genesis == nil {
 		return errors.New("missing genesis block")
 	}
-	t, err := trie.NewStateTrie(trie.StateTrieID(genesis.Root()), trie.NewDatabase(db, trie.HashDefaults))
+	if isZkState(db, genesis.Header()) {
+		return extractZkState(db, genesis.Root(), stateBloom)
+	}
+	return extractState(db, genesis.Root(), stateBloom)
+}
+
+// extractState loads the MPT state with the given root and commits all the
+// state entries into the given bloomfilter.
+func extractState(db ethdb.Database, root common.Hash, stateBloom *stateBloom) error {
+	t, err := trie.NewStateTrie(trie.StateTrieID(root), trie.NewDatabase(db, trie.HashDefaults))
 	if err != nil {
 		return err
 	}
@@ -432,8 +473,102 @@ func extractGenesis(db ethdb.Database, stateBloom *stateBloom) error {
 				return err
 			}
 			if acc.Root != types.EmptyRootHash {
-				id := trie.StorageTrieID(genesis.Root(), common.BytesToHash(accIter.LeafKey()), acc.Root)
-				storageTrie, err := trie.NewStateTrie(id, trie.NewDatabase(db, trie.HashDefaults))
+				id := trie.StorageTrieID(root, common.BytesToHash(accIter.LeafKey()), acc.Root)
+				if err := extractStorage(db, id, stateBloom); err != nil {
+					return err
+				}
+			}
+			if !bytes.Equal(acc.CodeHash, types.EmptyCodeHash.Bytes()) {
+				stateBloom.Put(acc.CodeHash, nil)
+			}
+		}
+	}
+	return accIter.Error()
+}
+
+// extractStorage loads the MPT storage trie with the given id and commits all
+// the trie nodes into the given bloomfilter.
+func extractStorage(db ethdb.Database, id *trie.ID, stateBloom *stateBloom) error {
+	storageTrie, err := trie.NewStateTrie(id, trie.NewDatabase(db, trie.HashDefaults))
+	if err != nil {
+		return err
+	}
+	storageIter, err := storageTrie.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	for storageIter.Next(true) {
+		hash := storageIter.Hash()
+		if hash != (common.Hash{}) {
+			stateBloom.Put(hash.Bytes(), nil)
+		}
+	}
+	return storageIter.Error()
+}
+
+// extractMigration commits the state entries needed by the ZKT to MPT state
+// migration into the given bloomfilter: the MPT state migrated so far, and the
+// zk state being migrated if the migration is still in progress.
+func extractMigration(db ethdb.Database, stateBloom *stateBloom) error {
+	if root := core.NewMigratedRef(db).Root(); root != (common.Hash{}) {
+		if err := extractState(db, root, stateBloom); err != nil {
+			return err
+		}
+	}
+	checkpoint := core.ReadMigrationCheckpoint(db)
+	if checkpoint == nil {
+		return nil
+	}
+	if err := extractZkState(db, checkpoint.ZkRoot, stateBloom); err != nil {
+		return err
+	}
+	if err := extractState(db, checkpoint.Root, stateBloom); err != nil {
+		return err
+	}
+	// The storage trie of the account being migrated is not linked to the
+	// partially built state yet.
+	return extractStorage(db, trie.TrieID(checkpoint.StorageRoot), stateBloom)
+}
+
+// hasMigration reports whether a ZKT to MPT state migration has been started.
+func hasMigration(db ethdb.Database) bool {
+	return core.NewMigratedRef(db).Root() != (common.Hash{}) || core.ReadMigrationCheckpoint(db) != nil
+}
+
+// extractZkState loads the zk state with the given root and commits all the
+// state entries, including the preimages of the trie keys, into the given
+// bloomfilter.
+func extractZkState(db ethdb.Database, root common.Hash, stateBloom *stateBloom) error {
+	var (
+		triedb    = trie.NewZkDatabase(db)
+		emptyRoot = types.GetEmptyRootHash(true)
+	)
+	t, err := trie.NewZkMerkleStateTrie(root, triedb)
+	if err != nil {
+		return err
+	}
+	accIter, err := t.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	for accIter.Next(true) {
+		hash := accIter.Hash()
+
+		// Empty nodes don't have hash.
+		if hash != (common.Hash{}) {
+			stateBloom.Put(trieNodeKey(hash, true).Bytes(), nil)
+		}
+		// If it's a leaf node, yes we are touching an account,
+		// dig into the storage trie further.
+		if accIter.Leaf() {
+			stateBloom.Put(trie.IteratorKeyToHash(accIter.LeafKey(), true).Bytes(), nil)
+
+			acc, err := types.NewStateAccount(accIter.LeafBlob(), true)
+			if err != nil {
+				return err
+			}
+			if acc.Root != emptyRoot {
+				storageTrie, err := trie.NewZkMerkleStateTrie(acc.Root, triedb)
 				if err != nil {
 					return err
 				}
@@ -444,7 +579,10 @@ func extractGenesis(db ethdb.Database, stateBloom *stateBloom) error {
 				for storageIter.Next(true) {
 					hash := storageIter.Hash()
 					if hash != (common.Hash{}) {
-						stateBloom.Put(hash.Bytes(), nil)
+						stateBloom.Put(trieNodeKey(hash, true).Bytes(), nil)
+					}
+					if storageIter.Leaf() {
+						stateBloom.Put(trie.IteratorKeyToHash(storageIter.LeafKey(), true).Bytes(), nil)
 					}
 				}
 				if storageIter.Error() != nil {
@@ -459,6 +597,26 @@ func extractGenesis(db ethdb.Database, stateBloom *stateBloom) error {
 	return accIter.Error()
 }
 
+// isZkState reports whether the state of the given block is stored in zk tries.
+func isZkState(db ethdb.Database, header *types.Header) bool {
+	config := rawdb.ReadChainConfig(db, rawdb.ReadCanonicalHash(db, 0))
+	return config != nil && config.IsZkState(header.Time)
+}
+
+// trieNodeKey returns the database key of the trie node with the given hash.
+// The nodes of zk tries are keyed by the byte reversed node hash.
+func trieNodeKey(hash common.Hash, zk bool) common.Hash {
+	if zk {
+		return common.BytesToHash(common.ReverseBytes(hash[:]))
+	}
+	return hash
+}
+
+// hasStateRoot reports whether the root node of the given state is present.
+func hasStateRoot(db ethdb.Database, root common.Hash, zk bool) bool {
+	return rawdb.HasLegacyTrieNode(db, trieNodeKey(root, zk))
+}
+
 func bloomFilterName(datadir string, hash common.Hash) string {
 	return filepath.Join(datadir, fmt.Sprintf("%s.%s.%s", stateBloomFilePrefix, hash.Hex(), stateBloomFileSuffix))
 }
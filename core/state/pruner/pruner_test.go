@@ -0,0 +1,215 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"math/big"
+	"testing"
+
+	zkt "github.com/kroma-network/zktrie/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddress = crypto.PubkeyToAddress(testKey.PublicKey)
+
+	// storageContract stores the calldata word at slot 0 and clears slot 1.
+	storageContract = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+	storageCode     = []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x01, byte(vm.SSTORE),
+	}
+)
+
+// makeZkChain generates a zktrie chain with the given length, keeping the states
+// of all blocks in the database.
+func makeZkChain(t *testing.T, db ethdb.Database, blocks int) (*core.BlockChain, []*types.Block) {
+	config := *params.TestChainConfig
+	config.Zktrie = true
+
+	gspec := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			testAddress:     {Balance: big.NewInt(params.Ether)},
+			storageContract: {Code: storageCode, Balance: common.Big0, Storage: map[common.Hash]common.Hash{{1}: {1}}},
+		},
+	}
+	engine := ethash.NewFaker()
+	_, chain, _ := core.GenerateChainWithGenesis(gspec, engine, blocks, func(i int, b *core.BlockGen) {
+		signer := types.LatestSigner(gspec.Config)
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(testAddress), common.BigToAddress(big.NewInt(int64(i+1))), big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, testKey)
+		b.AddTx(tx)
+		data := common.BigToHash(big.NewInt(int64(i + 1)))
+		tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(testAddress), storageContract, common.Big0, 100000, b.BaseFee(), data.Bytes()), signer, testKey)
+		b.AddTx(tx)
+	})
+	cacheConfig := core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cacheConfig.TrieDirtyDisabled = true
+	cacheConfig.Preimages = true
+
+	bc, err := core.NewBlockChain(db, cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return bc, chain
+}
+
+// checkZkState checks that the zk state with the given root is complete, and
+// that the preimages of all trie keys are available.
+func checkZkState(t *testing.T, triedb *trie.Database, root common.Hash) {
+	tr, err := trie.NewZkMerkleStateTrie(root, triedb)
+	if err != nil {
+		t.Fatalf("failed to open state %x: %v", root, err)
+	}
+	accIt := trie.NewIterator(tr.MustNodeIterator(nil))
+	for accIt.Next() {
+		if preimage := triedb.Preimage(*trie.IteratorKeyToHash(accIt.Key, true)); preimage == nil {
+			t.Fatalf("missing account preimage %x", accIt.Key)
+		}
+		acc, err := types.NewStateAccount(accIt.Value, true)
+		if err != nil {
+			t.Fatalf("invalid account: %v", err)
+		}
+		if acc.Root == types.GetEmptyRootHash(true) {
+			continue
+		}
+		storage, err := trie.NewZkMerkleStateTrie(acc.Root, triedb)
+		if err != nil {
+			t.Fatalf("failed to open storage %x: %v", acc.Root, err)
+		}
+		storageIt := trie.NewIterator(storage.MustNodeIterator(nil))
+		for storageIt.Next() {
+			if preimage := triedb.Preimage(*trie.IteratorKeyToHash(storageIt.Key, true)); preimage == nil {
+				t.Fatalf("missing slot preimage %x", storageIt.Key)
+			}
+		}
+		if storageIt.Err != nil {
+			t.Fatalf("failed to iterate storage %x: %v", acc.Root, storageIt.Err)
+		}
+	}
+	if accIt.Err != nil {
+		t.Fatalf("failed to iterate state %x: %v", root, accIt.Err)
+	}
+}
+
+func TestPruneZkState(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	bc, chain := makeZkChain(t, db, 8)
+	bc.Stop()
+
+	var (
+		target  = chain[5].Root()
+		stale   = chain[2].Root()
+		genesis = rawdb.ReadBlock(db, rawdb.ReadCanonicalHash(db, 0), 0).Root()
+	)
+	if !hasStateRoot(db, stale, true) {
+		t.Fatal("stale state is not available before pruning")
+	}
+	// Slot 0 of the contract is live in the target state, while the dangling
+	// preimage doesn't belong to any state.
+	var (
+		slot0    = zkPreimageHash(t, common.Hash{}.Bytes())
+		dangling = common.HexToHash("0xdeadbeef")
+	)
+	rawdb.WritePreimages(db, map[common.Hash][]byte{dangling: {0x01}})
+
+	pruner, err := NewPruner(db, Config{Datadir: t.TempDir(), BloomSize: 256})
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if !pruner.zk {
+		t.Fatal("zktrie state not detected")
+	}
+	if err := pruner.Prune(target); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if hasStateRoot(db, stale, true) {
+		t.Fatal("stale state is not pruned")
+	}
+	triedb := trie.NewDatabase(db, &trie.Config{Zktrie: true, Preimages: true})
+	checkZkState(t, triedb, target)
+	checkZkState(t, triedb, genesis)
+
+	if rawdb.ReadPreimage(db, dangling) != nil {
+		t.Fatal("dangling preimage is not pruned")
+	}
+	if rawdb.ReadPreimage(db, slot0) == nil {
+		t.Fatal("live preimage is pruned")
+	}
+	// The pruned database must be usable for further processing.
+	statedb, err := state.New(target, state.NewDatabaseWithNodeDB(db, triedb), nil)
+	if err != nil {
+		t.Fatalf("failed to open pruned state: %v", err)
+	}
+	if got := statedb.GetState(storageContract, common.Hash{}); got != common.BigToHash(big.NewInt(6)) {
+		t.Fatalf("slot mismatch: have %x, want %x", got, common.BigToHash(big.NewInt(6)))
+	}
+}
+
+// zkPreimageHash returns the hash under which the preimage of the given zk trie
+// key is stored.
+func zkPreimageHash(t *testing.T, key []byte) common.Hash {
+	secureKey, err := zkt.ToSecureKey(key)
+	if err != nil {
+		t.Fatalf("failed to hash key: %v", err)
+	}
+	return common.BytesToHash(secureKey.Bytes())
+}
+
+// TestPruneZkStateScheduledMPT checks that the zk state is still detected when the
+// stored chain config has the KromaMPT fork scheduled and the zktrie flag cleared.
+func TestPruneZkStateScheduledMPT(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	bc, chain := makeZkChain(t, db, 8)
+	bc.Stop()
+
+	genesisHash := rawdb.ReadCanonicalHash(db, 0)
+	config := rawdb.ReadChainConfig(db, genesisHash)
+	config.Zktrie = false
+	config.KromaMPTTime = new(uint64)
+	*config.KromaMPTTime = chain[len(chain)-1].Time() + 1
+	rawdb.WriteChainConfig(db, genesisHash, config)
+
+	pruner, err := NewPruner(db, Config{Datadir: t.TempDir(), BloomSize: 256})
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if !pruner.zk {
+		t.Fatal("zktrie state not detected")
+	}
+	if err := pruner.Prune(chain[5].Root()); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	triedb := trie.NewDatabase(db, &trie.Config{Zktrie: true, Preimages: true})
+	checkZkState(t, triedb, chain[5].Root())
+	checkZkState(t, triedb, rawdb.ReadBlock(db, genesisHash, 0).Root())
+}
@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/triestate"
@@ -348,6 +349,41 @@ func (s *StateDB) GetStorageRoot(addr common.Address) common.Hash {
 	return common.Hash{}
 }
 
+// [Kroma: START]
+
+// CheckTransactionConditional checks the known accounts of the conditional against the
+// current state, including the storage mutations of the transactions executed so far.
+func (s *StateDB) CheckTransactionConditional(cond *types.TransactionConditional) error {
+	// Hashing the storage commits the pending slots into the storage tries, so the
+	// roots are computed on a copy to leave the state being built untouched.
+	var copied *StateDB
+	for addr, account := range cond.KnownAccounts {
+		if account.StorageRoot != nil {
+			if copied == nil {
+				copied = s.Copy()
+			}
+			root := types.EmptyRootHash
+			if obj := copied.getStateObject(addr); obj != nil {
+				obj.updateRoot()
+				if obj.Root() != (common.Hash{}) {
+					root = obj.Root()
+				}
+			}
+			if want := *account.StorageRoot; want != root && !(want == (common.Hash{}) && root == types.EmptyRootHash) {
+				return fmt.Errorf("storage root of %v mismatch: have %v, want %v", addr, root, want)
+			}
+		}
+		for slot, want := range account.StorageSlots {
+			if have := s.GetState(addr, slot); have != want {
+				return fmt.Errorf("storage slot %v of %v mismatch: have %v, want %v", slot, addr, have, want)
+			}
+		}
+	}
+	return nil
+}
+
+// [Kroma: END]
+
 // TxIndex returns the current transaction index set by Prepare.
 func (s *StateDB) TxIndex() int {
 	return s.txIndex
@@ -1155,8 +1191,17 @@ func (s *StateDB) slowDeleteStorage(addr common.Address, addrHash common.Hash, r
 			return true, size, nil, nil, nil
 		}
 		if it.Leaf() {
-			slots[common.BytesToHash(it.LeafKey())] = common.CopyBytes(it.LeafBlob())
-			size += common.StorageSize(common.HashLength + len(it.LeafBlob()))
+			key, blob := common.BytesToHash(it.LeafKey()), common.CopyBytes(it.LeafBlob())
+			if s.IsZktrie() {
+				// The leaves of the zk trie are standalone nodes holding the raw
+				// slot values, keyed by the iterator key.
+				key = *trie.IteratorKeyToHash(it.LeafKey(), true)
+				blob, _ = rlp.EncodeToBytes(common.TrimLeftZeroes(blob))
+				size += common.StorageSize(len(it.Path()))
+				nodes.AddNode(it.Path(), trienode.NewDeleted())
+			}
+			slots[key] = blob
+			size += common.StorageSize(common.HashLength + len(blob))
 			continue
 		}
 		if it.Hash() == (common.Hash{}) {
@@ -1188,10 +1233,13 @@ func (s *StateDB) deleteStorage(addr common.Address, addrHash common.Hash, root
 	// The fast approach can be failed if the snapshot is not fully
 	// generated, or it's internally corrupted. Fallback to the slow
 	// one just in case.
-	if s.snap != nil {
+	// The fast approach is not applicable to the zk trie, as the storage trie
+	// can't be regenerated with the stack trie.
+	fast := s.snap != nil && !s.IsZktrie()
+	if fast {
 		aborted, size, slots, nodes, err = s.fastDeleteStorage(addrHash, root)
 	}
-	if s.snap == nil || err != nil {
+	if !fast || err != nil {
 		aborted, size, slots, nodes, err = s.slowDeleteStorage(addr, addrHash, root)
 	}
 	if err != nil {
@@ -1262,7 +1310,7 @@ func (s *StateDB) handleDestruction(nodes *trienode.MergedNodeSet) (map[common.A
 		s.accountsOrigin[addr] = types.SlimAccountBytes(*prev, s.IsZktrie()) // case (c) or (d)
 
 		// Short circuit if the storage was empty.
-		if prev.Root == types.EmptyRootHash {
+		if prev.Root == types.GetEmptyRootHash(s.IsZktrie()) {
 			continue
 		}
 		// Remove storage slots belong to the account.
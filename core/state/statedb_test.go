@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/trie/triedb/hashdb"
@@ -1189,3 +1190,159 @@ func TestDeleteStorage(t *testing.T) {
 		t.Fatalf("difference found:\nfast: %v\nslow: %v\n", fastRes, slowRes)
 	}
 }
+
+// TestZkPathState tests that the zk trie states are maintained by the path-based
+// database, including journaling and state recovery.
+func TestZkPathState(t *testing.T) {
+	disk, err := rawdb.NewDatabaseWithFreezer(memorydb.New(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer disk.Close()
+
+	config := &trie.Config{Zktrie: true, PathDB: &pathdb.Config{StateHistory: 128, CleanCacheSize: 1024 * 1024}}
+	triedb := trie.NewDatabase(disk, config)
+	if !triedb.IsKromaZK() {
+		t.Fatal("path-based zk database doesn't use the zk merkle trie")
+	}
+	var (
+		contract = common.HexToAddress("0xaaaa")
+		accounts = make([]common.Address, 8)
+		roots    []common.Hash
+		states   []func(*StateDB) // Modifications applied in each block
+	)
+	for i := range accounts {
+		accounts[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	states = append(states, func(state *StateDB) {
+		for i, addr := range accounts {
+			state.SetBalance(addr, big.NewInt(int64(i+1)))
+		}
+		state.SetCode(contract, []byte{0x1})
+		for i := 0; i < 16; i++ {
+			state.SetState(contract, common.BigToHash(big.NewInt(int64(i))), common.BigToHash(big.NewInt(int64(i+1))))
+		}
+	})
+	states = append(states, func(state *StateDB) {
+		for i, addr := range accounts[:4] {
+			state.SetNonce(addr, uint64(i+1))
+		}
+		for i := 0; i < 8; i++ {
+			state.SetState(contract, common.BigToHash(big.NewInt(int64(i))), common.Hash{})
+		}
+		state.SetState(contract, common.BigToHash(big.NewInt(100)), common.BigToHash(big.NewInt(100)))
+	})
+	states = append(states, func(state *StateDB) {
+		state.SelfDestruct(contract)
+		state.SetBalance(accounts[0], big.NewInt(100))
+	})
+	// checkState checks the state of the given block against the expected one,
+	// which is regenerated in a separate hash-based database.
+	checkState := func(triedb *trie.Database, block int) {
+		t.Helper()
+
+		memdb := rawdb.NewMemoryDatabase()
+		want, _ := New(common.Hash{}, NewDatabaseWithNodeDB(memdb, trie.NewDatabase(memdb, &trie.Config{Zktrie: true, KromaZKTrie: true})), nil)
+		for _, modify := range states[:block+1] {
+			modify(want)
+			want.Finalise(true)
+		}
+		have, err := New(roots[block], NewDatabaseWithNodeDB(disk, triedb), nil)
+		if err != nil {
+			t.Fatalf("failed to open state %d: %v", block, err)
+		}
+		for _, addr := range append(accounts, contract) {
+			if have.GetBalance(addr).Cmp(want.GetBalance(addr)) != 0 || have.GetNonce(addr) != want.GetNonce(addr) {
+				t.Fatalf("state %d: account %x mismatch", block, addr)
+			}
+		}
+		for i := 0; i <= 100; i++ {
+			slot := common.BigToHash(big.NewInt(int64(i)))
+			if have, want := have.GetState(contract, slot), want.GetState(contract, slot); have != want {
+				t.Fatalf("state %d: slot %d mismatch: have %x, want %x", block, i, have, want)
+			}
+		}
+		if root := want.IntermediateRoot(true); root != roots[block] {
+			t.Fatalf("state %d: root mismatch: have %x, want %x", block, roots[block], root)
+		}
+	}
+	parent := common.Hash{}
+	for i, modify := range states {
+		state, err := New(parent, NewDatabaseWithNodeDB(disk, triedb), nil)
+		if err != nil {
+			t.Fatalf("failed to open state: %v", err)
+		}
+		modify(state)
+		root, err := state.Commit(uint64(i+1), true)
+		if err != nil {
+			t.Fatalf("failed to commit state %d: %v", i, err)
+		}
+		roots, parent = append(roots, root), root
+	}
+	for i := range states {
+		checkState(triedb, i)
+	}
+	// The diff layers must survive the restart with the journal.
+	if err := triedb.Journal(roots[2]); err != nil {
+		t.Fatalf("failed to journal: %v", err)
+	}
+	triedb.Close()
+	triedb = trie.NewDatabase(disk, config)
+	for i := range states {
+		checkState(triedb, i)
+	}
+	// Flush all the layers into the disk, the nodes must be resolved from there.
+	if err := triedb.Commit(roots[2], false); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	triedb.Close()
+	triedb = trie.NewDatabase(disk, config)
+	checkState(triedb, 2)
+
+	// Roll the disk state back to the first block with the state histories.
+	if ok, _ := triedb.Recoverable(roots[0]); !ok {
+		t.Fatal("first state is not recoverable")
+	}
+	if err := triedb.Recover(roots[0]); err != nil {
+		t.Fatalf("failed to recover: %v", err)
+	}
+	checkState(triedb, 0)
+	if _, err := New(roots[2], NewDatabaseWithNodeDB(disk, triedb), nil); err == nil {
+		t.Fatal("reverted state is still available")
+	}
+}
+
+func TestCheckTransactionConditional(t *testing.T) {
+	var (
+		state, _ = New(types.EmptyRootHash, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		addr     = common.HexToAddress("0xaaaa")
+		slot     = common.HexToHash("0x01")
+	)
+	state.SetNonce(addr, 1)
+	state.SetState(addr, slot, common.HexToHash("0x02"))
+	state.Finalise(true)
+
+	expected := state.Copy()
+	expected.IntermediateRoot(true)
+	root := expected.GetStorageRoot(addr)
+
+	cond := &types.TransactionConditional{KnownAccounts: types.KnownAccounts{
+		addr: {StorageRoot: &root, StorageSlots: map[common.Hash]common.Hash{slot: common.HexToHash("0x02")}},
+	}}
+	if err := state.CheckTransactionConditional(cond); err != nil {
+		t.Fatalf("conditional rejected: %v", err)
+	}
+	// The check must not flush the pending storage into the storage trie.
+	obj := state.getStateObject(addr)
+	if obj.Root() != types.EmptyRootHash || len(obj.pendingStorage) != 1 {
+		t.Fatalf("storage trie updated by the check: root %x, pending %d", obj.Root(), len(obj.pendingStorage))
+	}
+	wrong := common.HexToHash("0xdead")
+	cond.KnownAccounts[addr] = types.KnownAccount{StorageRoot: &wrong}
+	if err := state.CheckTransactionConditional(cond); err == nil {
+		t.Fatal("conditional with a mismatching storage root accepted")
+	}
+	if have := state.IntermediateRoot(true); have != expected.IntermediateRoot(true) {
+		t.Fatalf("state root mismatch: have %x, want %x", have, expected.IntermediateRoot(true))
+	}
+}
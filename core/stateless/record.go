@@ -0,0 +1,193 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateless
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+	"golang.org/x/exp/slices"
+)
+
+// errKromaMPTActivation is returned for the KromaMPT activation block, which is
+// executed on top of the migrated MPT state instead of the state of its parent.
+var errKromaMPTActivation = errors.New("witness of the kroma mpt activation block is not supported")
+
+// trieKind returns the name of the trie the state is held in.
+func trieKind(zk bool) string {
+	if zk {
+		return "zk trie"
+	}
+	return "mpt"
+}
+
+// Record executes the block on top of the state of its parent held in the given
+// database, and collects every trie node, contract code and ancestor header the
+// execution touched into a witness.
+func Record(chain core.ProcessorChain, db state.Database, block *types.Block) (*Witness, error) {
+	config := chain.Config()
+	if config.IsKromaMPTActivationBlock(block.Time()) {
+		return nil, errKromaMPTActivation
+	}
+	if zk := config.IsZkState(block.Time()); db.TrieDB().IsZk() != zk {
+		return nil, fmt.Errorf("state of block %d is not held in the %s", block.NumberU64(), trieKind(zk))
+	}
+	parent := chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %x of block %d not found", block.ParentHash(), block.NumberU64())
+	}
+	recorder := newRecorder(chain, db, parent)
+
+	// The snapshot is skipped deliberately, so that every account and storage
+	// slot is read through the tries.
+	statedb, err := state.New(parent.Root, recorder, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, _, err := core.NewStateProcessor(config, recorder, chain.Engine()).Process(block, statedb, vm.Config{}); err != nil {
+		return nil, err
+	}
+	// Hash the state to pull in the nodes needed for the trie updates as well.
+	if root := statedb.IntermediateRoot(config.IsEIP158(block.Number())); root != block.Root() {
+		return nil, fmt.Errorf("invalid merkle root (remote: %x local: %x) dberr: %w", block.Root(), root, statedb.Error())
+	}
+	return recorder.witness(), nil
+}
+
+// recorder wraps the state database and the chain the block is executed with,
+// tracking the tries opened, the codes read and the headers retrieved.
+type recorder struct {
+	state.Database
+	core.ProcessorChain
+
+	parent  *types.Header
+	tries   []state.Trie
+	codes   map[common.Hash][]byte
+	headers map[common.Hash]*types.Header
+}
+
+func newRecorder(chain core.ProcessorChain, db state.Database, parent *types.Header) *recorder {
+	return &recorder{
+		Database:       db,
+		ProcessorChain: chain,
+		parent:         parent,
+		codes:          make(map[common.Hash][]byte),
+		headers:        make(map[common.Hash]*types.Header),
+	}
+}
+
+// OpenTrie opens the main account trie, tracking it for the witness.
+func (r *recorder) OpenTrie(root common.Hash) (state.Trie, error) {
+	tr, err := r.openZkTrie(trie.StateTrieID(root))
+	if tr == nil && err == nil {
+		tr, err = r.Database.OpenTrie(root)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.tries = append(r.tries, tr)
+	return tr, nil
+}
+
+// OpenStorageTrie opens the storage trie of an account, tracking it for the witness.
+func (r *recorder) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, self state.Trie) (state.Trie, error) {
+	tr, err := r.openZkTrie(trie.StorageTrieID(stateRoot, crypto.Keccak256Hash(address.Bytes()), root))
+	if tr == nil && err == nil {
+		tr, err = r.Database.OpenStorageTrie(stateRoot, address, root, self)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.tries = append(r.tries, tr)
+	return tr, nil
+}
+
+// openZkTrie opens the zk trie with the given identifier recording the nodes
+// read, which the zk tries stored with the hash-based scheme only do if opted
+// in. Nil is returned for the other tries, which track the nodes read anyway.
+func (r *recorder) openZkTrie(id *trie.ID) (state.Trie, error) {
+	triedb := r.TrieDB()
+	switch {
+	case triedb.IsKromaZK():
+		if triedb.Scheme() == rawdb.PathScheme {
+			return nil, nil
+		}
+		return trie.NewZkMerkleStateTrieWithWitness(id, triedb)
+	case triedb.IsZk():
+		return trie.NewZkTrieWithWitness(id.Root, triedb)
+	}
+	return nil, nil
+}
+
+// ContractCode retrieves a particular contract's code, tracking it for the witness.
+func (r *recorder) ContractCode(addr common.Address, codeHash common.Hash) ([]byte, error) {
+	code, err := r.Database.ContractCode(addr, codeHash)
+	if err != nil {
+		return nil, err
+	}
+	r.codes[codeHash] = code
+	return code, nil
+}
+
+// ContractCodeSize retrieves a particular contract's code size. The whole code
+// is tracked for the witness, since the size can't be derived otherwise.
+func (r *recorder) ContractCodeSize(addr common.Address, codeHash common.Hash) (int, error) {
+	code, err := r.ContractCode(addr, codeHash)
+	return len(code), err
+}
+
+// GetHeader retrieves a block header, tracking it for the witness. The headers
+// are retrieved while resolving the BLOCKHASH opcode.
+func (r *recorder) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header := r.ProcessorChain.GetHeader(hash, number)
+	if header != nil {
+		r.headers[hash] = header
+	}
+	return header
+}
+
+// witness assembles the witness from everything tracked so far.
+func (r *recorder) witness() *Witness {
+	w := NewWitness(r.parent)
+	for _, tr := range r.tries {
+		w.AddState(tr.Witness())
+	}
+	for _, code := range r.codes {
+		w.AddCode(code)
+	}
+	// The ancestors are walked one by one from the parent while resolving the
+	// block hashes, so the tracked ones are consecutive.
+	var headers []*types.Header
+	for hash, header := range r.headers {
+		if hash != r.parent.Hash() {
+			headers = append(headers, header)
+		}
+	}
+	slices.SortFunc(headers, func(a, b *types.Header) int {
+		return b.Number.Cmp(a.Number)
+	})
+	w.Headers = append(w.Headers, headers...)
+	return w
+}
@@ -0,0 +1,166 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateless
+
+import (
+	"fmt"
+	"math/big"
+
+	zktrie "github.com/kroma-network/zktrie/trie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// MakeStateDB rebuilds the pre-state of the block with the given timestamp from
+// the witness into an ephemeral in-memory database. The trie nodes are stored by
+// hash, in the zk trie or the MPT depending on whether the KromaMPT fork is active.
+func (w *Witness) MakeStateDB(config *params.ChainConfig, time uint64) (*state.StateDB, error) {
+	var (
+		memdb  = rawdb.NewMemoryDatabase()
+		triedb *trie.Database
+	)
+	if config.IsZkState(time) {
+		triedb = trie.NewDatabase(memdb, &trie.Config{Zktrie: true, KromaZKTrie: true})
+		for blob := range w.State {
+			node, err := zktrie.NewNodeFromBytes([]byte(blob))
+			if err != nil {
+				return nil, fmt.Errorf("invalid zk trie node in witness: %w", err)
+			}
+			hash, err := node.NodeHash()
+			if err != nil {
+				return nil, fmt.Errorf("invalid zk trie node in witness: %w", err)
+			}
+			if err := triedb.Put(hash[:], []byte(blob)); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		triedb = trie.NewDatabase(memdb, trie.HashDefaults)
+		for blob := range w.State {
+			rawdb.WriteLegacyTrieNode(memdb, crypto.Keccak256Hash([]byte(blob)), []byte(blob))
+		}
+	}
+	for code := range w.Codes {
+		rawdb.WriteCode(memdb, crypto.Keccak256Hash([]byte(code)), []byte(code))
+	}
+	return state.New(w.Root(), state.NewDatabaseWithNodeDB(memdb, triedb), nil)
+}
+
+// Execute runs the block statelessly on top of the state held in the witness,
+// returning the post state root and the receipts. The block hashes are resolved
+// from the ancestor headers in the witness.
+func Execute(config *params.ChainConfig, engine consensus.Engine, block *types.Block, witness *Witness) (common.Hash, types.Receipts, error) {
+	if config.IsKromaMPTActivationBlock(block.Time()) {
+		return common.Hash{}, nil, errKromaMPTActivation
+	}
+	if err := witness.sanitize(block); err != nil {
+		return common.Hash{}, nil, err
+	}
+	statedb, err := witness.MakeStateDB(config, block.Time())
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	chain := newWitnessChain(config, engine, block, witness)
+	receipts, _, _, err := core.NewStateProcessor(config, chain, engine).Process(block, statedb, vm.Config{})
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	root := statedb.IntermediateRoot(config.IsEIP158(block.Number()))
+	if err := statedb.Error(); err != nil {
+		return common.Hash{}, nil, fmt.Errorf("incomplete witness: %w", err)
+	}
+	return root, receipts, nil
+}
+
+// Verify runs the block statelessly on top of the state held in the witness,
+// and checks the post state root against the one of the block.
+func Verify(config *params.ChainConfig, engine consensus.Engine, block *types.Block, witness *Witness) error {
+	root, _, err := Execute(config, engine, block, witness)
+	if err != nil {
+		return err
+	}
+	if root != block.Root() {
+		return fmt.Errorf("invalid merkle root (remote: %x local: %x)", block.Root(), root)
+	}
+	return nil
+}
+
+// witnessChain is the view of the chain rebuilt from the ancestor headers held
+// in a witness, which is used to execute the block statelessly.
+type witnessChain struct {
+	config  *params.ChainConfig
+	engine  consensus.Engine
+	current *types.Header
+	headers map[common.Hash]*types.Header
+	numbers map[uint64]*types.Header
+}
+
+func newWitnessChain(config *params.ChainConfig, engine consensus.Engine, block *types.Block, witness *Witness) *witnessChain {
+	chain := &witnessChain{
+		config:  config,
+		engine:  engine,
+		current: block.Header(),
+		headers: make(map[common.Hash]*types.Header, len(witness.Headers)),
+		numbers: make(map[uint64]*types.Header, len(witness.Headers)),
+	}
+	for _, header := range witness.Headers {
+		chain.headers[header.Hash()] = header
+		chain.numbers[header.Number.Uint64()] = header
+	}
+	return chain
+}
+
+// Config retrieves the chain's configuration.
+func (c *witnessChain) Config() *params.ChainConfig { return c.config }
+
+// Engine retrieves the chain's consensus engine.
+func (c *witnessChain) Engine() consensus.Engine { return c.engine }
+
+// CurrentHeader retrieves the header of the block being executed.
+func (c *witnessChain) CurrentHeader() *types.Header { return c.current }
+
+// GetHeader retrieves an ancestor header from the witness by hash and number.
+func (c *witnessChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+
+// GetHeaderByNumber retrieves an ancestor header from the witness by number.
+func (c *witnessChain) GetHeaderByNumber(number uint64) *types.Header {
+	return c.numbers[number]
+}
+
+// GetHeaderByHash retrieves an ancestor header from the witness by hash.
+func (c *witnessChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	return c.headers[hash]
+}
+
+// GetTd is not available in the witness, nil is always returned.
+func (c *witnessChain) GetTd(hash common.Hash, number uint64) *big.Int {
+	return nil
+}
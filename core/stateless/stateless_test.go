@@ -0,0 +1,245 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateless
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddress = crypto.PubkeyToAddress(testKey.PublicKey)
+
+	// storageContract stores the calldata word at slot 0 and clears slot 1.
+	storageContract = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+	storageCode     = []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x01, byte(vm.SSTORE),
+	}
+	// hashContract stores the hash of the block three blocks back at slot 0,
+	// which requires the ancestor headers to be resolved.
+	hashContract = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+	hashCode     = []byte{
+		byte(vm.PUSH1), 0x03, byte(vm.NUMBER), byte(vm.SUB), byte(vm.BLOCKHASH),
+		byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+	}
+	// sizeContract stores the code size of the storage contract at slot 0.
+	sizeContract = common.HexToAddress("0x000000000000000000000000000000000000cccc")
+	sizeCode     = []byte{
+		byte(vm.PUSH2), 0xaa, 0xaa, byte(vm.EXTCODESIZE), byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+	}
+)
+
+// makeChain generates a chain with the given length and trie configuration,
+// keeping the states of all blocks in the database.
+func makeChain(t *testing.T, scheme string, zktrie bool, kromaZK bool, blocks int) (*core.BlockChain, []*types.Block) {
+	config := *params.TestChainConfig
+	config.Zktrie = zktrie
+
+	gspec := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			testAddress:     {Balance: big.NewInt(params.Ether)},
+			storageContract: {Code: storageCode, Balance: common.Big0, Storage: map[common.Hash]common.Hash{{1}: {1}}},
+			hashContract:    {Code: hashCode, Balance: common.Big0},
+			sizeContract:    {Code: sizeCode, Balance: common.Big0},
+		},
+	}
+	// The blocks are generated on top of a chain, as the hash contract resolves
+	// the ancestors beyond the parent.
+	var (
+		engine    = ethash.NewFaker()
+		signer    = types.LatestSigner(gspec.Config)
+		genDb     = rawdb.NewMemoryDatabase()
+		genConfig = core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+		chain     []*types.Block
+	)
+	genConfig.TrieDirtyDisabled = true
+	gen, err := core.NewBlockChain(genDb, genConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create generator chain: %v", err)
+	}
+	defer gen.Stop()
+
+	for i := 0; i < blocks; i++ {
+		parent := gen.GetBlockByHash(gen.CurrentBlock().Hash())
+		generated, _ := core.GenerateChain(gspec.Config, parent, engine, genDb, 1, func(_ int, b *core.BlockGen) {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(testAddress), common.BigToAddress(big.NewInt(int64(i+1))), big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, testKey)
+			b.AddTxWithChain(gen, tx)
+			data := common.BigToHash(big.NewInt(int64(i + 1)))
+			tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(testAddress), storageContract, common.Big0, 100000, b.BaseFee(), data.Bytes()), signer, testKey)
+			b.AddTxWithChain(gen, tx)
+			tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(testAddress), hashContract, common.Big0, 100000, b.BaseFee(), nil), signer, testKey)
+			b.AddTxWithChain(gen, tx)
+			tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(testAddress), sizeContract, common.Big0, 100000, b.BaseFee(), nil), signer, testKey)
+			b.AddTxWithChain(gen, tx)
+		})
+		if _, err := gen.InsertChain(generated); err != nil {
+			t.Fatalf("failed to insert generated block: %v", err)
+		}
+		chain = append(chain, generated...)
+	}
+	// The witness is recorded without the snapshot, skip generating it.
+	cacheConfig := core.DefaultCacheConfigWithScheme(scheme)
+	cacheConfig.KromaZKTrie = kromaZK
+	cacheConfig.SnapshotLimit = 0
+
+	bc, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return bc, chain
+}
+
+func TestRecordAndVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		scheme  string
+		zktrie  bool
+		kromaZK bool
+	}{
+		{"mpt/hash", rawdb.HashScheme, false, false},
+		{"mpt/path", rawdb.PathScheme, false, false},
+		{"zktrie/hash", rawdb.HashScheme, true, false},
+		{"kromazk/hash", rawdb.HashScheme, true, true},
+		{"kromazk/path", rawdb.PathScheme, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bc, chain := makeChain(t, tt.scheme, tt.zktrie, tt.kromaZK, 5)
+			defer bc.Stop()
+
+			for _, block := range chain {
+				witness, err := Record(bc, bc.StateCache(), block)
+				if err != nil {
+					t.Fatalf("block %d: failed to record witness: %v", block.NumberU64(), err)
+				}
+				// The hash contract resolves the block three blocks back, which
+				// requires the parent and the grandparent headers.
+				want := 1
+				if block.NumberU64() >= 3 {
+					want = 2
+				}
+				if len(witness.Headers) != want {
+					t.Fatalf("block %d: header count mismatch: have %d, want %d", block.NumberU64(), len(witness.Headers), want)
+				}
+				if _, ok := witness.Codes[string(storageCode)]; !ok {
+					t.Fatalf("block %d: missing code of the storage contract", block.NumberU64())
+				}
+				if err := Verify(bc.Config(), bc.Engine(), block, witness); err != nil {
+					t.Fatalf("block %d: failed to verify witness: %v", block.NumberU64(), err)
+				}
+				// The witness must survive the transfer across clients.
+				blob, err := json.Marshal(witness)
+				if err != nil {
+					t.Fatalf("block %d: failed to encode witness: %v", block.NumberU64(), err)
+				}
+				var decoded Witness
+				if err := json.Unmarshal(blob, &decoded); err != nil {
+					t.Fatalf("block %d: failed to decode witness: %v", block.NumberU64(), err)
+				}
+				if err := Verify(bc.Config(), bc.Engine(), block, &decoded); err != nil {
+					t.Fatalf("block %d: failed to verify decoded witness: %v", block.NumberU64(), err)
+				}
+			}
+			// The zk tries opened outside the recording don't record the nodes.
+			if tt.zktrie && tt.scheme == rawdb.HashScheme {
+				tr, err := bc.StateCache().OpenTrie(bc.CurrentBlock().Root)
+				if err != nil {
+					t.Fatalf("failed to open trie: %v", err)
+				}
+				if _, err := tr.GetAccount(testAddress); err != nil {
+					t.Fatalf("failed to read account: %v", err)
+				}
+				if witness := tr.Witness(); witness != nil {
+					t.Fatalf("nodes recorded by the state trie: %d", len(witness))
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyIncompleteWitness(t *testing.T) {
+	bc, chain := makeChain(t, rawdb.HashScheme, false, false, 4)
+	defer bc.Stop()
+
+	block := chain[len(chain)-1]
+	witness, err := Record(bc, bc.StateCache(), block)
+	if err != nil {
+		t.Fatalf("failed to record witness: %v", err)
+	}
+	// Dropping the ancestor headers makes the block hash unresolvable.
+	noHeaders := &Witness{Headers: witness.Headers[:1], Codes: witness.Codes, State: witness.State}
+	if err := Verify(bc.Config(), bc.Engine(), block, noHeaders); err == nil {
+		t.Fatal("expected failure without the ancestor headers")
+	}
+	// Replacing an ancestor header with an unrelated one must be detected.
+	replaced := &Witness{Headers: []*types.Header{witness.Headers[0], chain[0].Header()}, Codes: witness.Codes, State: witness.State}
+	if err := Verify(bc.Config(), bc.Engine(), block, replaced); err == nil {
+		t.Fatal("expected failure with the unrelated header")
+	}
+	// Dropping the codes makes the contracts unexecutable.
+	noCodes := &Witness{Headers: witness.Headers, Codes: map[string]struct{}{}, State: witness.State}
+	if err := Verify(bc.Config(), bc.Engine(), block, noCodes); err == nil {
+		t.Fatal("expected failure without the codes")
+	}
+	// Dropping any trie node makes the state incomplete.
+	for node := range witness.State {
+		state := make(map[string]struct{}, len(witness.State))
+		for n := range witness.State {
+			if n != node {
+				state[n] = struct{}{}
+			}
+		}
+		if err := Verify(bc.Config(), bc.Engine(), block, &Witness{Headers: witness.Headers, Codes: witness.Codes, State: state}); err == nil {
+			t.Fatalf("expected failure without trie node %x", node)
+		}
+	}
+}
+
+func TestRecordTrieMismatch(t *testing.T) {
+	bc, chain := makeChain(t, rawdb.HashScheme, false, false, 1)
+	defer bc.Stop()
+
+	config := *bc.Config()
+	config.Zktrie = true
+	if _, err := Record(&configChain{bc, &config}, bc.StateCache(), chain[0]); err == nil {
+		t.Fatal("expected failure recording a zk block over the mpt")
+	}
+}
+
+// configChain overrides the config of the chain.
+type configChain struct {
+	*core.BlockChain
+	config *params.ChainConfig
+}
+
+func (c *configChain) Config() *params.ChainConfig { return c.config }
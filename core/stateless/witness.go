@@ -0,0 +1,144 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package stateless implements the execution witness of a block, which holds
+// all the state needed to execute the block without access to the database.
+package stateless
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/exp/slices"
+)
+
+// Witness encompasses the state required to apply a set of transactions and
+// derive a post state/receipt root.
+//
+// The trie nodes are the ones of the zk trie or the MPT, depending on whether
+// the block is executed before or after the KromaMPT fork.
+type Witness struct {
+	Headers []*types.Header     // Past headers in reverse order (0=parent, 1=parent's-parent, etc). First *must* be set.
+	Codes   map[string]struct{} // Set of bytecodes ran or accessed
+	State   map[string]struct{} // Set of trie nodes (account and storage together)
+}
+
+// NewWitness creates an empty witness on top of the given parent header.
+func NewWitness(parent *types.Header) *Witness {
+	return &Witness{
+		Headers: []*types.Header{parent},
+		Codes:   make(map[string]struct{}),
+		State:   make(map[string]struct{}),
+	}
+}
+
+// AddCode adds a bytecode to the witness.
+func (w *Witness) AddCode(code []byte) {
+	if len(code) == 0 {
+		return
+	}
+	w.Codes[string(code)] = struct{}{}
+}
+
+// AddState inserts a batch of trie nodes into the witness.
+func (w *Witness) AddState(nodes map[string]struct{}) {
+	for node := range nodes {
+		w.State[node] = struct{}{}
+	}
+}
+
+// Root returns the pre-state root from the first header.
+//
+// Note, this method will panic in case of a bad witness (but JSON decoding will
+// reject a witness without headers before that).
+func (w *Witness) Root() common.Hash {
+	return w.Headers[0].Root
+}
+
+// sanitize checks that the headers of the witness are the consecutive ancestors
+// of the given block, so that they can be trusted for the block hash lookups.
+func (w *Witness) sanitize(block *types.Block) error {
+	if len(w.Headers) == 0 {
+		return errors.New("witness has no headers")
+	}
+	if hash := w.Headers[0].Hash(); hash != block.ParentHash() {
+		return fmt.Errorf("witness parent mismatch: have %x, want %x", hash, block.ParentHash())
+	}
+	for i := 1; i < len(w.Headers); i++ {
+		if hash := w.Headers[i].Hash(); hash != w.Headers[i-1].ParentHash {
+			return fmt.Errorf("witness header %d is not the parent of header %d: have %x, want %x", i, i-1, hash, w.Headers[i-1].ParentHash)
+		}
+	}
+	return nil
+}
+
+// extWitness is a witness JSON encoding for transferring across clients.
+type extWitness struct {
+	Headers []*types.Header `json:"headers"`
+	Codes   []hexutil.Bytes `json:"codes"`
+	State   []hexutil.Bytes `json:"state"`
+}
+
+// MarshalJSON encodes the witness into JSON, sorting the codes and trie nodes
+// for a deterministic output.
+func (w *Witness) MarshalJSON() ([]byte, error) {
+	ext := &extWitness{
+		Headers: w.Headers,
+		Codes:   make([]hexutil.Bytes, 0, len(w.Codes)),
+		State:   make([]hexutil.Bytes, 0, len(w.State)),
+	}
+	for _, code := range sortedKeys(w.Codes) {
+		ext.Codes = append(ext.Codes, []byte(code))
+	}
+	for _, node := range sortedKeys(w.State) {
+		ext.State = append(ext.State, []byte(node))
+	}
+	return json.Marshal(ext)
+}
+
+// UnmarshalJSON decodes the witness from JSON.
+func (w *Witness) UnmarshalJSON(input []byte) error {
+	var ext extWitness
+	if err := json.Unmarshal(input, &ext); err != nil {
+		return err
+	}
+	if len(ext.Headers) == 0 {
+		return errors.New("witness has no headers")
+	}
+	w.Headers = ext.Headers
+	w.Codes = make(map[string]struct{}, len(ext.Codes))
+	for _, code := range ext.Codes {
+		w.Codes[string(code)] = struct{}{}
+	}
+	w.State = make(map[string]struct{}, len(ext.State))
+	for _, node := range ext.State {
+		w.State[string(node)] = struct{}{}
+	}
+	return nil
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
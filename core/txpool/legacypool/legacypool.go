@@ -1682,6 +1682,30 @@ func (pool *LegacyPool) demoteUnexecutables() {
 	for addr, list := range pool.pending {
 		nonce := pool.currentState.GetNonce(addr)
 
+		// [Kroma: START]
+		// Drop all transactions whose conditional was rejected by the miner, and
+		// postpone the ones depending on them
+		for _, tx := range list.Flatten() {
+			if !tx.Rejected() {
+				continue
+			}
+			if removed, invalids := list.Remove(tx); removed {
+				hash := tx.Hash()
+				pool.all.Remove(hash)
+				log.Trace("Removed rejected conditional transaction", "hash", hash)
+
+				for _, tx := range invalids {
+					// Internal shuffle shouldn't touch the lookup set.
+					pool.enqueueTx(tx.Hash(), tx, false, false)
+				}
+				pendingGauge.Dec(int64(1 + len(invalids)))
+				if pool.locals.contains(addr) {
+					localGauge.Dec(1)
+				}
+			}
+		}
+		// [Kroma: END]
+
 		// Drop all transactions that are deemed too old (low nonce)
 		olds := list.Forward(nonce)
 		for _, tx := range olds {
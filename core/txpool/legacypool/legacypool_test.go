@@ -691,6 +691,44 @@ func TestDropping(t *testing.T) {
 	}
 }
 
+// Tests that transactions whose conditional was rejected by the miner are dropped
+// on the next reset, postponing the ones depending on them.
+func TestDroppingRejectedConditional(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Close()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, account, big.NewInt(1000000))
+
+	txs := []*types.Transaction{transaction(0, 100000, key), transaction(1, 100000, key), transaction(2, 100000, key)}
+	txs[1].SetConditional(&types.TransactionConditional{})
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	if pool.pending[account].Len() != 3 {
+		t.Fatalf("pending transaction mismatch: have %d, want %d", pool.pending[account].Len(), 3)
+	}
+	txs[1].SetRejected()
+	<-pool.requestReset(nil, nil)
+
+	if pool.pending[account].Len() != 1 {
+		t.Errorf("pending transaction mismatch: have %d, want %d", pool.pending[account].Len(), 1)
+	}
+	if pool.queue[account].Len() != 1 {
+		t.Errorf("queued transaction mismatch: have %d, want %d", pool.queue[account].Len(), 1)
+	}
+	if pool.all.Get(txs[1].Hash()) != nil {
+		t.Errorf("rejected transaction not dropped")
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that if a transaction is dropped from the current pending pool (e.g. out
 // of fund), all consecutive (still valid, but not executable) transactions are
 // postponed back into the future queue to prevent broadcasting them.
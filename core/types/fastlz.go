@@ -0,0 +1,143 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+// The FastLZ (level 1) compression below follows the LibZip library of Solady,
+// see https://github.com/Vectorized/solady/blob/main/src/utils/LibZip.sol, which
+// is the reference of the compressed size estimation used by the L1 cost function.
+// The compressed data is not used anywhere but in tests, yet the compressor is
+// kept complete so that the size estimation can be verified by decompression.
+
+const (
+	flzHashLog  = 13
+	flzHashSize = 1 << flzHashLog
+	flzMaxDist  = 0x1fff
+)
+
+// FlzCompressLen returns the length of the data after FastLZ compression.
+func FlzCompressLen(data []byte) uint32 {
+	_, n := flzCompress(data, false)
+	return n
+}
+
+// FlzCompress compresses the data with FastLZ.
+func FlzCompress(data []byte) []byte {
+	out, _ := flzCompress(data, true)
+	return out
+}
+
+// flzCompress compresses the data with FastLZ and returns the length of the
+// compressed data, along with the compressed data itself if emit is set.
+func flzCompress(ib []byte, emit bool) ([]byte, uint32) {
+	var (
+		ob []byte
+		n  uint32
+		ht = make([]uint32, flzHashSize)
+	)
+	u24 := func(i uint32) uint32 {
+		return uint32(ib[i]) | uint32(ib[i+1])<<8 | uint32(ib[i+2])<<16
+	}
+	hash := func(v uint32) uint32 {
+		return (2654435769 * v) >> 19 & (flzHashSize - 1)
+	}
+	// cmp returns the length of the common prefix of the data at p and q, up to
+	// the end e, plus one for the first mismatching byte.
+	cmp := func(p, q, e uint32) uint32 {
+		l := uint32(0)
+		for e -= q; l < e; l++ {
+			if ib[p+l] != ib[q+l] {
+				e = 0
+			}
+		}
+		return l
+	}
+	literals := func(r, s uint32) {
+		for ; r >= 0x20; r -= 0x20 {
+			n += 0x21
+			if emit {
+				ob = append(append(ob, 0x1f), ib[s:s+0x20]...)
+			}
+			s += 0x20
+		}
+		if r != 0 {
+			n += r + 1
+			if emit {
+				ob = append(append(ob, byte(r-1)), ib[s:s+r]...)
+			}
+		}
+	}
+	match := func(l, d uint32) {
+		for d--; l >= 263; l -= 262 {
+			n += 3
+			if emit {
+				ob = append(ob, byte(224+(d>>8)), 253, byte(d))
+			}
+		}
+		if l >= 7 {
+			n += 3
+			if emit {
+				ob = append(ob, byte(224+(d>>8)), byte(l-7), byte(d))
+			}
+		} else {
+			n += 2
+			if emit {
+				ob = append(ob, byte(l<<5+(d>>8)), byte(d))
+			}
+		}
+	}
+	setNextHash := func(ip uint32) uint32 {
+		ht[hash(u24(ip))] = ip
+		return ip + 1
+	}
+	var (
+		a       = uint32(0)
+		ipLimit = uint32(0)
+	)
+	if len(ib) > 13 {
+		ipLimit = uint32(len(ib)) - 13
+	}
+	for ip := a + 2; ip < ipLimit; {
+		var r, d uint32
+		for {
+			s := u24(ip)
+			h := hash(s)
+			r = ht[h]
+			ht[h] = ip
+			d = ip - r
+			if ip >= ipLimit {
+				break
+			}
+			ip++
+			if d <= flzMaxDist && s == u24(r) {
+				break
+			}
+		}
+		if ip >= ipLimit {
+			break
+		}
+		ip--
+		if ip > a {
+			literals(ip-a, a)
+		}
+		l := cmp(r+3, ip+3, ipLimit+9)
+		match(l, d)
+		ip = setNextHash(setNextHash(ip + l))
+		a = ip
+	}
+	literals(uint32(len(ib))-a, a)
+	return ob, n
+}
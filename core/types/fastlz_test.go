@@ -0,0 +1,132 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// flzDecompress decompresses FastLZ (level 1) compressed data.
+func flzDecompress(ib []byte) ([]byte, error) {
+	var ob []byte
+	for ip := 0; ip < len(ib); {
+		ctrl := int(ib[ip])
+		ip++
+		if ctrl < 0x20 {
+			if ip+ctrl+1 > len(ib) {
+				return nil, fmt.Errorf("literal run out of bounds at %d", ip)
+			}
+			ob = append(ob, ib[ip:ip+ctrl+1]...)
+			ip += ctrl + 1
+			continue
+		}
+		l := ctrl >> 5
+		if l == 7 {
+			if ip >= len(ib) {
+				return nil, fmt.Errorf("truncated match length at %d", ip)
+			}
+			l += int(ib[ip])
+			ip++
+		}
+		if ip >= len(ib) {
+			return nil, fmt.Errorf("truncated match distance at %d", ip)
+		}
+		ref := len(ob) - (ctrl&0x1f)<<8 - int(ib[ip]) - 1
+		ip++
+		if ref < 0 {
+			return nil, fmt.Errorf("match reference out of bounds at %d", ip)
+		}
+		for i := 0; i < l+2; i++ {
+			ob = append(ob, ob[ref+i])
+		}
+	}
+	return ob, nil
+}
+
+func TestFlzCompress(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		rng.Read(b)
+		return b
+	}
+	tests := [][]byte{
+		nil,
+		{0x01},
+		bytes.Repeat([]byte{0x00}, 13),
+		bytes.Repeat([]byte{0x00}, 14),
+		bytes.Repeat([]byte{0x00}, 1000),
+		bytes.Repeat([]byte{0xab, 0xcd, 0xef}, 500),
+		random(31),
+		random(32),
+		random(33),
+		random(10000),
+		append(random(100), bytes.Repeat(random(50), 200)...),
+		common.FromHex("dd80808094095e7baea6a6c7c4c2dfeb977efac326af552d8780808080"),
+	}
+	for i, data := range tests {
+		compressed := FlzCompress(data)
+		if have, want := FlzCompressLen(data), uint32(len(compressed)); have != want {
+			t.Errorf("test %d: compressed length mismatch: have %d, want %d", i, have, want)
+		}
+		decompressed, err := flzDecompress(compressed)
+		if err != nil {
+			t.Errorf("test %d: failed to decompress: %v", i, err)
+			continue
+		}
+		if !bytes.Equal(decompressed, data) {
+			t.Errorf("test %d: roundtrip mismatch: have %x, want %x", i, decompressed, data)
+		}
+	}
+	// Repetitive data should compress well, random data should not.
+	if n := FlzCompressLen(bytes.Repeat([]byte{0x00}, 1000)); n > 32 {
+		t.Errorf("repetitive data compressed poorly: %d bytes", n)
+	}
+	if n := FlzCompressLen(random(1000)); n < 1000 {
+		t.Errorf("random data compressed unexpectedly: %d bytes", n)
+	}
+}
+
+// TestFlzCompressLen checks the compression against the known answers of the
+// reference implementation in solady, which the L1 cost function of the
+// network is defined by.
+func TestFlzCompressLen(t *testing.T) {
+	tests := []struct {
+		input []byte
+		want  []byte // nil if only the length is known
+		len   uint32
+	}{
+		{[]byte{}, []byte{}, 0},
+		{[]byte{0x01}, common.FromHex("0x0001"), 2},
+		{bytes.Repeat([]byte{0xab, 0xcd, 0xef}, 10), common.FromHex("0x02abcdefe00d0204cdefabcdef"), 13},
+		{make([]byte, 1000), common.FromHex("0x010000e0fd01e0fd01e0fd01e0c601040000000000"), 21},
+		{bytes.Repeat([]byte{0x01}, 1000), nil, 21},
+	}
+	for i, tt := range tests {
+		if have := FlzCompressLen(tt.input); have != tt.len {
+			t.Errorf("test %d: compressed length mismatch: have %d, want %d", i, have, tt.len)
+		}
+		if have := FlzCompress(tt.input); tt.want != nil && !bytes.Equal(have, tt.want) {
+			t.Errorf("test %d: compressed data mismatch: have %x, want %x", i, have, tt.want)
+		}
+	}
+}
@@ -46,23 +46,29 @@ func NewFeeDistributionFunc(config *params.ChainConfig, statedb StateGetter) Fee
 
 		if blockNum != cacheBlockNum {
 			scalar = statedb.GetState(KromaL1BlockAddr, ValidatorRewardScalarSlot).Big().Uint64()
-			if scalar > 10000 {
-				scalar = 0
-			}
-
 			cacheBlockNum = blockNum
 		}
 		fee := new(big.Int)
 		fee.Mul(new(big.Int).SetUint64(gasUsed), baseFee)
 		fee.Add(fee, new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), effectiveTip))
 
-		R := big.NewRat(int64(scalar), 10000)
-		reward := new(big.Int).Mul(fee, R.Num())
-		reward.Div(reward, R.Denom())
+		return newFeeDistribution(fee, scalar)
+	}
+}
+
+// newFeeDistribution splits the fee into the validator reward and the protocol fee
+// by the validator reward scalar, which is in basis points. An out of range scalar
+// gives the whole fee to the protocol.
+func newFeeDistribution(fee *big.Int, scalar uint64) *FeeDistribution {
+	if scalar > 10000 {
+		scalar = 0
+	}
+	R := big.NewRat(int64(scalar), 10000)
+	reward := new(big.Int).Mul(fee, R.Num())
+	reward.Div(reward, R.Denom())
 
-		return &FeeDistribution{
-			Reward:   reward,
-			Protocol: new(big.Int).Sub(fee, reward),
-		}
+	return &FeeDistribution{
+		Reward:   reward,
+		Protocol: new(big.Int).Sub(fee, reward),
 	}
 }
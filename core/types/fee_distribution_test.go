@@ -0,0 +1,102 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+type feeDistributionStateGetter struct {
+	scalar uint64
+}
+
+func (sg *feeDistributionStateGetter) GetState(addr common.Address, slot common.Hash) common.Hash {
+	if addr == KromaL1BlockAddr && slot == ValidatorRewardScalarSlot {
+		return common.BigToHash(new(big.Int).SetUint64(sg.scalar))
+	}
+	return common.Hash{}
+}
+
+func TestNewFeeDistribution(t *testing.T) {
+	fee := big.NewInt(1_000_001)
+	tests := []struct {
+		scalar           uint64
+		reward, protocol *big.Int
+	}{
+		{0, big.NewInt(0), big.NewInt(1_000_001)},
+		{2500, big.NewInt(250_000), big.NewInt(750_001)},
+		{10000, big.NewInt(1_000_001), big.NewInt(0)},
+		{10001, big.NewInt(0), big.NewInt(1_000_001)}, // out of range
+	}
+	for _, tt := range tests {
+		feeDist := newFeeDistribution(fee, tt.scalar)
+		require.Zero(t, tt.reward.Cmp(feeDist.Reward), "scalar %d: reward mismatch", tt.scalar)
+		require.Zero(t, tt.protocol.Cmp(feeDist.Protocol), "scalar %d: protocol fee mismatch", tt.scalar)
+	}
+}
+
+func TestFeeDistributionFunc(t *testing.T) {
+	distFunc := NewFeeDistributionFunc(params.KromaTestConfig, &feeDistributionStateGetter{scalar: 5000})
+	// fee = 100 * (7 + 3) = 1000
+	feeDist := distFunc(1, 100, big.NewInt(7), big.NewInt(3))
+	require.Equal(t, big.NewInt(500), feeDist.Reward)
+	require.Equal(t, big.NewInt(500), feeDist.Protocol)
+
+	require.Nil(t, NewFeeDistributionFunc(params.TestChainConfig, nil)(1, 100, big.NewInt(7), big.NewInt(3)))
+}
+
+func TestDeriveFeeDistribution(t *testing.T) {
+	zeroTime := uint64(0)
+	config := &params.ChainConfig{
+		ChainID:      big.NewInt(1),
+		Kroma:        params.KromaTestConfig.Kroma,
+		BedrockBlock: big.NewInt(0),
+		RegolithTime: &zeroTime,
+		EcotoneTime:  &zeroTime,
+	}
+	data := getEcotoneL1Attributes(baseFee, blobBaseFee, baseFeeScalar, blobBaseFeeScalar)
+	big.NewInt(2500).FillBytes(data[164:196]) // validatorRewardScalar
+
+	txs := Transactions{
+		NewTx(&DepositTx{Data: data}),
+		NewTx(&LegacyTx{Nonce: 1, GasPrice: big.NewInt(10), Gas: 21000}),
+	}
+	receipts := Receipts{
+		&Receipt{Type: DepositTxType, CumulativeGasUsed: 100},
+		&Receipt{CumulativeGasUsed: 100 + 21000},
+	}
+	require.NoError(t, receipts.DeriveFields(config, common.Hash{}, 1, 0, big.NewInt(1), nil, txs))
+
+	require.Nil(t, receipts[0].ValidatorReward)
+	require.Nil(t, receipts[0].ProtocolFee)
+	// fee = 21000 * 10 = 210000
+	require.Equal(t, big.NewInt(52500), receipts[1].ValidatorReward)
+	require.Equal(t, big.NewInt(157500), receipts[1].ProtocolFee)
+
+	// The fee is not distributed after the Kroma MPT upgrade.
+	config.KromaMPTTime = &zeroTime
+	receipts[1].ValidatorReward, receipts[1].ProtocolFee = nil, nil
+	txs[0] = NewTx(&DepositTx{Data: data[:164]})
+	require.NoError(t, receipts.DeriveFields(config, common.Hash{}, 1, 0, big.NewInt(1), nil, txs))
+	require.Nil(t, receipts[1].ValidatorReward)
+	require.Nil(t, receipts[1].ProtocolFee)
+}
@@ -37,6 +37,11 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 		L1GasUsed             *hexutil.Big    `json:"l1GasUsed,omitempty"`
 		L1Fee                 *hexutil.Big    `json:"l1Fee,omitempty"`
 		FeeScalar             *big.Float      `json:"l1FeeScalar,omitempty"`
+		L1BlobBaseFee         *hexutil.Big    `json:"l1BlobBaseFee,omitempty"`
+		L1BaseFeeScalar       *hexutil.Uint64 `json:"l1BaseFeeScalar,omitempty"`
+		L1BlobBaseFeeScalar   *hexutil.Uint64 `json:"l1BlobBaseFeeScalar,omitempty"`
+		ValidatorReward       *hexutil.Big    `json:"validatorReward,omitempty"`
+		ProtocolFee           *hexutil.Big    `json:"protocolFee,omitempty"`
 		ReturnValue           []byte          `json:"returnValue,omitempty"`
 	}
 	var enc Receipt
@@ -61,6 +66,11 @@ func (r Receipt) MarshalJSON() ([]byte, error) {
 	enc.L1GasUsed = (*hexutil.Big)(r.L1GasUsed)
 	enc.L1Fee = (*hexutil.Big)(r.L1Fee)
 	enc.FeeScalar = r.FeeScalar
+	enc.L1BlobBaseFee = (*hexutil.Big)(r.L1BlobBaseFee)
+	enc.L1BaseFeeScalar = (*hexutil.Uint64)(r.L1BaseFeeScalar)
+	enc.L1BlobBaseFeeScalar = (*hexutil.Uint64)(r.L1BlobBaseFeeScalar)
+	enc.ValidatorReward = (*hexutil.Big)(r.ValidatorReward)
+	enc.ProtocolFee = (*hexutil.Big)(r.ProtocolFee)
 	enc.ReturnValue = r.ReturnValue
 	return json.Marshal(&enc)
 }
@@ -89,7 +99,12 @@ func (r *Receipt) UnmarshalJSON(input []byte) error {
 		L1GasUsed             *hexutil.Big    `json:"l1GasUsed,omitempty"`
 		L1Fee                 *hexutil.Big    `json:"l1Fee,omitempty"`
 		FeeScalar             *big.Float      `json:"l1FeeScalar,omitempty"`
-		ReturnValue       []byte          `json:"returnValue,omitempty"`
+		L1BlobBaseFee         *hexutil.Big    `json:"l1BlobBaseFee,omitempty"`
+		L1BaseFeeScalar       *hexutil.Uint64 `json:"l1BaseFeeScalar,omitempty"`
+		L1BlobBaseFeeScalar   *hexutil.Uint64 `json:"l1BlobBaseFeeScalar,omitempty"`
+		ValidatorReward       *hexutil.Big    `json:"validatorReward,omitempty"`
+		ProtocolFee           *hexutil.Big    `json:"protocolFee,omitempty"`
+		ReturnValue           []byte          `json:"returnValue,omitempty"`
 	}
 	var dec Receipt
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -163,6 +178,21 @@ func (r *Receipt) UnmarshalJSON(input []byte) error {
 	if dec.FeeScalar != nil {
 		r.FeeScalar = dec.FeeScalar
 	}
+	if dec.L1BlobBaseFee != nil {
+		r.L1BlobBaseFee = (*big.Int)(dec.L1BlobBaseFee)
+	}
+	if dec.L1BaseFeeScalar != nil {
+		r.L1BaseFeeScalar = (*uint64)(dec.L1BaseFeeScalar)
+	}
+	if dec.L1BlobBaseFeeScalar != nil {
+		r.L1BlobBaseFeeScalar = (*uint64)(dec.L1BlobBaseFeeScalar)
+	}
+	if dec.ValidatorReward != nil {
+		r.ValidatorReward = (*big.Int)(dec.ValidatorReward)
+	}
+	if dec.ProtocolFee != nil {
+		r.ProtocolFee = (*big.Int)(dec.ProtocolFee)
+	}
 	if dec.ReturnValue != nil {
 		r.ReturnValue = dec.ReturnValue
 	}
@@ -36,6 +36,10 @@ type BlockTrace struct {
 	StorageTrace     *StorageTrace      `json:"storageTrace"`
 	ExecutionResults []*ExecutionResult `json:"executionResults"`
 	MPTWitness       *json.RawMessage   `json:"mptwitness,omitempty"`
+
+	// Removed is set by the block trace subscription when the block is reverted
+	// by a chain reorganisation, in which case only the header is present.
+	Removed bool `json:"removed,omitempty"`
 }
 
 // StorageTrace stores proofs of storage needed by storage circuit
@@ -50,6 +54,13 @@ type StorageTrace struct {
 
 	// All storage proofs BEFORE execution
 	StorageProofs map[string]map[string][]hexutil.Bytes `json:"storageProofs,omitempty"`
+
+	// [Kroma: START]
+	// Node proofs BEFORE execution needed besides the proofs above to apply the
+	// updates of the block, which are the siblings collapsed on the deletions in
+	// the MPT. Only filled for the blocks after the KromaMPT fork.
+	DeletionProofs []hexutil.Bytes `json:"deletionProofs,omitempty"`
+	// [Kroma: END]
 }
 
 // ExecutionResult groups all structured logs emitted by the EVM
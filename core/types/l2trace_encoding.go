@@ -0,0 +1,734 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+)
+
+// blockTraceEncodingVersion is the version of the compact block trace encoding,
+// which is bumped on every incompatible change of the layout.
+const blockTraceEncodingVersion = 1
+
+var errBlockTraceEncodingVersion = errors.New("unsupported block trace encoding version")
+
+// The compact encoding of a block trace is a stream of RLP items, so that the
+// trace can be written and read piece by piece instead of as a whole:
+//
+//	head, codes, nodes, storage trace, transaction*, execution result*
+//
+// The contract codes and the proof nodes are deduplicated into the codes and
+// nodes tables, which the later items refer to by index. The stack, memory and
+// storage of the struct logs are held as raw bytes instead of hex strings.
+
+type compactBlockTraceHead struct {
+	Version      uint64
+	ChainID      uint64
+	TraceVersion string
+	Coinbase     *compactAccount `rlp:"nil"`
+	Header       *Header         `rlp:"nil"`
+	MPTWitness   []byte
+	Removed      bool
+	Transactions uint64 // number of the transactions plus one, zero if nil
+	Results      uint64 // number of the execution results plus one, zero if nil
+}
+
+type compactStorageTrace struct {
+	RootBefore     common.Hash
+	RootAfter      common.Hash
+	Proofs         []compactProof
+	StorageProofs  []compactStorageProofs
+	DeletionProofs []uint64 `rlp:"optional"`
+}
+
+type compactProof struct {
+	Key   []byte
+	Nodes []uint64
+}
+
+type compactStorageProofs struct {
+	Address []byte
+	Proofs  []compactProof
+}
+
+type compactTransaction struct {
+	Type          uint8
+	Nonce         uint64
+	TxHash        common.Hash
+	Gas           uint64
+	GasPrice      []byte
+	GasTipCap     []byte
+	GasFeeCap     []byte
+	From          common.Address
+	To            *common.Address `rlp:"nil"`
+	ChainId       []byte
+	Mint          []byte
+	Value         []byte
+	Data          []byte
+	IsCreate      bool
+	SourceHash    common.Hash
+	HasAccessList bool
+	AccessList    AccessList
+	V, R, S       []byte
+}
+
+type compactExecutionResult struct {
+	Gas            uint64
+	Failed         bool
+	ReturnValue    []byte
+	From           *compactAccount `rlp:"nil"`
+	To             *compactAccount `rlp:"nil"`
+	AccountCreated *compactAccount `rlp:"nil"`
+	AccountsAfter  []*compactAccount
+	CodeHash       *common.Hash `rlp:"nil"`
+	ByteCode       uint64       // index into the codes table plus one, zero if absent
+	StructLogs     []*compactStructLog
+}
+
+type compactStructLog struct {
+	Pc            uint64
+	Op            string
+	Gas           uint64
+	GasCost       uint64
+	Depth         uint64
+	Error         string
+	Stack         [][]byte
+	Memory        [][]byte
+	Storage       []compactSlot
+	RefundCounter uint64
+	ExtraData     *compactExtraData `rlp:"nil"`
+}
+
+type compactSlot struct {
+	Key   common.Hash
+	Value common.Hash
+}
+
+type compactExtraData struct {
+	CallFailed bool
+	CodeList   []uint64
+	StateList  []*compactAccount
+	Caller     []*compactAccount
+}
+
+type compactAccount struct {
+	Address  common.Address
+	Nonce    uint64
+	Balance  []byte
+	CodeHash common.Hash
+	Storage  *StorageWrapper `rlp:"nil"`
+}
+
+// blobTable deduplicates the blobs of a block trace, assigning each distinct
+// blob an index in the order of the first appearance.
+type blobTable struct {
+	blobs   [][]byte
+	indexes map[string]uint64
+}
+
+func newBlobTable() *blobTable {
+	return &blobTable{indexes: make(map[string]uint64)}
+}
+
+func (t *blobTable) add(blob []byte) uint64 {
+	if index, ok := t.indexes[string(blob)]; ok {
+		return index
+	}
+	index := uint64(len(t.blobs))
+	t.blobs = append(t.blobs, blob)
+	t.indexes[string(blob)] = index
+	return index
+}
+
+func lookupBlob(blobs [][]byte, index uint64) ([]byte, error) {
+	if index >= uint64(len(blobs)) {
+		return nil, fmt.Errorf("blob index %d out of range", index)
+	}
+	return blobs[index], nil
+}
+
+// EncodeBlockTrace writes the block trace to w in the compact binary encoding.
+// The transactions and the execution results are encoded one by one, so that
+// the encoded trace is never buffered as a whole.
+func EncodeBlockTrace(w io.Writer, trace *BlockTrace) error {
+	var (
+		codes = newBlobTable()
+		nodes = newBlobTable()
+	)
+	head := &compactBlockTraceHead{
+		Version:      blockTraceEncodingVersion,
+		ChainID:      trace.ChainID,
+		TraceVersion: trace.Version,
+		Coinbase:     compactAccountFrom(trace.Coinbase),
+		Header:       trace.Header,
+		Removed:      trace.Removed,
+	}
+	if trace.Transactions != nil {
+		head.Transactions = uint64(len(trace.Transactions)) + 1
+	}
+	if trace.ExecutionResults != nil {
+		head.Results = uint64(len(trace.ExecutionResults)) + 1
+	}
+	if trace.MPTWitness != nil {
+		head.MPTWitness = *trace.MPTWitness
+	}
+	storage, err := compactStorageTraceFrom(trace.StorageTrace, nodes)
+	if err != nil {
+		return err
+	}
+	// The codes are collected before anything is written, since the table has
+	// to precede the results referring to it.
+	results := make([]*compactExecutionResult, len(trace.ExecutionResults))
+	for i, result := range trace.ExecutionResults {
+		if results[i], err = compactExecutionResultFrom(result, codes); err != nil {
+			return fmt.Errorf("execution result %d: %w", i, err)
+		}
+	}
+	for _, item := range []interface{}{head, codes.blobs, nodes.blobs, storage} {
+		if err := rlp.Encode(w, item); err != nil {
+			return err
+		}
+	}
+	for i, tx := range trace.Transactions {
+		enc, err := compactTransactionFrom(tx)
+		if err != nil {
+			return fmt.Errorf("transaction %d: %w", i, err)
+		}
+		if err := rlp.Encode(w, enc); err != nil {
+			return err
+		}
+	}
+	for _, result := range results {
+		if err := rlp.Encode(w, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeBlockTrace reads a block trace in the compact binary encoding from r.
+// The decoded trace is serialised into the same JSON as the encoded one.
+func DecodeBlockTrace(r io.Reader) (*BlockTrace, error) {
+	if _, ok := r.(io.ByteReader); !ok {
+		r = bufio.NewReader(r)
+	}
+	var (
+		stream  = rlp.NewStream(r, 0)
+		head    compactBlockTraceHead
+		codes   [][]byte
+		nodes   [][]byte
+		storage *compactStorageTrace
+	)
+	if err := stream.Decode(&head); err != nil {
+		return nil, err
+	}
+	if head.Version != blockTraceEncodingVersion {
+		return nil, fmt.Errorf("%w: %d", errBlockTraceEncodingVersion, head.Version)
+	}
+	if err := stream.Decode(&codes); err != nil {
+		return nil, err
+	}
+	if err := stream.Decode(&nodes); err != nil {
+		return nil, err
+	}
+	// A nil storage trace is encoded as an empty list, which can't be decoded
+	// into the pointer directly at the top level.
+	if kind, size, err := stream.Kind(); err != nil {
+		return nil, err
+	} else if kind == rlp.List && size == 0 {
+		if _, err := stream.Raw(); err != nil {
+			return nil, err
+		}
+	} else if err := stream.Decode(&storage); err != nil {
+		return nil, err
+	}
+	trace := &BlockTrace{
+		ChainID:  head.ChainID,
+		Version:  head.TraceVersion,
+		Coinbase: head.Coinbase.wrapper(),
+		Header:   head.Header,
+		Removed:  head.Removed,
+	}
+	if head.Transactions > 0 {
+		trace.Transactions = make([]*TransactionData, head.Transactions-1)
+	}
+	if head.Results > 0 {
+		trace.ExecutionResults = make([]*ExecutionResult, head.Results-1)
+	}
+	if len(head.MPTWitness) > 0 {
+		witness := json.RawMessage(head.MPTWitness)
+		trace.MPTWitness = &witness
+	}
+	var err error
+	if trace.StorageTrace, err = storage.trace(nodes); err != nil {
+		return nil, err
+	}
+	for i := range trace.Transactions {
+		var tx compactTransaction
+		if err := stream.Decode(&tx); err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		trace.Transactions[i] = tx.data()
+	}
+	for i := range trace.ExecutionResults {
+		var result compactExecutionResult
+		if err := stream.Decode(&result); err != nil {
+			return nil, fmt.Errorf("execution result %d: %w", i, err)
+		}
+		if trace.ExecutionResults[i], err = result.result(codes); err != nil {
+			return nil, fmt.Errorf("execution result %d: %w", i, err)
+		}
+	}
+	return trace, nil
+}
+
+func compactStorageTraceFrom(trace *StorageTrace, nodes *blobTable) (*compactStorageTrace, error) {
+	if trace == nil {
+		return nil, nil
+	}
+	enc := &compactStorageTrace{
+		RootBefore: trace.RootBefore,
+		RootAfter:  trace.RootAfter,
+	}
+	var err error
+	if enc.Proofs, err = compactProofsFrom(trace.Proofs, nodes, decodeAddressKey); err != nil {
+		return nil, err
+	}
+	for _, addr := range sortedKeys(trace.StorageProofs) {
+		key, err := decodeAddressKey(addr)
+		if err != nil {
+			return nil, err
+		}
+		proofs, err := compactProofsFrom(trace.StorageProofs[addr], nodes, decodeHashKey)
+		if err != nil {
+			return nil, err
+		}
+		enc.StorageProofs = append(enc.StorageProofs, compactStorageProofs{Address: key, Proofs: proofs})
+	}
+	for _, node := range trace.DeletionProofs {
+		enc.DeletionProofs = append(enc.DeletionProofs, nodes.add(node))
+	}
+	return enc, nil
+}
+
+func compactProofsFrom(proofs map[string][]hexutil.Bytes, nodes *blobTable, decodeKey func(string) ([]byte, error)) ([]compactProof, error) {
+	enc := make([]compactProof, 0, len(proofs))
+	for _, key := range sortedKeys(proofs) {
+		raw, err := decodeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		proof := compactProof{Key: raw, Nodes: make([]uint64, len(proofs[key]))}
+		for i, node := range proofs[key] {
+			proof.Nodes[i] = nodes.add(node)
+		}
+		enc = append(enc, proof)
+	}
+	return enc, nil
+}
+
+func (enc *compactStorageTrace) trace(nodes [][]byte) (*StorageTrace, error) {
+	if enc == nil {
+		return nil, nil
+	}
+	trace := &StorageTrace{
+		RootBefore: enc.RootBefore,
+		RootAfter:  enc.RootAfter,
+		Proofs:     make(map[string][]hexutil.Bytes, len(enc.Proofs)),
+	}
+	if err := decodeProofs(trace.Proofs, enc.Proofs, nodes, encodeAddressKey); err != nil {
+		return nil, err
+	}
+	if len(enc.StorageProofs) > 0 {
+		trace.StorageProofs = make(map[string]map[string][]hexutil.Bytes, len(enc.StorageProofs))
+	}
+	for _, storage := range enc.StorageProofs {
+		proofs := make(map[string][]hexutil.Bytes, len(storage.Proofs))
+		if err := decodeProofs(proofs, storage.Proofs, nodes, encodeHashKey); err != nil {
+			return nil, err
+		}
+		trace.StorageProofs[encodeAddressKey(storage.Address)] = proofs
+	}
+	for _, index := range enc.DeletionProofs {
+		node, err := lookupBlob(nodes, index)
+		if err != nil {
+			return nil, err
+		}
+		trace.DeletionProofs = append(trace.DeletionProofs, node)
+	}
+	return trace, nil
+}
+
+func decodeProofs(dst map[string][]hexutil.Bytes, proofs []compactProof, nodes [][]byte, encodeKey func([]byte) string) error {
+	for _, proof := range proofs {
+		blobs := make([]hexutil.Bytes, len(proof.Nodes))
+		for i, index := range proof.Nodes {
+			node, err := lookupBlob(nodes, index)
+			if err != nil {
+				return err
+			}
+			blobs[i] = node
+		}
+		dst[encodeKey(proof.Key)] = blobs
+	}
+	return nil
+}
+
+func compactTransactionFrom(tx *TransactionData) (*compactTransaction, error) {
+	hash, err := decodeHashKey(tx.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	data, err := hexutil.Decode(tx.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data: %w", err)
+	}
+	enc := &compactTransaction{
+		Type:          tx.Type,
+		Nonce:         tx.Nonce,
+		TxHash:        common.BytesToHash(hash),
+		Gas:           tx.Gas,
+		From:          tx.From,
+		To:            tx.To,
+		Data:          data,
+		IsCreate:      tx.IsCreate,
+		SourceHash:    tx.SourceHash,
+		HasAccessList: tx.AccessList != nil,
+		AccessList:    tx.AccessList,
+	}
+	for _, field := range []struct {
+		dst *[]byte
+		src *hexutil.Big
+	}{
+		{&enc.GasPrice, tx.GasPrice}, {&enc.GasTipCap, tx.GasTipCap}, {&enc.GasFeeCap, tx.GasFeeCap},
+		{&enc.ChainId, tx.ChainId}, {&enc.Mint, tx.Mint}, {&enc.Value, tx.Value},
+		{&enc.V, tx.V}, {&enc.R, tx.R}, {&enc.S, tx.S},
+	} {
+		if *field.dst, err = encodeBig(field.src); err != nil {
+			return nil, err
+		}
+	}
+	return enc, nil
+}
+
+func (enc *compactTransaction) data() *TransactionData {
+	tx := &TransactionData{
+		Type:       enc.Type,
+		Nonce:      enc.Nonce,
+		TxHash:     enc.TxHash.String(),
+		Gas:        enc.Gas,
+		GasPrice:   decodeBig(enc.GasPrice),
+		GasTipCap:  decodeBig(enc.GasTipCap),
+		GasFeeCap:  decodeBig(enc.GasFeeCap),
+		From:       enc.From,
+		To:         enc.To,
+		ChainId:    decodeBig(enc.ChainId),
+		Mint:       decodeBig(enc.Mint),
+		Value:      decodeBig(enc.Value),
+		Data:       hexutil.Encode(enc.Data),
+		IsCreate:   enc.IsCreate,
+		SourceHash: enc.SourceHash,
+		V:          decodeBig(enc.V),
+		R:          decodeBig(enc.R),
+		S:          decodeBig(enc.S),
+	}
+	if enc.HasAccessList {
+		tx.AccessList = enc.AccessList
+		if tx.AccessList == nil {
+			tx.AccessList = AccessList{}
+		}
+	}
+	return tx
+}
+
+func compactExecutionResultFrom(result *ExecutionResult, codes *blobTable) (*compactExecutionResult, error) {
+	ret, err := hex.DecodeString(result.ReturnValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid return value: %w", err)
+	}
+	enc := &compactExecutionResult{
+		Gas:            result.Gas,
+		Failed:         result.Failed,
+		ReturnValue:    ret,
+		From:           compactAccountFrom(result.From),
+		To:             compactAccountFrom(result.To),
+		AccountCreated: compactAccountFrom(result.AccountCreated),
+		AccountsAfter:  compactAccountsFrom(result.AccountsAfter),
+		CodeHash:       result.CodeHash,
+		StructLogs:     make([]*compactStructLog, len(result.StructLogs)),
+	}
+	if result.ByteCode != "" {
+		code, err := hexutil.Decode(result.ByteCode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid byte code: %w", err)
+		}
+		enc.ByteCode = codes.add(code) + 1
+	}
+	for i, log := range result.StructLogs {
+		if enc.StructLogs[i], err = compactStructLogFrom(log, codes); err != nil {
+			return nil, fmt.Errorf("struct log %d: %w", i, err)
+		}
+	}
+	return enc, nil
+}
+
+func (enc *compactExecutionResult) result(codes [][]byte) (*ExecutionResult, error) {
+	result := &ExecutionResult{
+		Gas:            enc.Gas,
+		Failed:         enc.Failed,
+		ReturnValue:    hex.EncodeToString(enc.ReturnValue),
+		From:           enc.From.wrapper(),
+		To:             enc.To.wrapper(),
+		AccountCreated: enc.AccountCreated.wrapper(),
+		AccountsAfter:  accountWrappers(enc.AccountsAfter),
+		CodeHash:       enc.CodeHash,
+		StructLogs:     make([]*StructLogRes, len(enc.StructLogs)),
+	}
+	if enc.ByteCode != 0 {
+		code, err := lookupBlob(codes, enc.ByteCode-1)
+		if err != nil {
+			return nil, err
+		}
+		result.ByteCode = hexutil.Encode(code)
+	}
+	for i, log := range enc.StructLogs {
+		var err error
+		if result.StructLogs[i], err = log.log(codes); err != nil {
+			return nil, fmt.Errorf("struct log %d: %w", i, err)
+		}
+	}
+	return result, nil
+}
+
+func compactStructLogFrom(log *StructLogRes, codes *blobTable) (*compactStructLog, error) {
+	if log.Depth < 0 {
+		return nil, fmt.Errorf("invalid depth %d", log.Depth)
+	}
+	enc := &compactStructLog{
+		Pc:            log.Pc,
+		Op:            log.Op,
+		Gas:           log.Gas,
+		GasCost:       log.GasCost,
+		Depth:         uint64(log.Depth),
+		Error:         log.Error,
+		Stack:         make([][]byte, len(log.Stack)),
+		Memory:        make([][]byte, len(log.Memory)),
+		RefundCounter: log.RefundCounter,
+	}
+	// The stack items are formatted without leading zeros, they are parsed
+	// strictly to make sure the same string is reproduced.
+	for i, item := range log.Stack {
+		value, err := uint256.FromHex(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stack item %q: %w", item, err)
+		}
+		enc.Stack[i] = value.Bytes()
+	}
+	for i, word := range log.Memory {
+		chunk, err := hex.DecodeString(word)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory word %q: %w", word, err)
+		}
+		enc.Memory[i] = chunk
+	}
+	for _, key := range sortedKeys(log.Storage) {
+		k, err := decodeHashKey(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := decodeHashKey(log.Storage[key])
+		if err != nil {
+			return nil, err
+		}
+		enc.Storage = append(enc.Storage, compactSlot{Key: common.BytesToHash(k), Value: common.BytesToHash(v)})
+	}
+	if extra := log.ExtraData; extra != nil {
+		enc.ExtraData = &compactExtraData{
+			CallFailed: extra.CallFailed,
+			CodeList:   make([]uint64, len(extra.CodeList)),
+			StateList:  compactAccountsFrom(extra.StateList),
+			Caller:     compactAccountsFrom(extra.Caller),
+		}
+		for i, code := range extra.CodeList {
+			blob, err := hexutil.Decode(code)
+			if err != nil {
+				return nil, fmt.Errorf("invalid code: %w", err)
+			}
+			enc.ExtraData.CodeList[i] = codes.add(blob)
+		}
+	}
+	return enc, nil
+}
+
+func (enc *compactStructLog) log(codes [][]byte) (*StructLogRes, error) {
+	log := &StructLogRes{
+		Pc:            enc.Pc,
+		Op:            enc.Op,
+		Gas:           enc.Gas,
+		GasCost:       enc.GasCost,
+		Depth:         int(enc.Depth),
+		Error:         enc.Error,
+		Stack:         make([]string, len(enc.Stack)),
+		Memory:        make([]string, len(enc.Memory)),
+		RefundCounter: enc.RefundCounter,
+	}
+	for i, item := range enc.Stack {
+		log.Stack[i] = new(uint256.Int).SetBytes(item).Hex()
+	}
+	for i, chunk := range enc.Memory {
+		log.Memory[i] = common.Bytes2Hex(chunk)
+	}
+	if len(enc.Storage) > 0 {
+		log.Storage = make(map[string]string, len(enc.Storage))
+		for _, slot := range enc.Storage {
+			log.Storage[slot.Key.Hex()] = slot.Value.Hex()
+		}
+	}
+	if extra := enc.ExtraData; extra != nil {
+		log.ExtraData = &ExtraData{
+			CallFailed: extra.CallFailed,
+			StateList:  accountWrappers(extra.StateList),
+			Caller:     accountWrappers(extra.Caller),
+		}
+		for _, index := range extra.CodeList {
+			code, err := lookupBlob(codes, index)
+			if err != nil {
+				return nil, err
+			}
+			log.ExtraData.CodeList = append(log.ExtraData.CodeList, hexutil.Encode(code))
+		}
+	}
+	return log, nil
+}
+
+func compactAccountFrom(account *AccountWrapper) *compactAccount {
+	if account == nil {
+		return nil
+	}
+	// The balances are never negative, the error can be safely ignored.
+	balance, _ := encodeBig(account.Balance)
+	return &compactAccount{
+		Address:  account.Address,
+		Nonce:    account.Nonce,
+		Balance:  balance,
+		CodeHash: account.CodeHash,
+		Storage:  account.Storage,
+	}
+}
+
+func compactAccountsFrom(accounts []*AccountWrapper) []*compactAccount {
+	if accounts == nil {
+		return nil
+	}
+	enc := make([]*compactAccount, len(accounts))
+	for i, account := range accounts {
+		enc[i] = compactAccountFrom(account)
+	}
+	return enc
+}
+
+func (enc *compactAccount) wrapper() *AccountWrapper {
+	if enc == nil {
+		return nil
+	}
+	return &AccountWrapper{
+		Address:  enc.Address,
+		Nonce:    enc.Nonce,
+		Balance:  decodeBig(enc.Balance),
+		CodeHash: enc.CodeHash,
+		Storage:  enc.Storage,
+	}
+}
+
+func accountWrappers(enc []*compactAccount) []*AccountWrapper {
+	if len(enc) == 0 {
+		return nil
+	}
+	accounts := make([]*AccountWrapper, len(enc))
+	for i, account := range enc {
+		accounts[i] = account.wrapper()
+	}
+	return accounts
+}
+
+// encodeBig encodes an optional big integer, prefixing the non-nil ones with a
+// marker byte to tell the zero value apart from nil.
+func encodeBig(v *hexutil.Big) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if v.ToInt().Sign() < 0 {
+		return nil, fmt.Errorf("negative integer %v", v)
+	}
+	return append([]byte{1}, v.ToInt().Bytes()...), nil
+}
+
+func decodeBig(enc []byte) *hexutil.Big {
+	if len(enc) == 0 {
+		return nil
+	}
+	return (*hexutil.Big)(new(big.Int).SetBytes(enc[1:]))
+}
+
+// decodeAddressKey parses an address formatted by common.Address.String, making
+// sure the same string is reproduced from the raw bytes.
+func decodeAddressKey(key string) ([]byte, error) {
+	addr := common.HexToAddress(key)
+	if addr.String() != key {
+		return nil, fmt.Errorf("non-canonical address %q", key)
+	}
+	return addr.Bytes(), nil
+}
+
+func encodeAddressKey(key []byte) string {
+	return common.BytesToAddress(key).String()
+}
+
+// decodeHashKey parses a hash formatted by common.Hash.String, making sure the
+// same string is reproduced from the raw bytes.
+func decodeHashKey(key string) ([]byte, error) {
+	hash := common.HexToHash(key)
+	if hash.String() != key {
+		return nil, fmt.Errorf("non-canonical hash %q", key)
+	}
+	return hash.Bytes(), nil
+}
+
+func encodeHashKey(key []byte) string {
+	return common.BytesToHash(key).String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestBlockTraceEncoding(t *testing.T) {
+	var (
+		code    = "0x6001600055"
+		witness = json.RawMessage(`{"witness":true}`)
+		node    = hexutil.Bytes{0x01, 0x02}
+		account = &AccountWrapper{
+			Address: common.HexToAddress("0xaaaa"),
+			Balance: (*hexutil.Big)(new(big.Int)),
+			Storage: &StorageWrapper{Key: common.Hash{1}.String(), Value: common.Hash{2}.String()},
+		}
+	)
+	tests := map[string]*BlockTrace{
+		"removed": {
+			ChainID: 1,
+			Header:  &Header{Number: big.NewInt(1), Difficulty: common.Big0},
+			Removed: true,
+		},
+		"full": {
+			ChainID:  1,
+			Version:  "1.0",
+			Coinbase: &AccountWrapper{Address: common.HexToAddress("0xcccc")},
+			Header:   &Header{Number: big.NewInt(1), Difficulty: common.Big0, BaseFee: common.Big1},
+			Transactions: []*TransactionData{
+				{
+					TxHash:   common.Hash{3}.String(),
+					GasPrice: (*hexutil.Big)(new(big.Int)),
+					Mint:     nil,
+					Value:    (*hexutil.Big)(big.NewInt(1000)),
+					Data:     "0x",
+				},
+				{
+					Type:       DynamicFeeTxType,
+					TxHash:     common.Hash{4}.String(),
+					To:         &common.Address{},
+					Data:       "0x1234",
+					AccessList: AccessList{},
+				},
+			},
+			StorageTrace: &StorageTrace{
+				RootBefore: common.Hash{5},
+				Proofs: map[string][]hexutil.Bytes{
+					account.Address.String():  {node, {0x03}},
+					common.Address{}.String(): {node},
+				},
+				StorageProofs: map[string]map[string][]hexutil.Bytes{
+					account.Address.String(): {common.Hash{1}.String(): {node}},
+				},
+				DeletionProofs: []hexutil.Bytes{node, {0x04}},
+			},
+			ExecutionResults: []*ExecutionResult{{
+				Gas:           21000,
+				ReturnValue:   "2a",
+				From:          account,
+				AccountsAfter: []*AccountWrapper{account},
+				CodeHash:      &common.Hash{6},
+				ByteCode:      code,
+				StructLogs: []*StructLogRes{
+					{Pc: 0, Op: "PUSH1", Gas: 100, GasCost: 3, Depth: 1},
+					{
+						Pc:      2,
+						Op:      "SSTORE",
+						Depth:   1,
+						Error:   "out of gas",
+						Stack:   []string{"0x0", "0x1"},
+						Memory:  []string{common.Bytes2Hex(common.Hash{7}.Bytes())},
+						Storage: map[string]string{common.Hash{}.Hex(): common.Hash{1}.Hex()},
+						ExtraData: &ExtraData{
+							CallFailed: true,
+							CodeList:   []string{code, "0x"},
+							StateList:  []*AccountWrapper{account},
+						},
+					},
+				},
+			}},
+			MPTWitness: &witness,
+		},
+	}
+	for name, trace := range tests {
+		want, err := json.Marshal(trace)
+		if err != nil {
+			t.Fatalf("%s: failed to encode json: %v", name, err)
+		}
+		var buf bytes.Buffer
+		if err := EncodeBlockTrace(&buf, trace); err != nil {
+			t.Fatalf("%s: failed to encode trace: %v", name, err)
+		}
+		decoded, err := DecodeBlockTrace(&buf)
+		if err != nil {
+			t.Fatalf("%s: failed to decode trace: %v", name, err)
+		}
+		if have, _ := json.Marshal(decoded); !bytes.Equal(have, want) {
+			t.Fatalf("%s: trace mismatch\nhave %s\nwant %s", name, have, want)
+		}
+	}
+}
+
+func TestBlockTraceEncodingNonCanonical(t *testing.T) {
+	tests := map[string]*BlockTrace{
+		"stack": {ExecutionResults: []*ExecutionResult{{StructLogs: []*StructLogRes{{Stack: []string{"0x01"}}}}}},
+		"proof": {StorageTrace: &StorageTrace{Proofs: map[string][]hexutil.Bytes{"0xaaaa": nil}}},
+		"hash":  {Transactions: []*TransactionData{{TxHash: "0x01", Data: "0x"}}},
+	}
+	for name, trace := range tests {
+		if err := EncodeBlockTrace(new(bytes.Buffer), trace); err == nil {
+			t.Errorf("%s: expected failure encoding non-canonical trace", name)
+		}
+	}
+}
@@ -90,6 +90,16 @@ type Receipt struct {
 	L1Fee      *big.Int   `json:"l1Fee,omitempty"`
 	FeeScalar  *big.Float `json:"l1FeeScalar,omitempty"` // always nil after Ecotone hardfork
 
+	L1BlobBaseFee       *big.Int `json:"l1BlobBaseFee,omitempty"`       // always nil prior to the Ecotone hardfork
+	L1BaseFeeScalar     *uint64  `json:"l1BaseFeeScalar,omitempty"`     // always nil prior to the Ecotone hardfork
+	L1BlobBaseFeeScalar *uint64  `json:"l1BlobBaseFeeScalar,omitempty"` // always nil prior to the Ecotone hardfork
+
+	// [Kroma: START]
+	// The distribution of the transaction fee between the validators and the protocol.
+	ValidatorReward *big.Int `json:"validatorReward,omitempty"` // always nil after the Kroma MPT hardfork
+	ProtocolFee     *big.Int `json:"protocolFee,omitempty"`     // always nil after the Kroma MPT hardfork
+	// [Kroma: END]
+
 	// [Scroll: START]
 	// The value of evm execution result.
 	ReturnValue []byte `json:"returnValue,omitempty"`
@@ -113,8 +123,13 @@ type receiptMarshaling struct {
 	L1GasUsed             *hexutil.Big
 	L1Fee                 *hexutil.Big
 	FeeScalar             *big.Float
+	L1BlobBaseFee         *hexutil.Big
+	L1BaseFeeScalar       *hexutil.Uint64
+	L1BlobBaseFeeScalar   *hexutil.Uint64
 	DepositNonce          *hexutil.Uint64
 	DepositReceiptVersion *hexutil.Uint64
+	ValidatorReward       *hexutil.Big
+	ProtocolFee           *hexutil.Big
 }
 
 // receiptRLP is the consensus encoding of a receipt.
@@ -502,7 +517,7 @@ func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, nu
 		}
 	}
 	if config.Kroma != nil && len(txs) >= 2 && config.IsBedrock(new(big.Int).SetUint64(number)) { // need at least an info tx and a non-info tx
-		l1BaseFee, costFunc, feeScalar, err := extractL1GasParams(config, time, txs[0].Data())
+		gasParams, err := extractL1GasParams(config, time, txs[0].Data())
 		if err != nil {
 			return err
 		}
@@ -510,9 +525,19 @@ func (rs Receipts) DeriveFields(config *params.ChainConfig, hash common.Hash, nu
 			if txs[i].IsDepositTx() {
 				continue
 			}
-			rs[i].L1GasPrice = l1BaseFee
-			rs[i].L1Fee, rs[i].L1GasUsed = costFunc(txs[i].RollupCostData())
-			rs[i].FeeScalar = feeScalar
+			rs[i].L1GasPrice = gasParams.l1BaseFee
+			rs[i].L1BlobBaseFee = gasParams.l1BlobBaseFee
+			rs[i].L1Fee, rs[i].L1GasUsed = gasParams.costFunc(txs[i].RollupCostData())
+			rs[i].FeeScalar = gasParams.feeScalar
+			rs[i].L1BaseFeeScalar = gasParams.l1BaseFeeScalar
+			rs[i].L1BlobBaseFeeScalar = gasParams.l1BlobBaseFeeScalar
+			// [Kroma: START]
+			if gasParams.validatorRewardScalar != nil && config.IsPreKromaMPT(time) {
+				fee := new(big.Int).Mul(new(big.Int).SetUint64(rs[i].GasUsed), rs[i].EffectiveGasPrice)
+				feeDist := newFeeDistribution(fee, *gasParams.validatorRewardScalar)
+				rs[i].ValidatorReward, rs[i].ProtocolFee = feeDist.Reward, feeDist.Protocol
+			}
+			// [Kroma: END]
 		}
 	}
 	return nil
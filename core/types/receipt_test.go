@@ -48,6 +48,12 @@ var (
 		conf.EcotoneTime = &time
 		return &conf
 	}()
+	fjordTestConfig = func() *params.ChainConfig {
+		conf := *ecotoneTestConfig // copy the config
+		time := uint64(0)
+		conf.FjordTime = &time
+		return &conf
+	}()
 
 	legacyReceipt = &Receipt{
 		Status:            ReceiptStatusFailed,
@@ -778,6 +784,8 @@ func TestDeriveOptimismBedrockTxReceipts(t *testing.T) {
 	feeScalar := big.NewFloat(float64(scalar.Uint64() / 1e6))
 	l1Fee := bedrockFee
 	txs, receipts := getOptimismTxReceipts(t, payload, l1GasPrice, l1GasUsed, feeScalar, l1Fee)
+	// emptyTx pays no fee, so there is nothing to distribute
+	receipts[1].ValidatorReward, receipts[1].ProtocolFee = new(big.Int), new(big.Int)
 
 	// Re-derive receipts.
 	baseFee := big.NewInt(1000)
@@ -805,6 +813,9 @@ func TestDeriveOptimismEcotoneTxReceipts(t *testing.T) {
 	l1GasUsed := ecotoneGas
 	l1Fee := ecotoneFee
 	txs, receipts := getOptimismTxReceipts(t, payload, l1GasPrice, l1GasUsed, nil /*feeScalar*/, l1Fee)
+	// emptyTx pays no fee, so there is nothing to distribute
+	receipts[1].ValidatorReward, receipts[1].ProtocolFee = new(big.Int), new(big.Int)
+	setEcotoneReceiptFields(receipts[1], blobBaseFee, baseFeeScalar, blobBaseFeeScalar)
 
 	// Re-derive receipts.
 	baseFee := big.NewInt(1000)
@@ -822,6 +833,32 @@ func TestDeriveOptimismEcotoneTxReceipts(t *testing.T) {
 	diffReceipts(t, receipts, derivedReceipts)
 }
 
+func TestDeriveOptimismFjordTxReceipts(t *testing.T) {
+	// Fjord keeps the Ecotone style l1 attributes, see TestDeriveOptimismEcotoneTxReceipts
+	payload := common.Hex2Bytes("440a5e20000000020000000300000000000004d200000000000004d200000000000004d2000000000000000000000000000000000000000000000000000000003b9aca00000000000000000000000000000000000000000000000000000000000098968000000000000000000000000000000000000000000000000000000000000004d200000000000000000000000000000000000000000000000000000000000004d20000000000000000000000000000000000000000000000000000000000000000")
+	txs, receipts := getOptimismTxReceipts(t, payload, baseFee, fjordGas, nil /*feeScalar*/, fjordFee)
+	// emptyTx pays no fee, so there is nothing to distribute
+	receipts[1].ValidatorReward, receipts[1].ProtocolFee = new(big.Int), new(big.Int)
+	setEcotoneReceiptFields(receipts[1], blobBaseFee, baseFeeScalar, blobBaseFeeScalar)
+
+	// Re-derive receipts.
+	baseFee := big.NewInt(1000)
+	derivedReceipts := clearComputedFieldsOnReceipts(receipts)
+	err := Receipts(derivedReceipts).DeriveFields(fjordTestConfig, blockHash, blockNumber.Uint64(), 0, baseFee, nil, txs)
+	if err != nil {
+		t.Fatalf("DeriveFields(...) = %v, want <nil>", err)
+	}
+	diffReceipts(t, receipts, derivedReceipts)
+}
+
+// setEcotoneReceiptFields sets the L1 cost fields of the receipt introduced with Ecotone.
+func setEcotoneReceiptFields(receipt *Receipt, l1BlobBaseFee, l1BaseFeeScalar, l1BlobBaseFeeScalar *big.Int) {
+	baseFeeScalar, blobBaseFeeScalar := l1BaseFeeScalar.Uint64(), l1BlobBaseFeeScalar.Uint64()
+	receipt.L1BlobBaseFee = l1BlobBaseFee
+	receipt.L1BaseFeeScalar = &baseFeeScalar
+	receipt.L1BlobBaseFeeScalar = &blobBaseFeeScalar
+}
+
 func diffReceipts(t *testing.T, receipts, derivedReceipts []*Receipt) {
 	// Check diff of receipts against derivedReceipts.
 	r1, err := json.MarshalIndent(receipts, "", "  ")
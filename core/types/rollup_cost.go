@@ -75,8 +75,20 @@ var (
 	KromaL1BlobBaseFeeSlot = common.BigToHash(big.NewInt(8))
 	// [Kroma: END]
 
+	// L1CostIntercept and L1CostFastlzCoef are the parameters of the linear regression
+	// estimating the size of a transaction in a compressed batch from its FastLZ size,
+	// since the Fjord upgrade. Both are scaled by 1e6.
+	L1CostIntercept  = big.NewInt(-42_585_600)
+	L1CostFastlzCoef = big.NewInt(836_500)
+
+	// MinTransactionSize is the lower bound of the estimated size of a transaction
+	// since the Fjord upgrade.
+	MinTransactionSize       = big.NewInt(100)
+	MinTransactionSizeScaled = new(big.Int).Mul(MinTransactionSize, big.NewInt(1e6))
+
 	oneMillion     = big.NewInt(1_000_000)
 	ecotoneDivisor = big.NewInt(1_000_000 * 16)
+	fjordDivisor   = big.NewInt(1_000_000_000_000)
 	sixteen        = big.NewInt(16)
 
 	emptyScalars = make([]byte, 8)
@@ -86,6 +98,7 @@ var (
 // availablility costs for the transaction.
 type RollupCostData struct {
 	zeroes, ones uint64
+	fastLzSize   uint64
 }
 
 type StateGetter interface {
@@ -108,6 +121,7 @@ func NewRollupCostData(data []byte) (out RollupCostData) {
 			out.ones++
 		}
 	}
+	out.fastLzSize = uint64(FlzCompressLen(data))
 	return out
 }
 
@@ -133,46 +147,100 @@ func NewL1CostFunc(config *params.ChainConfig, statedb StateGetter) L1CostFunc {
 			// Note: the various state variables below are not initialized from the DB until this
 			// point to allow deposit transactions from the block to be processed first by state
 			// transition.  This behavior is consensus critical!
-			if !config.IsOptimismEcotone(blockTime) {
-				cachedFunc = newL1CostFuncBedrock(config, statedb, blockTime)
-			} else {
-				// [Kroma: START]
-				l1BlockAddr := KromaL1BlockAddr
-				l1BlobBaseFeeSlot := KromaL1BlobBaseFeeSlot
-				if config.IsKromaMPT(blockTime) {
-					l1BlockAddr = L1BlockAddr
-					l1BlobBaseFeeSlot = L1BlobBaseFeeSlot
-				}
-				// [Kroma: END]
-
-				l1BlobBaseFee := statedb.GetState(l1BlockAddr, l1BlobBaseFeeSlot).Big()
-				l1FeeScalars := statedb.GetState(l1BlockAddr, L1FeeScalarsSlot).Bytes()
-
-				// Edge case: the very first Ecotone block requires we use the Bedrock cost
-				// function. We detect this scenario by checking if the Ecotone parameters are
-				// unset.  Not here we rely on assumption that the scalar parameters are adjacent
-				// in the buffer and basefeeScalar comes first.
-				if l1BlobBaseFee.BitLen() == 0 &&
-					bytes.Equal(emptyScalars, l1FeeScalars[scalarSectionStart:scalarSectionStart+8]) {
-					log.Info("using bedrock l1 cost func for first Ecotone block", "time", blockTime)
-					cachedFunc = newL1CostFuncBedrock(config, statedb, blockTime)
-				} else {
-					l1BaseFee := statedb.GetState(l1BlockAddr, L1BaseFeeSlot).Big()
-					offset := scalarSectionStart
-					l1BaseFeeScalar := new(big.Int).SetBytes(l1FeeScalars[offset : offset+4])
-					l1BlobBaseFeeScalar := new(big.Int).SetBytes(l1FeeScalars[offset+4 : offset+8])
-					cachedFunc = newL1CostFuncEcotone(l1BaseFee, l1BlobBaseFee, l1BaseFeeScalar, l1BlobBaseFeeScalar)
-				}
-			}
+			cachedFunc = l1GasParamsFromState(config, statedb, blockTime).costFunc
 		}
 		fee, _ := cachedFunc(rollupCostData)
 		return fee
 	}
 }
 
-// newL1CostFuncBedrock returns an L1 cost function suitable for Bedrock, Regolith, and the first
-// block only of the Ecotone upgrade.
-func newL1CostFuncBedrock(config *params.ChainConfig, statedb StateGetter, blockTime uint64) l1CostFunc {
+// [Kroma: START]
+
+// L1CostEstimate is the data availability fee of a transaction, along with the L1 gas
+// parameters it is computed with.
+type L1CostEstimate struct {
+	Fee                 *big.Int
+	GasUsed             *big.Int
+	L1BaseFee           *big.Int
+	L1BlobBaseFee       *big.Int   // nil prior to Ecotone
+	FeeScalar           *big.Float // nil after Ecotone
+	L1BaseFeeScalar     *uint64    // nil prior to Ecotone
+	L1BlobBaseFeeScalar *uint64    // nil prior to Ecotone
+}
+
+// EstimateL1Cost computes the data availability fee of a transaction included in a block at the
+// given time, from the L1 gas parameters in the L1Block contract state. It returns nil if this
+// is not an op-stack chain or if there is no rollup cost-data.
+func EstimateL1Cost(config *params.ChainConfig, statedb StateGetter, blockTime uint64, rollupCostData RollupCostData) *L1CostEstimate {
+	if config.Kroma == nil || rollupCostData == (RollupCostData{}) {
+		return nil
+	}
+	gasParams := l1GasParamsFromState(config, statedb, blockTime)
+	fee, gasUsed := gasParams.costFunc(rollupCostData)
+	return &L1CostEstimate{
+		Fee:                 fee,
+		GasUsed:             gasUsed,
+		L1BaseFee:           gasParams.l1BaseFee,
+		L1BlobBaseFee:       gasParams.l1BlobBaseFee,
+		FeeScalar:           gasParams.feeScalar,
+		L1BaseFeeScalar:     gasParams.l1BaseFeeScalar,
+		L1BlobBaseFeeScalar: gasParams.l1BlobBaseFeeScalar,
+	}
+}
+
+// [Kroma: END]
+
+// l1GasParamsFromState reads the L1 gas parameters from the L1Block contract state and
+// returns them along with the l1 cost function to use for the block at the given time.
+func l1GasParamsFromState(config *params.ChainConfig, statedb StateGetter, blockTime uint64) gasParams {
+	if !config.IsOptimismEcotone(blockTime) {
+		return bedrockGasParamsFromState(config, statedb, blockTime)
+	}
+	// [Kroma: START]
+	l1BlockAddr := KromaL1BlockAddr
+	l1BlobBaseFeeSlot := KromaL1BlobBaseFeeSlot
+	if config.IsKromaMPT(blockTime) {
+		l1BlockAddr = L1BlockAddr
+		l1BlobBaseFeeSlot = L1BlobBaseFeeSlot
+	}
+	// [Kroma: END]
+
+	l1BlobBaseFee := statedb.GetState(l1BlockAddr, l1BlobBaseFeeSlot).Big()
+	l1FeeScalars := statedb.GetState(l1BlockAddr, L1FeeScalarsSlot).Bytes()
+
+	// Edge case: the very first Ecotone block requires we use the Bedrock cost
+	// function. We detect this scenario by checking if the Ecotone parameters are
+	// unset.  Not here we rely on assumption that the scalar parameters are adjacent
+	// in the buffer and basefeeScalar comes first.
+	if l1BlobBaseFee.BitLen() == 0 &&
+		bytes.Equal(emptyScalars, l1FeeScalars[scalarSectionStart:scalarSectionStart+8]) {
+		log.Info("using bedrock l1 cost func for first Ecotone block", "time", blockTime)
+		return bedrockGasParamsFromState(config, statedb, blockTime)
+	}
+	l1BaseFee := statedb.GetState(l1BlockAddr, L1BaseFeeSlot).Big()
+	offset := scalarSectionStart
+	l1BaseFeeScalar := new(big.Int).SetBytes(l1FeeScalars[offset : offset+4])
+	l1BlobBaseFeeScalar := new(big.Int).SetBytes(l1FeeScalars[offset+4 : offset+8])
+
+	var costFunc l1CostFunc
+	if config.IsOptimismFjord(blockTime) {
+		costFunc = newL1CostFuncFjord(l1BaseFee, l1BlobBaseFee, l1BaseFeeScalar, l1BlobBaseFeeScalar)
+	} else {
+		costFunc = newL1CostFuncEcotone(l1BaseFee, l1BlobBaseFee, l1BaseFeeScalar, l1BlobBaseFeeScalar)
+	}
+	baseFeeScalar, blobBaseFeeScalar := l1BaseFeeScalar.Uint64(), l1BlobBaseFeeScalar.Uint64()
+	return gasParams{
+		l1BaseFee:           l1BaseFee,
+		l1BlobBaseFee:       l1BlobBaseFee,
+		costFunc:            costFunc,
+		l1BaseFeeScalar:     &baseFeeScalar,
+		l1BlobBaseFeeScalar: &blobBaseFeeScalar,
+	}
+}
+
+// bedrockGasParamsFromState returns the L1 gas parameters and cost function suitable for Bedrock,
+// Regolith, and the first block only of the Ecotone upgrade.
+func bedrockGasParamsFromState(config *params.ChainConfig, statedb StateGetter, blockTime uint64) gasParams {
 	// [Kroma: START]
 	l1BlockAddr := KromaL1BlockAddr
 	if config.IsKromaMPT(blockTime) {
@@ -184,7 +252,11 @@ func newL1CostFuncBedrock(config *params.ChainConfig, statedb StateGetter, block
 	overhead := statedb.GetState(l1BlockAddr, OverheadSlot).Big()
 	scalar := statedb.GetState(l1BlockAddr, ScalarSlot).Big()
 	isRegolith := config.IsRegolith(blockTime)
-	return newL1CostFuncBedrockHelper(l1BaseFee, overhead, scalar, isRegolith)
+	return gasParams{
+		l1BaseFee: l1BaseFee,
+		costFunc:  newL1CostFuncBedrockHelper(l1BaseFee, overhead, scalar, isRegolith),
+		feeScalar: new(big.Float).Quo(new(big.Float).SetInt(scalar), new(big.Float).SetUint64(1_000_000)),
+	}
 }
 
 // newL1CostFuncBedrockHelper is lower level version of newL1CostFuncBedrock that expects already
@@ -240,44 +312,100 @@ func newL1CostFuncEcotone(l1BaseFee, l1BlobBaseFee, l1BaseFeeScalar, l1BlobBaseF
 	}
 }
 
+// newL1CostFuncFjord returns an l1 cost function suitable for the Fjord upgrade, which
+// estimates the compressed size of a transaction from its FastLZ-compressed size.
+func newL1CostFuncFjord(l1BaseFee, l1BlobBaseFee, l1BaseFeeScalar, l1BlobBaseFeeScalar *big.Int) l1CostFunc {
+	// Fjord L1 cost function:
+	//
+	//   l1FeeScaled = l1BaseFeeScalar*l1BaseFee*16 + l1BlobBaseFeeScalar*l1BlobBaseFee
+	//   estimatedSize = max(minTransactionSize, intercept + fastlzCoef*fastlzSize)
+	//   l1Cost = estimatedSize * l1FeeScaled / 1e12
+	//
+	// The estimated size is scaled by 1e6, as the regression parameters are.
+	calldataCostPerByte := new(big.Int).Mul(l1BaseFeeScalar, l1BaseFee)
+	calldataCostPerByte = calldataCostPerByte.Mul(calldataCostPerByte, sixteen)
+	blobCostPerByte := new(big.Int).Mul(l1BlobBaseFeeScalar, l1BlobBaseFee)
+	l1FeeScaled := new(big.Int).Add(calldataCostPerByte, blobCostPerByte)
+
+	return func(costData RollupCostData) (fee, calldataGasUsed *big.Int) {
+		estimatedSize := new(big.Int).SetUint64(costData.fastLzSize)
+		estimatedSize = estimatedSize.Mul(estimatedSize, L1CostFastlzCoef)
+		estimatedSize = estimatedSize.Add(estimatedSize, L1CostIntercept)
+		if estimatedSize.Cmp(MinTransactionSizeScaled) < 0 {
+			estimatedSize.Set(MinTransactionSizeScaled)
+		}
+		fee = new(big.Int).Mul(estimatedSize, l1FeeScaled)
+		fee = fee.Div(fee, fjordDivisor)
+
+		// The gas used is the calldata gas of the estimated size, as if all bytes were non-zero.
+		calldataGasUsed = new(big.Int).Mul(estimatedSize, new(big.Int).SetUint64(params.TxDataNonZeroGasEIP2028))
+		calldataGasUsed = calldataGasUsed.Div(calldataGasUsed, oneMillion)
+
+		return fee, calldataGasUsed
+	}
+}
+
+// gasParams is the set of L1 gas parameters extracted from the L1 attributes
+// transaction, which are used to compute the L1 cost fields of the receipts.
+type gasParams struct {
+	l1BaseFee           *big.Int
+	l1BlobBaseFee       *big.Int // nil prior to Ecotone
+	costFunc            l1CostFunc
+	feeScalar           *big.Float // nil after Ecotone
+	l1BaseFeeScalar     *uint64    // nil prior to Ecotone
+	l1BlobBaseFeeScalar *uint64    // nil prior to Ecotone
+
+	// [Kroma: START]
+	validatorRewardScalar *uint64 // nil after Kroma MPT
+	// [Kroma: END]
+}
+
 // extractL1GasParams extracts the gas parameters necessary to compute gas costs from L1 block info
-func extractL1GasParams(config *params.ChainConfig, time uint64, data []byte) (l1BaseFee *big.Int, costFunc l1CostFunc, feeScalar *big.Float, err error) {
+func extractL1GasParams(config *params.ChainConfig, time uint64, data []byte) (gasParams, error) {
 	if config.IsEcotone(time) {
 		// edge case: for the very first Ecotone block we still need to use the Bedrock
 		// function. We detect this edge case by seeing if the function selector is the old one
 		if len(data) >= 4 && !bytes.Equal(data[0:4], BedrockL1AttributesSelector) {
 			// [Kroma: START]
-			l1BaseFee, costFunc, err = extractL1GasParamsEcotone(data, config.IsKromaMPT(time))
+			return extractL1GasParamsPostEcotone(data, config.IsKromaMPT(time), config.IsFjord(time))
 			// [Kroma: END]
-			return
 		}
 	}
 
 	// data consists of func selector followed by 7 ABI-encoded parameters (32 bytes each)
 	if len(data) < 4+32*8 {
-		return nil, nil, nil, fmt.Errorf("expected at least %d L1 info bytes, got %d", 4+32*8, len(data))
+		return gasParams{}, fmt.Errorf("expected at least %d L1 info bytes, got %d", 4+32*8, len(data))
+	}
+	data = data[4:]                                       // trim function selector
+	l1BaseFee := new(big.Int).SetBytes(data[32*2 : 32*3]) // arg index 2
+	overhead := new(big.Int).SetBytes(data[32*6 : 32*7])  // arg index 6
+	scalar := new(big.Int).SetBytes(data[32*7 : 32*8])    // arg index 7
+	fscalar := new(big.Float).SetInt(scalar)              // legacy: format fee scalar as big Float
+	fdivisor := new(big.Float).SetUint64(1_000_000)       // 10**6, i.e. 6 decimals
+	params := gasParams{
+		l1BaseFee: l1BaseFee,
+		costFunc:  newL1CostFuncBedrockHelper(l1BaseFee, overhead, scalar, config.IsRegolith(time)),
+		feeScalar: new(big.Float).Quo(fscalar, fdivisor),
 	}
-	data = data[4:]                                      // trim function selector
-	l1BaseFee = new(big.Int).SetBytes(data[32*2 : 32*3]) // arg index 2
-	overhead := new(big.Int).SetBytes(data[32*6 : 32*7]) // arg index 6
-	scalar := new(big.Int).SetBytes(data[32*7 : 32*8])   // arg index 7
-	fscalar := new(big.Float).SetInt(scalar)             // legacy: format fee scalar as big Float
-	fdivisor := new(big.Float).SetUint64(1_000_000)      // 10**6, i.e. 6 decimals
-	feeScalar = new(big.Float).Quo(fscalar, fdivisor)
-	costFunc = newL1CostFuncBedrockHelper(l1BaseFee, overhead, scalar, config.IsRegolith(time))
-	return
+	// [Kroma: START]
+	if len(data) >= 32*9 {
+		validatorRewardScalar := new(big.Int).SetBytes(data[32*8 : 32*9]).Uint64() // arg index 8
+		params.validatorRewardScalar = &validatorRewardScalar
+	}
+	// [Kroma: END]
+	return params, nil
 }
 
-// extractEcotoneL1GasParams extracts the gas parameters necessary to compute gas from L1 attribute
+// extractL1GasParamsPostEcotone extracts the gas parameters necessary to compute gas from L1 attribute
 // info calldata after the Ecotone upgrade, but not for the very first Ecotone block.
-func extractL1GasParamsEcotone(data []byte, isKromaMPT bool) (l1BaseFee *big.Int, costFunc l1CostFunc, err error) {
+func extractL1GasParamsPostEcotone(data []byte, isKromaMPT, isFjord bool) (gasParams, error) {
 	// [Kroma: START]
 	// Since validatorRewardScalar is removed after the Kroma MPT upgrade, the calldata can be 164 bytes long.
 	// Validate the length of the L1 info bytes accordingly.
 	if len(data) != 196 && !isKromaMPT {
-		return nil, nil, fmt.Errorf("expected 196 L1 info bytes, got %d", len(data))
+		return gasParams{}, fmt.Errorf("expected 196 L1 info bytes, got %d", len(data))
 	} else if len(data) != 164 && isKromaMPT {
-		return nil, nil, fmt.Errorf("expected 164 L1 info bytes, got %d", len(data))
+		return gasParams{}, fmt.Errorf("expected 164 L1 info bytes, got %d", len(data))
 	}
 	// [Kroma: END]
 
@@ -293,12 +421,33 @@ func extractL1GasParamsEcotone(data []byte, isKromaMPT bool) (l1BaseFee *big.Int
 	// 68    uint256 _blobBaseFee,
 	// 100   bytes32 _hash,
 	// 132   bytes32 _batcherHash,
-	l1BaseFee = new(big.Int).SetBytes(data[36:68])
+	l1BaseFee := new(big.Int).SetBytes(data[36:68])
 	l1BlobBaseFee := new(big.Int).SetBytes(data[68:100])
 	l1BaseFeeScalar := new(big.Int).SetBytes(data[4:8])
 	l1BlobBaseFeeScalar := new(big.Int).SetBytes(data[8:12])
-	costFunc = newL1CostFuncEcotone(l1BaseFee, l1BlobBaseFee, l1BaseFeeScalar, l1BlobBaseFeeScalar)
-	return
+
+	var costFunc l1CostFunc
+	if isFjord {
+		costFunc = newL1CostFuncFjord(l1BaseFee, l1BlobBaseFee, l1BaseFeeScalar, l1BlobBaseFeeScalar)
+	} else {
+		costFunc = newL1CostFuncEcotone(l1BaseFee, l1BlobBaseFee, l1BaseFeeScalar, l1BlobBaseFeeScalar)
+	}
+	baseFeeScalar, blobBaseFeeScalar := l1BaseFeeScalar.Uint64(), l1BlobBaseFeeScalar.Uint64()
+	params := gasParams{
+		l1BaseFee:           l1BaseFee,
+		l1BlobBaseFee:       l1BlobBaseFee,
+		costFunc:            costFunc,
+		l1BaseFeeScalar:     &baseFeeScalar,
+		l1BlobBaseFeeScalar: &blobBaseFeeScalar,
+	}
+	// [Kroma: START]
+	// 164   uint256 _validatorRewardScalar, removed in Kroma MPT
+	if !isKromaMPT {
+		validatorRewardScalar := new(big.Int).SetBytes(data[164:196]).Uint64()
+		params.validatorRewardScalar = &validatorRewardScalar
+	}
+	// [Kroma: END]
+	return params, nil
 }
 
 // L1Cost computes the the data availability fee for transactions in blocks prior to the Ecotone
@@ -24,11 +24,13 @@ var (
 	// which is defined in transaction_test.go
 	bedrockFee  = big.NewInt(11326000000000)
 	regolithFee = big.NewInt(3710000000000)
-	ecotoneFee  = big.NewInt(960900) // (480/16)*(2*16*1000 + 3*10) == 960900
+	ecotoneFee  = big.NewInt(960900)  // (480/16)*(2*16*1000 + 3*10) == 960900
+	fjordFee    = big.NewInt(3203000) // 100_000_000*(2*16*1000e6 + 3*10e6)/1e12 == 3203000, the size of emptyTx is below the minimum
 
 	bedrockGas  = big.NewInt(1618)
 	regolithGas = big.NewInt(530) // 530  = 1618 - (16*68)
 	ecotoneGas  = big.NewInt(480)
+	fjordGas    = big.NewInt(1600) // 100 * 16
 )
 
 func TestBedrockL1CostFunc(t *testing.T) {
@@ -52,6 +54,18 @@ func TestEcotoneL1CostFunc(t *testing.T) {
 	require.Equal(t, ecotoneFee, c)
 }
 
+func TestFjordL1CostFunc(t *testing.T) {
+	costFunc := newL1CostFuncFjord(baseFee, blobBaseFee, baseFeeScalar, blobBaseFeeScalar)
+	c, g := costFunc(emptyTx.RollupCostData())
+	require.Equal(t, fjordGas, g)
+	require.Equal(t, fjordFee, c)
+
+	// The estimated size is 1000*836_500 - 42_585_600 = 793_914_400 when above the minimum.
+	c, g = costFunc(RollupCostData{ones: 1000, fastLzSize: 1000})
+	require.Equal(t, big.NewInt(12702), g)    // 793_914_400*16/1e6
+	require.Equal(t, big.NewInt(25429078), c) // 793_914_400*(2*16*1000e6 + 3*10e6)/1e12
+}
+
 func TestExtractBedrockGasParams(t *testing.T) {
 	regolithTime := uint64(1)
 	config := &params.ChainConfig{
@@ -61,7 +75,7 @@ func TestExtractBedrockGasParams(t *testing.T) {
 
 	data := getBedrockL1Attributes(baseFee, overhead, scalar)
 
-	_, costFuncPreRegolith, _, err := extractL1GasParams(config, regolithTime-1, data)
+	gasParamsPreRegolith, err := extractL1GasParams(config, regolithTime-1, data)
 	require.NoError(t, err)
 
 	// Function should continue to succeed even with extra data (that just gets ignored) since we
@@ -69,18 +83,18 @@ func TestExtractBedrockGasParams(t *testing.T) {
 	// the expected number of bytes. It's unclear if this flexibility was intentional, but since
 	// it's been in production we shouldn't change this behavior.
 	data = append(data, []byte{0xBE, 0xEE, 0xEE, 0xFF}...) // tack on garbage data
-	_, costFuncRegolith, _, err := extractL1GasParams(config, regolithTime, data)
+	gasParamsRegolith, err := extractL1GasParams(config, regolithTime, data)
 	require.NoError(t, err)
 
-	c, _ := costFuncPreRegolith(emptyTx.RollupCostData())
+	c, _ := gasParamsPreRegolith.costFunc(emptyTx.RollupCostData())
 	require.Equal(t, bedrockFee, c)
 
-	c, _ = costFuncRegolith(emptyTx.RollupCostData())
+	c, _ = gasParamsRegolith.costFunc(emptyTx.RollupCostData())
 	require.Equal(t, regolithFee, c)
 
 	// try to extract from data which has not enough params, should get error.
 	data = data[:len(data)-4-32]
-	_, _, _, err = extractL1GasParams(config, regolithTime, data)
+	_, err = extractL1GasParams(config, regolithTime, data)
 	require.Error(t, err)
 }
 
@@ -96,20 +110,44 @@ func TestExtractEcotoneGasParams(t *testing.T) {
 
 	data := getEcotoneL1Attributes(baseFee, blobBaseFee, baseFeeScalar, blobBaseFeeScalar)
 
-	_, costFunc, _, err := extractL1GasParams(config, 0, data)
+	gasParams, err := extractL1GasParams(config, 0, data)
 	require.NoError(t, err)
 
-	c, g := costFunc(emptyTx.RollupCostData())
+	c, g := gasParams.costFunc(emptyTx.RollupCostData())
 
 	require.Equal(t, ecotoneGas, g)
 	require.Equal(t, ecotoneFee, c)
 
 	// make sure wrong amont of data results in error
 	data = append(data, 0x00) // tack on garbage byte
-	_, _, err = extractL1GasParamsEcotone(data, false)
+	_, err = extractL1GasParamsPostEcotone(data, false, false)
 	require.Error(t, err)
 }
 
+func TestExtractFjordGasParams(t *testing.T) {
+	zeroTime := uint64(0)
+	// create a config where fjord upgrade is active
+	config := &params.ChainConfig{
+		Kroma:        params.KromaTestConfig.Kroma,
+		RegolithTime: &zeroTime,
+		EcotoneTime:  &zeroTime,
+		FjordTime:    &zeroTime,
+	}
+	require.True(t, config.IsOptimismFjord(0))
+
+	data := getEcotoneL1Attributes(baseFee, blobBaseFee, baseFeeScalar, blobBaseFeeScalar)
+
+	gasParams, err := extractL1GasParams(config, 0, data)
+	require.NoError(t, err)
+	require.Equal(t, blobBaseFee, gasParams.l1BlobBaseFee)
+	require.Equal(t, baseFeeScalar.Uint64(), *gasParams.l1BaseFeeScalar)
+	require.Equal(t, blobBaseFeeScalar.Uint64(), *gasParams.l1BlobBaseFeeScalar)
+
+	c, g := gasParams.costFunc(emptyTx.RollupCostData())
+	require.Equal(t, fjordGas, g)
+	require.Equal(t, fjordFee, c)
+}
+
 // [Kroma: START]
 func TestExtractKromaMPTGasParams(t *testing.T) {
 	zeroTime := uint64(0)
@@ -126,17 +164,17 @@ func TestExtractKromaMPTGasParams(t *testing.T) {
 	// Remove last 32 bytes (validatorRewardScalar).
 	data = data[:len(data)-32]
 
-	_, costFunc, _, err := extractL1GasParams(config, 0, data)
+	gasParams, err := extractL1GasParams(config, 0, data)
 	require.NoError(t, err)
 
-	c, g := costFunc(emptyTx.RollupCostData())
+	c, g := gasParams.costFunc(emptyTx.RollupCostData())
 
 	require.Equal(t, ecotoneGas, g)
 	require.Equal(t, ecotoneFee, c)
 
 	// make sure wrong amount of data results in error
 	data = append(data, 0x00) // tack on garbage byte
-	_, _, err = extractL1GasParamsEcotone(data, true)
+	_, err = extractL1GasParamsPostEcotone(data, true, false)
 	require.Error(t, err)
 }
 
@@ -156,9 +194,9 @@ func TestFirstBlockEcotoneGasParams(t *testing.T) {
 
 	data := getBedrockL1Attributes(baseFee, overhead, scalar)
 
-	_, oldCostFunc, _, err := extractL1GasParams(config, 0, data)
+	gasParams, err := extractL1GasParams(config, 0, data)
 	require.NoError(t, err)
-	c, _ := oldCostFunc(emptyTx.RollupCostData())
+	c, _ := gasParams.costFunc(emptyTx.RollupCostData())
 	require.Equal(t, regolithFee, c)
 }
 
@@ -230,6 +268,43 @@ func (sg *testStateGetter) GetState(addr common.Address, slot common.Hash) commo
 	return buf
 }
 
+func TestEstimateL1Cost(t *testing.T) {
+	time := uint64(1)
+	config := &params.ChainConfig{
+		Kroma:        params.KromaTestConfig.Kroma,
+		RegolithTime: &time,
+	}
+	statedb := &testStateGetter{
+		baseFee:           baseFee,
+		overhead:          overhead,
+		scalar:            scalar,
+		blobBaseFee:       blobBaseFee,
+		baseFeeScalar:     uint32(baseFeeScalar.Uint64()),
+		blobBaseFeeScalar: uint32(blobBaseFeeScalar.Uint64()),
+	}
+	require.Nil(t, EstimateL1Cost(config, statedb, time, RollupCostData{}))
+	require.Nil(t, EstimateL1Cost(params.TestChainConfig, statedb, time, emptyTx.RollupCostData()))
+
+	// regolith estimate carries the legacy fee scalar
+	estimate := EstimateL1Cost(config, statedb, time, emptyTx.RollupCostData())
+	require.Equal(t, regolithFee, estimate.Fee)
+	require.Equal(t, regolithGas, estimate.GasUsed)
+	require.Equal(t, baseFee, estimate.L1BaseFee)
+	require.Equal(t, "7", estimate.FeeScalar.String())
+	require.Nil(t, estimate.L1BlobBaseFee)
+	require.Nil(t, estimate.L1BaseFeeScalar)
+
+	// ecotone estimate carries the blob base fee and the new scalars
+	config.EcotoneTime = &time
+	estimate = EstimateL1Cost(config, statedb, time, emptyTx.RollupCostData())
+	require.Equal(t, ecotoneFee, estimate.Fee)
+	require.Equal(t, ecotoneGas, estimate.GasUsed)
+	require.Equal(t, blobBaseFee, estimate.L1BlobBaseFee)
+	require.Equal(t, baseFeeScalar.Uint64(), *estimate.L1BaseFeeScalar)
+	require.Equal(t, blobBaseFeeScalar.Uint64(), *estimate.L1BlobBaseFeeScalar)
+	require.Nil(t, estimate.FeeScalar)
+}
+
 // TestNewL1CostFunc tests that the appropriate cost function is selected based on the
 // configuration and statedb values.
 func TestNewL1CostFunc(t *testing.T) {
@@ -273,6 +348,13 @@ func TestNewL1CostFunc(t *testing.T) {
 	require.NotNil(t, fee)
 	require.Equal(t, ecotoneFee, fee)
 
+	// emptyTx fee w/ fjord config should be the fjord fee
+	config.FjordTime = &time
+	costFunc = NewL1CostFunc(config, statedb)
+	fee = costFunc(emptyTx.RollupCostData(), time)
+	require.NotNil(t, fee)
+	require.Equal(t, fjordFee, fee)
+
 	// emptyTx fee w/ ecotone config, but simulate first ecotone block by blowing away the ecotone
 	// params. Should result in regolith fee.
 	statedb.baseFeeScalar = 0
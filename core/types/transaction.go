@@ -64,8 +64,25 @@ type Transaction struct {
 
 	// cache of details to compute the data availability fee
 	rollupCostData atomic.Value
+
+	// [Kroma: START]
+	// conditional state of a transaction submitted via eth_sendRawTransactionConditional,
+	// which is not part of the consensus encoding. It's kept behind a pointer so
+	// that the transaction remains copyable.
+	meta *txMeta
+	// [Kroma: END]
+}
+
+// [Kroma: START]
+
+// txMeta holds the conditional of a transaction and whether it was rejected.
+type txMeta struct {
+	conditional *TransactionConditional
+	rejected    atomic.Bool // Whether the conditional was rejected by the miner
 }
 
+// [Kroma: END]
+
 // NewTx creates a new transaction.
 func NewTx(inner TxData) *Transaction {
 	tx := new(Transaction)
@@ -561,6 +578,39 @@ func (tx *Transaction) Time() time.Time {
 	return tx.time
 }
 
+// [Kroma: START]
+
+// Conditional returns the conditional of the transaction, or nil if it has none.
+func (tx *Transaction) Conditional() *TransactionConditional {
+	if tx.meta == nil {
+		return nil
+	}
+	return tx.meta.conditional
+}
+
+// SetConditional attaches the conditional to the transaction. This is used when the
+// transaction is submitted via eth_sendRawTransactionConditional, and must be done
+// before the transaction is shared.
+func (tx *Transaction) SetConditional(cond *TransactionConditional) {
+	tx.meta = &txMeta{conditional: cond}
+}
+
+// Rejected returns whether the conditional of the transaction was rejected by the miner.
+func (tx *Transaction) Rejected() bool {
+	return tx.meta != nil && tx.meta.rejected.Load()
+}
+
+// SetRejected marks the transaction as rejected for its conditional, so that it is
+// dropped from the transaction pool. The transactions without conditional can't be
+// rejected.
+func (tx *Transaction) SetRejected() {
+	if tx.meta != nil {
+		tx.meta.rejected.Store(true)
+	}
+}
+
+// [Kroma: END]
+
 // Hash returns the transaction hash.
 func (tx *Transaction) Hash() common.Hash {
 	if hash := tx.hash.Load(); hash != nil {
@@ -0,0 +1,145 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+//go:generate go run github.com/fjl/gencodec -type TransactionConditional -field-override transactionConditionalMarshaling -out gen_transaction_conditional_json.go
+
+// TransactionConditionalMaxCost is the upper bound of the cost of a conditional, which
+// is the number of state lookups needed to check it.
+const TransactionConditionalMaxCost = 1000
+
+var (
+	errConditionalBlockNumberRange = errors.New("blockNumberMin is greater than blockNumberMax")
+	errConditionalTimestampRange   = errors.New("timestampMin is greater than timestampMax")
+)
+
+// KnownAccount is the expected state of an account, either as the storage root of the
+// account or as the values of a set of storage slots.
+type KnownAccount struct {
+	StorageRoot  *common.Hash
+	StorageSlots map[common.Hash]common.Hash
+}
+
+// MarshalJSON encodes the known account as the storage root if set, or as the object
+// of the storage slots otherwise.
+func (ka KnownAccount) MarshalJSON() ([]byte, error) {
+	if ka.StorageRoot != nil {
+		return json.Marshal(*ka.StorageRoot)
+	}
+	return json.Marshal(ka.StorageSlots)
+}
+
+// UnmarshalJSON decodes the known account from either a storage root hash or an object
+// of storage slots.
+func (ka *KnownAccount) UnmarshalJSON(input []byte) error {
+	var root common.Hash
+	if err := json.Unmarshal(input, &root); err == nil {
+		ka.StorageRoot, ka.StorageSlots = &root, nil
+		return nil
+	}
+	var slots map[common.Hash]common.Hash
+	if err := json.Unmarshal(input, &slots); err != nil {
+		return errors.New("known account must be a storage root or an object of storage slots")
+	}
+	ka.StorageRoot, ka.StorageSlots = nil, slots
+	return nil
+}
+
+// KnownAccounts is the expected state of a set of accounts.
+type KnownAccounts map[common.Address]KnownAccount
+
+// TransactionConditional is the set of conditions which must hold for a transaction to
+// be included in a block. The conditional is attached to the transaction submitted via
+// eth_sendRawTransactionConditional, and is not part of its consensus encoding.
+type TransactionConditional struct {
+	KnownAccounts  KnownAccounts `json:"knownAccounts"`
+	BlockNumberMin *big.Int      `json:"blockNumberMin,omitempty"`
+	BlockNumberMax *big.Int      `json:"blockNumberMax,omitempty"`
+	TimestampMin   *uint64       `json:"timestampMin,omitempty"`
+	TimestampMax   *uint64       `json:"timestampMax,omitempty"`
+}
+
+// field type overrides for gencodec
+type transactionConditionalMarshaling struct {
+	BlockNumberMin *hexutil.Big
+	BlockNumberMax *hexutil.Big
+	TimestampMin   *hexutil.Uint64
+	TimestampMax   *hexutil.Uint64
+}
+
+// Validate checks the sanity of the block number and timestamp bounds.
+func (cond *TransactionConditional) Validate() error {
+	if cond.BlockNumberMin != nil && cond.BlockNumberMax != nil && cond.BlockNumberMin.Cmp(cond.BlockNumberMax) > 0 {
+		return errConditionalBlockNumberRange
+	}
+	if cond.TimestampMin != nil && cond.TimestampMax != nil && *cond.TimestampMin > *cond.TimestampMax {
+		return errConditionalTimestampRange
+	}
+	return nil
+}
+
+// Cost returns the number of lookups needed to check the conditional, which is used
+// to rate limit the submission of conditional transactions.
+func (cond *TransactionConditional) Cost() int {
+	cost := 0
+	for _, account := range cond.KnownAccounts {
+		cost++ // lookup of the account itself
+		if account.StorageRoot != nil {
+			cost++
+		}
+		cost += len(account.StorageSlots)
+	}
+	if cond.BlockNumberMin != nil || cond.BlockNumberMax != nil {
+		cost++
+	}
+	if cond.TimestampMin != nil || cond.TimestampMax != nil {
+		cost++
+	}
+	return cost
+}
+
+// CheckBlockNumber checks whether the block number is within the bounds of the conditional.
+func (cond *TransactionConditional) CheckBlockNumber(number *big.Int) error {
+	if cond.BlockNumberMin != nil && number.Cmp(cond.BlockNumberMin) < 0 {
+		return fmt.Errorf("block number %v is below the minimum %v", number, cond.BlockNumberMin)
+	}
+	if cond.BlockNumberMax != nil && number.Cmp(cond.BlockNumberMax) > 0 {
+		return fmt.Errorf("block number %v is above the maximum %v", number, cond.BlockNumberMax)
+	}
+	return nil
+}
+
+// CheckTimestamp checks whether the timestamp is within the bounds of the conditional.
+func (cond *TransactionConditional) CheckTimestamp(time uint64) error {
+	if cond.TimestampMin != nil && time < *cond.TimestampMin {
+		return fmt.Errorf("timestamp %d is below the minimum %d", time, *cond.TimestampMin)
+	}
+	if cond.TimestampMax != nil && time > *cond.TimestampMax {
+		return fmt.Errorf("timestamp %d is above the maximum %d", time, *cond.TimestampMax)
+	}
+	return nil
+}
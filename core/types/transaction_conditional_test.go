@@ -0,0 +1,124 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestTransactionConditionalJSON(t *testing.T) {
+	var (
+		root  = common.HexToHash("0x01")
+		min   = uint64(100)
+		tests = []struct {
+			input string
+			want  TransactionConditional
+		}{
+			{
+				input: `{"knownAccounts":{"0x0000000000000000000000000000000000000001":"0x0000000000000000000000000000000000000000000000000000000000000001"}}`,
+				want: TransactionConditional{KnownAccounts: KnownAccounts{
+					common.HexToAddress("0x01"): {StorageRoot: &root},
+				}},
+			},
+			{
+				input: `{"knownAccounts":{"0x0000000000000000000000000000000000000001":{"0x0000000000000000000000000000000000000000000000000000000000000002":"0x0000000000000000000000000000000000000000000000000000000000000003"}},"blockNumberMin":"0x1","blockNumberMax":"0x2","timestampMin":"0x64"}`,
+				want: TransactionConditional{
+					KnownAccounts: KnownAccounts{
+						common.HexToAddress("0x01"): {StorageSlots: map[common.Hash]common.Hash{
+							common.HexToHash("0x02"): common.HexToHash("0x03"),
+						}},
+					},
+					BlockNumberMin: big.NewInt(1),
+					BlockNumberMax: big.NewInt(2),
+					TimestampMin:   &min,
+				},
+			},
+		}
+	)
+	for i, tt := range tests {
+		var cond TransactionConditional
+		if err := json.Unmarshal([]byte(tt.input), &cond); err != nil {
+			t.Fatalf("test %d: failed to unmarshal: %v", i, err)
+		}
+		if !reflect.DeepEqual(cond, tt.want) {
+			t.Errorf("test %d: unmarshal mismatch: have %+v, want %+v", i, cond, tt.want)
+		}
+		output, err := json.Marshal(&cond)
+		if err != nil {
+			t.Fatalf("test %d: failed to marshal: %v", i, err)
+		}
+		if string(output) != tt.input {
+			t.Errorf("test %d: marshal mismatch: have %s, want %s", i, output, tt.input)
+		}
+	}
+	var cond TransactionConditional
+	if err := json.Unmarshal([]byte(`{"knownAccounts":{"0x0000000000000000000000000000000000000001":1}}`), &cond); err == nil {
+		t.Error("expected error for invalid known account")
+	}
+}
+
+func TestTransactionConditionalChecks(t *testing.T) {
+	var (
+		min, max = uint64(10), uint64(20)
+		root     = common.Hash{0x01}
+		cond     = TransactionConditional{
+			KnownAccounts: KnownAccounts{
+				common.Address{0x01}: {StorageRoot: &root},
+				common.Address{0x02}: {StorageSlots: map[common.Hash]common.Hash{{0x01}: {}, {0x02}: {}}},
+			},
+			BlockNumberMin: big.NewInt(10),
+			BlockNumberMax: big.NewInt(20),
+			TimestampMin:   &min,
+			TimestampMax:   &max,
+		}
+	)
+	if err := cond.Validate(); err != nil {
+		t.Errorf("unexpected validation error: %v", err)
+	}
+	if cost := cond.Cost(); cost != 7 {
+		t.Errorf("cost mismatch: have %d, want %d", cost, 7)
+	}
+	for _, number := range []int64{10, 15, 20} {
+		if err := cond.CheckBlockNumber(big.NewInt(number)); err != nil {
+			t.Errorf("block number %d: unexpected error: %v", number, err)
+		}
+		if err := cond.CheckTimestamp(uint64(number)); err != nil {
+			t.Errorf("timestamp %d: unexpected error: %v", number, err)
+		}
+	}
+	for _, number := range []int64{9, 21} {
+		if err := cond.CheckBlockNumber(big.NewInt(number)); err == nil {
+			t.Errorf("block number %d: expected error", number)
+		}
+		if err := cond.CheckTimestamp(uint64(number)); err == nil {
+			t.Errorf("timestamp %d: expected error", number)
+		}
+	}
+	cond.BlockNumberMin = big.NewInt(21)
+	if err := cond.Validate(); err != errConditionalBlockNumberRange {
+		t.Errorf("validation error mismatch: have %v, want %v", err, errConditionalBlockNumberRange)
+	}
+	cond.BlockNumberMin, cond.TimestampMin = nil, &[]uint64{21}[0]
+	if err := cond.Validate(); err != errConditionalTimestampRange {
+		t.Errorf("validation error mismatch: have %v, want %v", err, errConditionalTimestampRange)
+	}
+}
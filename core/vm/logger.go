@@ -59,6 +59,15 @@ type LogConfig struct {
 	Limit            int  // maximum length of output, but zero means unlimited
 	// Chain overrides, can be used to execute a trace using future fork rules
 	Overrides *params.ChainConfig `json:"overrides,omitempty"`
+
+	// [Kroma: START]
+	// The parts of the block traces which are skipped, these aren't exposed to
+	// the users of the debug tracers.
+	DisableStructLogs bool `json:"-"` // disable struct log capture, implies no extra data either
+	DisableCodeList   bool `json:"-"` // disable capture of the codes in the extra data
+	DisableStateList  bool `json:"-"` // disable capture of the account states in the extra data
+	DisableCaller     bool `json:"-"` // disable capture of the caller states in the extra data
+	// [Kroma: END]
 }
 
 //go:generate go run github.com/fjl/gencodec -type StructLog -field-override structLogMarshaling -out gen_structlog.go
@@ -187,6 +196,9 @@ type StructLogger struct {
 
 	callStackLogInd []int
 	// [Scroll: END]
+	// [Kroma: START]
+	callStackTo []common.Address
+	// [Kroma: END]
 	logs     []*StructLog
 	output   []byte
 	err      error
@@ -208,6 +220,14 @@ func NewStructLogger(cfg *LogConfig) *StructLogger {
 	if cfg != nil {
 		logger.cfg = *cfg
 	}
+	// [Kroma: START]
+	// Without the struct logs there's nothing to attach the extra data to.
+	if logger.cfg.DisableStructLogs {
+		logger.cfg.DisableCodeList = true
+		logger.cfg.DisableStateList = true
+		logger.cfg.DisableCaller = true
+	}
+	// [Kroma: END]
 	return logger
 }
 
@@ -222,6 +242,9 @@ func (l *StructLogger) Reset() {
 	// [Scroll: START]
 	l.callStackLogInd = nil
 	// [Scroll: END]
+	// [Kroma: START]
+	l.callStackTo = nil
+	// [Kroma: END]
 	l.err = nil
 	// [Scroll: START]
 	l.createdAccount = nil
@@ -261,6 +284,13 @@ func (l *StructLogger) CaptureState(pc uint64, op OpCode, gas, cost uint64, scop
 		return
 	}
 
+	// [Kroma: START]
+	// Only the touched accounts and storages are collected for the proofs.
+	if l.cfg.DisableStructLogs {
+		l.captureStates(op, scope)
+		return
+	}
+	// [Kroma: END]
 	memory := scope.Memory
 	stack := scope.Stack
 	contract := scope.Contract
@@ -339,7 +369,11 @@ func (l *StructLogger) CaptureState(pc uint64, op OpCode, gas, cost uint64, scop
 	switch op {
 	case CALL, CALLCODE, STATICCALL, DELEGATECALL, CREATE, CREATE2:
 		extraData := structlog.getOrInitExtraData()
-		extraData.Caller = append(extraData.Caller, getWrappedAccountForAddr(l, scope.Contract.Address()))
+		// [Kroma: START]
+		if !l.cfg.DisableCaller {
+			extraData.Caller = append(extraData.Caller, getWrappedAccountForAddr(l, scope.Contract.Address()))
+		}
+		// [Kroma: END]
 	}
 
 	structlog.RefundCounter = l.env.StateDB.GetRefund()
@@ -347,6 +381,30 @@ func (l *StructLogger) CaptureState(pc uint64, op OpCode, gas, cost uint64, scop
 	// [Scroll: END]
 }
 
+// [Kroma: START]
+// captureStates collects the accounts and the storages touched by the opcode
+// without capturing the struct log.
+func (l *StructLogger) captureStates(op OpCode, scope *ScopeContext) {
+	stack := scope.Stack
+	if !l.cfg.DisableStorage && (op == SLOAD || op == SSTORE) && stack.len() >= 1 {
+		contractAddress := scope.Contract.Address()
+		if l.storage[contractAddress] == nil {
+			l.storage[contractAddress] = make(Storage)
+		}
+		// Only the keys are needed for the proofs, the values are left empty.
+		l.storage[contractAddress][stack.data[stack.len()-1].Bytes32()] = common.Hash{}
+	}
+	// The extra data is disabled, the trace funcs only mark the touched accounts.
+	var extraData types.ExtraData
+	for _, exec := range OpcodeExecs[op] {
+		if err := exec(l, scope, &extraData); err != nil {
+			log.Error("Failed to trace data", "opcode", op.String(), "err", err)
+		}
+	}
+}
+
+// [Kroma: END]
+
 // [Scroll:START]
 func (l *StructLogger) CaptureStateAfter(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error) {
 }
@@ -375,11 +433,20 @@ func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, err error) {
 }
 
 func (l *StructLogger) CaptureEnter(typ OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	// [Kroma: START]
+	if l.cfg.DisableStructLogs {
+		l.statesAffected[to] = struct{}{}
+		return
+	}
+	// [Kroma: END]
 	// [Scroll: START]
 	// the last logged op should be CALL/STATICCALL/CALLCODE/CREATE/CREATE2
 	lastLogPos := len(l.logs) - 1
 	log.Debug("mark call stack", "pos", lastLogPos, "op", l.logs[lastLogPos].Op)
 	l.callStackLogInd = append(l.callStackLogInd, lastLogPos)
+	// [Kroma: START]
+	l.callStackTo = append(l.callStackTo, to)
+	// [Kroma: END]
 	// sanity check
 	if len(l.callStackLogInd) != l.env.depth {
 		panic("unexpected evm depth in capture enter")
@@ -389,8 +456,12 @@ func (l *StructLogger) CaptureEnter(typ OpCode, from common.Address, to common.A
 	theLog.getOrInitExtraData()
 	// handling additional updating for CALL/STATICCALL/CALLCODE/CREATE/CREATE2 only
 	// append extraData part for the log, capture the account status (the nonce / balance has been updated in capture enter)
-	wrappedStatus := getWrappedAccountForAddr(l, to)
-	theLog.ExtraData.StateList = append(theLog.ExtraData.StateList, wrappedStatus)
+	// [Kroma: START]
+	if !l.cfg.DisableStateList {
+		wrappedStatus := getWrappedAccountForAddr(l, to)
+		theLog.ExtraData.StateList = append(theLog.ExtraData.StateList, wrappedStatus)
+	}
+	// [Kroma: END]
 	// finally we update the caller's status (it is possible that nonce and balance being updated)
 	if len(theLog.ExtraData.Caller) == 1 {
 		theLog.ExtraData.Caller = append(theLog.ExtraData.Caller, getWrappedAccountForAddr(l, from))
@@ -402,6 +473,11 @@ func (l *StructLogger) CaptureEnter(typ OpCode, from common.Address, to common.A
 // in CaptureExit phase, a CREATE has its target address's code being set and queryable
 // [Scroll: END]
 func (l *StructLogger) CaptureExit(output []byte, gasUsed uint64, err error) {
+	// [Kroma: START]
+	if l.cfg.DisableStructLogs {
+		return
+	}
+	// [Kroma: END]
 	// [Scroll: START]
 	stackH := len(l.callStackLogInd)
 	if stackH == 0 {
@@ -411,6 +487,10 @@ func (l *StructLogger) CaptureExit(output []byte, gasUsed uint64, err error) {
 	theLogPos := l.callStackLogInd[stackH-1]
 	l.callStackLogInd = l.callStackLogInd[:stackH-1]
 	theLog := l.logs[theLogPos]
+	// [Kroma: START]
+	to := l.callStackTo[stackH-1]
+	l.callStackTo = l.callStackTo[:stackH-1]
+	// [Kroma: END]
 	// update "forecast" data
 	if err != nil {
 		theLog.ExtraData.CallFailed = true
@@ -420,16 +500,16 @@ func (l *StructLogger) CaptureExit(output []byte, gasUsed uint64, err error) {
 	switch theLog.Op {
 	case CREATE, CREATE2:
 		// append extraData part for the log whose op is CREATE(2), capture the account status (the codehash would be updated in capture exit)
-		dataLen := len(theLog.ExtraData.StateList)
-		if dataLen == 0 {
-			panic("unexpected data capture for target op")
+		// [Kroma: START]
+		if !l.cfg.DisableStateList {
+			wrappedStatus := getWrappedAccountForAddr(l, to)
+			theLog.ExtraData.StateList = append(theLog.ExtraData.StateList, wrappedStatus)
 		}
-
-		lastAccData := theLog.ExtraData.StateList[dataLen-1]
-		wrappedStatus := getWrappedAccountForAddr(l, lastAccData.Address)
-		theLog.ExtraData.StateList = append(theLog.ExtraData.StateList, wrappedStatus)
-		code := getCodeForAddr(l, lastAccData.Address)
-		theLog.ExtraData.CodeList = append(theLog.ExtraData.CodeList, hexutil.Encode(code))
+		if !l.cfg.DisableCodeList {
+			code := getCodeForAddr(l, to)
+			theLog.ExtraData.CodeList = append(theLog.ExtraData.CodeList, hexutil.Encode(code))
+		}
+		// [Kroma: END]
 	default:
 		//do nothing for other op code
 		return
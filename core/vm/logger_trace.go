@@ -34,7 +34,7 @@ var (
 
 // traceToAddressCode gets tx.to address’s code
 func traceToAddressCode(l *StructLogger, scope *ScopeContext, extraData *types.ExtraData) error {
-	if l.env.To == nil {
+	if l.env.To == nil || l.cfg.DisableCodeList {
 		return nil
 	}
 	code := l.env.StateDB.GetCode(*l.env.To)
@@ -50,15 +50,21 @@ func traceLastNAddressCode(n int) traceFunc {
 			return nil
 		}
 		address := common.Address(stack.data[stack.len()-1-n].Bytes20())
+		l.statesAffected[address] = struct{}{}
+		if l.cfg.DisableCodeList {
+			return nil
+		}
 		code := l.env.StateDB.GetCode(address)
 		extraData.CodeList = append(extraData.CodeList, hexutil.Encode(code))
-		l.statesAffected[address] = struct{}{}
 		return nil
 	}
 }
 
 // traceContractCode gets the contract's code
 func traceContractCode(l *StructLogger, scope *ScopeContext, extraData *types.ExtraData) error {
+	if l.cfg.DisableCodeList {
+		return nil
+	}
 	code := l.env.StateDB.GetCode(scope.Contract.Address())
 	extraData.CodeList = append(extraData.CodeList, hexutil.Encode(code))
 	return nil
@@ -66,7 +72,7 @@ func traceContractCode(l *StructLogger, scope *ScopeContext, extraData *types.Ex
 
 // traceStorage get contract's storage at storage_address
 func traceStorage(l *StructLogger, scope *ScopeContext, extraData *types.ExtraData) error {
-	if scope.Stack.len() == 0 {
+	if scope.Stack.len() == 0 || l.cfg.DisableStateList {
 		return nil
 	}
 	key := common.Hash(scope.Stack.peek().Bytes32())
@@ -78,10 +84,13 @@ func traceStorage(l *StructLogger, scope *ScopeContext, extraData *types.ExtraDa
 
 // traceContractAccount gets the contract's account
 func traceContractAccount(l *StructLogger, scope *ScopeContext, extraData *types.ExtraData) error {
+	l.statesAffected[scope.Contract.Address()] = struct{}{}
+	if l.cfg.DisableStateList {
+		return nil
+	}
 	// Get account state.
 	state := getWrappedAccountForAddr(l, scope.Contract.Address())
 	extraData.StateList = append(extraData.StateList, state)
-	l.statesAffected[scope.Contract.Address()] = struct{}{}
 
 	return nil
 }
@@ -94,10 +103,13 @@ func traceLastNAddressAccount(n int) traceFunc {
 			return nil
 		}
 
+		l.statesAffected[scope.Contract.Address()] = struct{}{}
+		if l.cfg.DisableStateList {
+			return nil
+		}
 		address := common.Address(stack.data[stack.len()-1-n].Bytes20())
 		state := getWrappedAccountForAddr(l, address)
 		extraData.StateList = append(extraData.StateList, state)
-		l.statesAffected[scope.Contract.Address()] = struct{}{}
 
 		return nil
 	}
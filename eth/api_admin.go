@@ -26,6 +26,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/migration"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
@@ -141,3 +142,25 @@ func (api *AdminAPI) ImportChain(file string) (bool, error) {
 	}
 	return true, nil
 }
+
+// MigrationStatus returns the progress of the state migration from ZKTrie to
+// Merkle Patricia Tree.
+func (api *AdminAPI) MigrationStatus() (*migration.MigrationStatus, error) {
+	migrator := api.eth.StateMigrator()
+	if migrator == nil {
+		return nil, errors.New("state migrator is not running")
+	}
+	status := migrator.MigrationStatus()
+	return &status, nil
+}
+
+// MigrationValidationStatus returns the progress of the latest validation of the
+// state migrated from ZKTrie to Merkle Patricia Tree.
+func (api *AdminAPI) MigrationValidationStatus() (*migration.ValidationStatus, error) {
+	migrator := api.eth.StateMigrator()
+	if migrator == nil {
+		return nil, errors.New("state migrator is not running")
+	}
+	status := migrator.ValidationStatus()
+	return &status, nil
+}
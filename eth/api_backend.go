@@ -21,11 +21,13 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
@@ -38,15 +40,21 @@ import (
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// sequencerRetryDelay is the delay between the attempts to forward a transaction
+// to the sequencer.
+const sequencerRetryDelay = 100 * time.Millisecond
+
 // EthAPIBackend implements ethapi.Backend and tracers.Backend for full nodes
 type EthAPIBackend struct {
 	extRPCEnabled       bool
 	allowUnprotectedTxs bool
+	disableTxPool       bool
 	eth                 *Ethereum
 	gpo                 *gasprice.Oracle
 }
@@ -300,9 +308,75 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	if b.ChainConfig().IsKroma() && signedTx.Type() == types.BlobTxType {
 		return types.ErrTxTypeNotSupported
 	}
+	if b.eth.seqRPCService != nil {
+		if err := b.forwardTx(ctx, signedTx); err != nil {
+			return err
+		}
+		if b.disableTxPool {
+			return nil
+		}
+		// Retain tx in local tx pool after forwarding, for local RPC usage.
+		if err := b.eth.txPool.Add([]*types.Transaction{signedTx}, true, false)[0]; err != nil {
+			log.Warn("Successfully sent tx to sequencer, but failed to persist in local tx pool", "err", err, "tx", signedTx.Hash())
+		}
+		return nil
+	}
+	if b.disableTxPool {
+		return nil
+	}
 	return b.eth.txPool.Add([]*types.Transaction{signedTx}, true, false)[0]
 }
 
+// forwardTx sends the transaction to the sequencer, retrying the attempts failed
+// in transport. The transaction rejected by the sequencer is not retried.
+//
+// An attempt failed in transport may still have reached the sequencer, so the
+// transaction already known to the sequencer on a retry is considered sent.
+func (b *EthAPIBackend) forwardTx(ctx context.Context, tx *types.Transaction) error {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	var (
+		timeout = b.eth.config.RollupSequencerHTTPTimeout
+		retries = b.eth.config.RollupSequencerHTTPRetries
+	)
+	for attempt := 0; ; attempt++ {
+		callCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			callCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		err = b.eth.seqRPCService.CallContext(callCtx, nil, "eth_sendRawTransaction", hexutil.Encode(data))
+		cancel()
+
+		var rpcErr rpc.Error
+		if attempt > 0 && isAlreadyKnown(err) {
+			return nil
+		}
+		if err == nil || errors.As(err, &rpcErr) || attempt >= retries || ctx.Err() != nil {
+			return err
+		}
+		log.Debug("Retrying to forward tx to sequencer", "tx", tx.Hash(), "attempt", attempt+1, "err", err)
+		select {
+		case <-time.After(sequencerRetryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// isAlreadyKnown reports whether the sequencer rejected the transaction as being
+// already in its pool. The pool error carries no code of its own, so it is served
+// as the generic -32000 server error with the upstream "already known" message,
+// which is matched as a substring since the sequencer may wrap it.
+func isAlreadyKnown(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32000 {
+		return false
+	}
+	return strings.Contains(rpcErr.Error(), txpool.ErrAlreadyKnown.Error())
+}
+
 func (b *EthAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending := b.eth.txPool.Pending(false)
 	var txs types.Transactions
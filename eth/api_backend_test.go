@@ -0,0 +1,250 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	sequencerTestKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	sequencerTestAddress = crypto.PubkeyToAddress(sequencerTestKey.PublicKey)
+)
+
+// mockSequencer is the stand-in sequencer, receiving the forwarded transactions.
+type mockSequencer struct {
+	lock sync.Mutex
+	txs  []*types.Transaction
+	err  error // Error to reject the transactions with
+
+	known error // Error to reject the known transactions with, the pool error if nil
+
+	lost atomic.Int32 // Number of requests whose responses are lost after being served
+}
+
+func (s *mockSequencer) SendRawTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.err != nil {
+		return common.Hash{}, s.err
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	for _, known := range s.txs {
+		if known.Hash() == tx.Hash() {
+			if s.known != nil {
+				return common.Hash{}, s.known
+			}
+			return common.Hash{}, txpool.ErrAlreadyKnown
+		}
+	}
+	s.txs = append(s.txs, tx)
+	return tx.Hash(), nil
+}
+
+func (s *mockSequencer) received() []*types.Transaction {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.txs
+}
+
+// newSequencerTestNode starts a sequencer serving over HTTP, whose first requests
+// fail in transport as many times as given, and a replica node forwarding the
+// transactions to it.
+func newSequencerTestNode(t *testing.T, failures int32, admission bool) (*node.Node, *Ethereum, *mockSequencer, *atomic.Int32) {
+	sequencer := new(mockSequencer)
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", sequencer); err != nil {
+		t.Fatalf("failed to register sequencer: %v", err)
+	}
+	requests := new(atomic.Int32)
+	httpsrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if sequencer.lost.Add(-1) >= 0 {
+			server.ServeHTTP(httptest.NewRecorder(), r)
+			http.Error(w, "timeout", http.StatusGatewayTimeout)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		httpsrv.Close()
+		server.Stop()
+	})
+
+	stack, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	config := ethconfig.Defaults
+	config.Genesis = &core.Genesis{
+		Config:  params.AllEthashProtocolChanges,
+		Alloc:   core.GenesisAlloc{sequencerTestAddress: {Balance: big.NewInt(params.Ether)}},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	config.RollupSequencerHTTP = httpsrv.URL
+	config.RollupDisableTxPoolAdmission = !admission
+	ethservice, err := New(stack, &config)
+	if err != nil {
+		t.Fatalf("failed to create ethereum service: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	t.Cleanup(func() { stack.Close() })
+	return stack, ethservice, sequencer, requests
+}
+
+func newSequencerTestTx(t *testing.T, nonce uint64) *types.Transaction {
+	tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0x01}, big.NewInt(1), params.TxGas, big.NewInt(2*params.InitialBaseFee), nil), types.LatestSigner(params.AllEthashProtocolChanges), sequencerTestKey)
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	return tx
+}
+
+func sendRawTransaction(stack *node.Node, tx *types.Transaction) error {
+	client := stack.Attach()
+	defer client.Close()
+
+	data, _ := tx.MarshalBinary()
+	return client.CallContext(context.Background(), nil, "eth_sendRawTransaction", hexutil.Encode(data))
+}
+
+func TestSequencerForwarding(t *testing.T) {
+	for _, admission := range []bool{false, true} {
+		stack, ethservice, sequencer, _ := newSequencerTestNode(t, 0, admission)
+
+		tx := newSequencerTestTx(t, 0)
+		if err := sendRawTransaction(stack, tx); err != nil {
+			t.Fatalf("admission %v: failed to send tx: %v", admission, err)
+		}
+		if txs := sequencer.received(); len(txs) != 1 || txs[0].Hash() != tx.Hash() {
+			t.Fatalf("admission %v: tx not forwarded to sequencer", admission)
+		}
+		if pooled := ethservice.TxPool().Get(tx.Hash()) != nil; pooled != admission {
+			t.Fatalf("admission %v: unexpected local pool admission: %v", admission, pooled)
+		}
+	}
+}
+
+func TestSequencerForwardingRejected(t *testing.T) {
+	stack, ethservice, sequencer, requests := newSequencerTestNode(t, 0, true)
+	sequencer.err = errors.New("nonce too low")
+
+	tx := newSequencerTestTx(t, 0)
+	err := sendRawTransaction(stack, tx)
+	if err == nil || !strings.Contains(err.Error(), "nonce too low") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The rejection from the sequencer is not retried, nor is the transaction
+	// admitted to the local pool.
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("unexpected number of requests: have %d, want 1", n)
+	}
+	if ethservice.TxPool().Get(tx.Hash()) != nil {
+		t.Fatal("rejected tx admitted to local pool")
+	}
+}
+
+func TestSequencerForwardingRetries(t *testing.T) {
+	// The transport failures are retried up to the configured times.
+	stack, _, sequencer, requests := newSequencerTestNode(t, 2, false)
+	if err := sendRawTransaction(stack, newSequencerTestTx(t, 0)); err != nil {
+		t.Fatalf("failed to send tx: %v", err)
+	}
+	if n := requests.Load(); n != 3 || len(sequencer.received()) != 1 {
+		t.Fatalf("unexpected forwarding: %d requests, %d txs", n, len(sequencer.received()))
+	}
+	// Beyond that, the error is returned.
+	stack, _, sequencer, requests = newSequencerTestNode(t, 3, false)
+	if err := sendRawTransaction(stack, newSequencerTestTx(t, 0)); err == nil {
+		t.Fatal("expected forwarding failure")
+	}
+	if n := requests.Load(); n != 3 || len(sequencer.received()) != 0 {
+		t.Fatalf("unexpected forwarding: %d requests, %d txs", n, len(sequencer.received()))
+	}
+}
+
+func TestSequencerForwardingLostResponse(t *testing.T) {
+	// The tx reaching the sequencer on an attempt failed in transport is already
+	// known on the retry, which is not a failure.
+	stack, _, sequencer, requests := newSequencerTestNode(t, 0, false)
+	sequencer.lost.Store(1)
+
+	tx := newSequencerTestTx(t, 0)
+	if err := sendRawTransaction(stack, tx); err != nil {
+		t.Fatalf("failed to send tx: %v", err)
+	}
+	if n := requests.Load(); n != 2 || len(sequencer.received()) != 1 {
+		t.Fatalf("unexpected forwarding: %d requests, %d txs", n, len(sequencer.received()))
+	}
+	// The tx already known on the first attempt is still reported.
+	err := sendRawTransaction(stack, tx)
+	if err == nil || err.Error() != txpool.ErrAlreadyKnown.Error() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSequencerForwardingLostResponseWrapped(t *testing.T) {
+	// The sequencer may wrap the pool error, which is still recognized on the retry.
+	stack, _, sequencer, requests := newSequencerTestNode(t, 0, false)
+	sequencer.known = fmt.Errorf("failed to add tx: %w", txpool.ErrAlreadyKnown)
+	sequencer.lost.Store(1)
+
+	if err := sendRawTransaction(stack, newSequencerTestTx(t, 0)); err != nil {
+		t.Fatalf("failed to send tx: %v", err)
+	}
+	if n := requests.Load(); n != 2 || len(sequencer.received()) != 1 {
+		t.Fatalf("unexpected forwarding: %d requests, %d txs", n, len(sequencer.received()))
+	}
+	// Other rejections on the retry are still reported.
+	stack, _, sequencer, _ = newSequencerTestNode(t, 0, false)
+	sequencer.known = errors.New("replacement transaction underpriced")
+	sequencer.lost.Store(1)
+
+	err := sendRawTransaction(stack, newSequencerTestTx(t, 0))
+	if err == nil || !strings.Contains(err.Error(), "underpriced") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
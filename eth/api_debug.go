@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
@@ -446,3 +447,21 @@ func (api *DebugAPI) GetTrieFlushInterval() (string, error) {
 	}
 	return api.eth.blockchain.GetTrieFlushInterval().String(), nil
 }
+
+// ExecutionWitness re-executes the block with the given number on top of the
+// state of its parent, and returns the witness holding every trie node, contract
+// code and ancestor header needed to execute the block statelessly.
+func (api *DebugAPI) ExecutionWitness(ctx context.Context, blockNr rpc.BlockNumber) (*stateless.Witness, error) {
+	block, err := api.eth.APIBackend.BlockByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNr)
+	}
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not executed")
+	}
+	bc := api.eth.blockchain
+	return stateless.Record(bc, bc.StateCache(), block)
+}
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
@@ -78,9 +79,7 @@ type Ethereum struct {
 	snapDialCandidates enode.Iterator
 	merger             *consensus.Merger
 
-	/* [kroma unsupported]
 	seqRPCService        *rpc.Client
-	*/
 	historicalRPCService *rpc.Client
 
 	// DB interfaces
@@ -253,6 +252,13 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.OverrideKromaMPT != nil {
 		overrides.OverrideKromaMPT = config.OverrideKromaMPT
 	}
+	if config.KromaChainConfig != "" {
+		registry, err := params.LoadKromaChainRegistry(config.KromaChainConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kroma chain registry: %w", err)
+		}
+		overrides.KromaChainRegistry = registry
+	}
 	// [Kroma: END]
 
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, config.Genesis, &overrides, eth.engine, vmConfig, eth.shouldPreserve, &config.TransactionHistory)
@@ -301,9 +307,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		BloomCache:     uint64(cacheLimit),
 		EventMux:       eth.eventMux,
 		RequiredBlocks: config.RequiredBlocks,
-		/* [kroma unsupported]
 		NoTxGossip:     config.RollupDisableTxPoolGossip,
-		*/
 	}); err != nil {
 		return nil, err
 	}
@@ -311,7 +315,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	eth.miner = miner.New(eth, &config.Miner, eth.blockchain.Config(), eth.EventMux(), eth.engine, eth.isLocalBlock)
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
-	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
+	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, config.RollupDisableTxPoolAdmission, eth, nil}
 	if eth.APIBackend.allowUnprotectedTxs {
 		log.Info("Unprotected transactions allowed")
 	}
@@ -332,7 +336,6 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 
-	/* [kroma unsupported]
 	if config.RollupSequencerHTTP != "" {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		client, err := rpc.DialContext(ctx, config.RollupSequencerHTTP)
@@ -342,7 +345,6 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		}
 		eth.seqRPCService = client
 	}
-	*/
 
 	if config.RollupHistoricalRPC != "" {
 		ctx, cancel := context.WithTimeout(context.Background(), config.RollupHistoricalRPCTimeout)
@@ -359,7 +361,14 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if eth.blockchain.Config().Zktrie && eth.blockchain.Config().KromaMPTTime != nil && !config.DisableMPTMigration {
 		head := eth.BlockChain().CurrentBlock()
 		if !eth.blockchain.Config().IsKromaMPT(head.Time) {
-			migrator, err := migration.NewStateMigrator(eth)
+			migrator, err := migration.NewStateMigrator(eth, &migration.Config{
+				Workers:        config.MPTMigrationWorkers,
+				MemoryCap:      config.MPTMigrationMemoryCap,
+				Validation:     config.MPTMigrationValidation,
+				SampleAccounts: config.MPTMigrationSampleAccounts,
+				SampleSlots:    config.MPTMigrationSampleSlots,
+				SampleSeed:     config.MPTMigrationSampleSeed,
+			})
 			if err != nil {
 				log.Error("Fail to start state migrator", "error", err)
 			} else {
@@ -411,6 +420,15 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// [Kroma: START]
+	if s.config.RollupSequencerTxConditionalEnabled {
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Service:   ethapi.NewTransactionConditionalAPI(s.APIBackend, s.seqRPCService, s.config.RollupSequencerTxConditionalCostRateLimit),
+		})
+	}
+	// [Kroma: END]
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -645,11 +663,9 @@ func (s *Ethereum) Stop() error {
 	s.miner.Close()
 	s.blockchain.Stop()
 	s.engine.Close()
-	/* [kroma unsupported]
 	if s.seqRPCService != nil {
 		s.seqRPCService.Close()
 	}
-	*/
 	if s.historicalRPCService != nil {
 		s.historicalRPCService.Close()
 	}
@@ -668,7 +684,6 @@ func (s *Ethereum) Stop() error {
 	return nil
 }
 
-/* [kroma unsupported]
 // HandleRequiredProtocolVersion handles the protocol version signal. This implements opt-in halting,
 // the protocol version data is already logged and metered when signaled through the Engine API.
 func (s *Ethereum) HandleRequiredProtocolVersion(required params.ProtocolVersion) error {
@@ -698,4 +713,3 @@ func (s *Ethereum) HandleRequiredProtocolVersion(required params.ProtocolVersion
 	}
 	return nil
 }
-*/
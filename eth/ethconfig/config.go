@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/migration"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/params"
 )
@@ -68,6 +69,20 @@ var Defaults = Config{
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
 	RPCTxFeeCap:        1, // 1 ether
+
+	RollupSequencerHTTPTimeout: 5 * time.Second,
+	RollupSequencerHTTPRetries: 2,
+
+	RollupSequencerTxConditionalCostRateLimit: 5000,
+
+	MPTMigrationWorkers:        migration.DefaultConfig.Workers,
+	MPTMigrationMemoryCap:      migration.DefaultConfig.MemoryCap,
+	MPTMigrationValidation:     migration.DefaultConfig.Validation,
+	MPTMigrationSampleAccounts: migration.DefaultConfig.SampleAccounts,
+	MPTMigrationSampleSlots:    migration.DefaultConfig.SampleSlots,
+
+	BlockTraceCacheRetention: 7200,
+	BlockTraceCacheSize:      2048,
 }
 
 //go:generate go run github.com/fjl/gencodec -type Config -formats toml -out gen_config.go
@@ -169,25 +184,42 @@ type Config struct {
 	/* [kroma unsupported]
 	// ApplySuperchainUpgrades requests the node to load chain-configuration from the superchain-registry.
 	ApplySuperchainUpgrades bool `toml:",omitempty"`
+	*/
 
 	RollupSequencerHTTP                     string
-	*/
-	RollupHistoricalRPC        string
-	RollupHistoricalRPCTimeout time.Duration
-	/* [kroma unsupported]
-	RollupDisableTxPoolGossip               bool
+	RollupSequencerHTTPTimeout              time.Duration // Timeout of forwarding a transaction to the sequencer
+	RollupSequencerHTTPRetries              int           // Number of retries of the forwarding failed in transport
+	RollupHistoricalRPC                     string
+	RollupHistoricalRPCTimeout              time.Duration
 	RollupDisableTxPoolAdmission            bool
+	RollupDisableTxPoolGossip               bool
 	RollupHaltOnIncompatibleProtocolVersion string
-	*/
+	// [Kroma: START]
+	RollupSequencerTxConditionalEnabled       bool    // Whether eth_sendRawTransactionConditional is served
+	RollupSequencerTxConditionalCostRateLimit float64 // Maximum cost of the conditionals accepted per second
+	// [Kroma: END]
 	// [Scroll: START]
 	// Trace option
 	MPTWitness int
 	// [Scroll: END]
 	CircuitParams *params.CircuitParams
 
-	KromaZKTrie         bool
-	OverrideKromaMPT    *uint64 `toml:",omitempty"`
-	DisableMPTMigration bool
+	KromaZKTrie           bool
+	OverrideKromaMPT      *uint64 `toml:",omitempty"`
+	KromaChainConfig      string  `toml:",omitempty"` // Path of the external Kroma chain registry
+	DisableMPTMigration   bool
+	MPTMigrationWorkers   int // Number of concurrent workers migrating the ZKT state to MPT
+	MPTMigrationMemoryCap int // Memory allowance (MB) for buffering migrated MPT nodes
+
+	MPTMigrationValidation     string // Validation mode of the migrated state (full, sample or background)
+	MPTMigrationSampleAccounts int    // Number of sampled accounts in the sample validation
+	MPTMigrationSampleSlots    int    // Number of sampled storage slots per account in the sample validation
+	MPTMigrationSampleSeed     int64  `toml:",omitempty"` // Seed of the sample validation, random if zero
+
+	BlockTraceCache          bool   // Whether to store the generated block traces on disk
+	BlockTraceCacheOnImport  bool   // Whether to generate the block traces of the imported blocks
+	BlockTraceCacheRetention uint64 // Number of recent blocks whose traces are retained, zero to keep all
+	BlockTraceCacheSize      int    // Disk allowance (MB) for the stored block traces, zero for unlimited
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain config.
@@ -18,55 +18,74 @@ import (
 // MarshalTOML marshals as TOML.
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                    *core.Genesis `toml:",omitempty"`
-		NetworkId                  uint64
-		SyncMode                   downloader.SyncMode
-		EthDiscoveryURLs           []string
-		SnapDiscoveryURLs          []string
-		NoPruning                  bool
-		NoPrefetch                 bool
-		TxLookupLimit              uint64                 `toml:",omitempty"`
-		TransactionHistory         uint64                 `toml:",omitempty"`
-		StateHistory               uint64                 `toml:",omitempty"`
-		StateScheme                string                 `toml:",omitempty"`
-		RequiredBlocks             map[uint64]common.Hash `toml:"-"`
-		LightServ                  int                    `toml:",omitempty"`
-		LightIngress               int                    `toml:",omitempty"`
-		LightEgress                int                    `toml:",omitempty"`
-		LightPeers                 int                    `toml:",omitempty"`
-		LightNoPrune               bool                   `toml:",omitempty"`
-		LightNoSyncServe           bool                   `toml:",omitempty"`
-		SkipBcVersionCheck         bool                   `toml:"-"`
-		DatabaseHandles            int                    `toml:"-"`
-		DatabaseCache              int
-		DatabaseFreezer            string
-		TrieCleanCache             int
-		TrieDirtyCache             int
-		TrieTimeout                time.Duration
-		SnapshotCache              int
-		Preimages                  bool
-		FilterLogCacheSize         int
-		Miner                      miner.Config
-		TxPool                     legacypool.Config
-		BlobPool                   blobpool.Config
-		GPO                        gasprice.Config
-		EnablePreimageRecording    bool
-		DocRoot                    string `toml:"-"`
-		RPCGasCap                  uint64
-		RPCEVMTimeout              time.Duration
-		RPCTxFeeCap                float64
-		OverrideCancun             *uint64 `toml:",omitempty"`
-		OverrideVerkle             *uint64 `toml:",omitempty"`
-		OverrideOptimismCanyon     *uint64 `toml:",omitempty"`
-		OverrideOptimismEcotone    *uint64 `toml:",omitempty"`
-		OverrideOptimismInterop    *uint64 `toml:",omitempty"`
-		OverrideKromaMPT           *uint64 `toml:",omitempty"`
-		DisableMPTMigration        bool
-		RollupHistoricalRPC        string
-		RollupHistoricalRPCTimeout time.Duration
-		MPTWitness                 int
-		CircuitParams              *params.CircuitParams
-		KromaZKTrie                bool
+		Genesis                                   *core.Genesis `toml:",omitempty"`
+		NetworkId                                 uint64
+		SyncMode                                  downloader.SyncMode
+		EthDiscoveryURLs                          []string
+		SnapDiscoveryURLs                         []string
+		NoPruning                                 bool
+		NoPrefetch                                bool
+		TxLookupLimit                             uint64                 `toml:",omitempty"`
+		TransactionHistory                        uint64                 `toml:",omitempty"`
+		StateHistory                              uint64                 `toml:",omitempty"`
+		StateScheme                               string                 `toml:",omitempty"`
+		RequiredBlocks                            map[uint64]common.Hash `toml:"-"`
+		LightServ                                 int                    `toml:",omitempty"`
+		LightIngress                              int                    `toml:",omitempty"`
+		LightEgress                               int                    `toml:",omitempty"`
+		LightPeers                                int                    `toml:",omitempty"`
+		LightNoPrune                              bool                   `toml:",omitempty"`
+		LightNoSyncServe                          bool                   `toml:",omitempty"`
+		SkipBcVersionCheck                        bool                   `toml:"-"`
+		DatabaseHandles                           int                    `toml:"-"`
+		DatabaseCache                             int
+		DatabaseFreezer                           string
+		TrieCleanCache                            int
+		TrieDirtyCache                            int
+		TrieTimeout                               time.Duration
+		SnapshotCache                             int
+		Preimages                                 bool
+		FilterLogCacheSize                        int
+		Miner                                     miner.Config
+		TxPool                                    legacypool.Config
+		BlobPool                                  blobpool.Config
+		GPO                                       gasprice.Config
+		EnablePreimageRecording                   bool
+		DocRoot                                   string `toml:"-"`
+		RPCGasCap                                 uint64
+		RPCEVMTimeout                             time.Duration
+		RPCTxFeeCap                               float64
+		OverrideCancun                            *uint64 `toml:",omitempty"`
+		OverrideVerkle                            *uint64 `toml:",omitempty"`
+		OverrideOptimismCanyon                    *uint64 `toml:",omitempty"`
+		OverrideOptimismEcotone                   *uint64 `toml:",omitempty"`
+		OverrideOptimismInterop                   *uint64 `toml:",omitempty"`
+		OverrideKromaMPT                          *uint64 `toml:",omitempty"`
+		KromaChainConfig                          string  `toml:",omitempty"`
+		DisableMPTMigration                       bool
+		MPTMigrationWorkers                       int
+		MPTMigrationMemoryCap                     int
+		MPTMigrationValidation                    string
+		MPTMigrationSampleAccounts                int
+		MPTMigrationSampleSlots                   int
+		MPTMigrationSampleSeed                    int64 `toml:",omitempty"`
+		RollupSequencerHTTP                       string
+		RollupSequencerHTTPTimeout                time.Duration
+		RollupSequencerHTTPRetries                int
+		RollupHistoricalRPC                       string
+		RollupHistoricalRPCTimeout                time.Duration
+		RollupDisableTxPoolAdmission              bool
+		RollupDisableTxPoolGossip                 bool
+		RollupHaltOnIncompatibleProtocolVersion   string
+		RollupSequencerTxConditionalEnabled       bool
+		RollupSequencerTxConditionalCostRateLimit float64
+		MPTWitness                                int
+		CircuitParams                             *params.CircuitParams
+		KromaZKTrie                               bool
+		BlockTraceCache                           bool
+		BlockTraceCacheOnImport                   bool
+		BlockTraceCacheRetention                  uint64
+		BlockTraceCacheSize                       int
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -112,67 +131,105 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.OverrideOptimismEcotone = c.OverrideOptimismEcotone
 	enc.OverrideOptimismInterop = c.OverrideOptimismInterop
 	enc.OverrideKromaMPT = c.OverrideKromaMPT
+	enc.KromaChainConfig = c.KromaChainConfig
 	enc.DisableMPTMigration = c.DisableMPTMigration
+	enc.MPTMigrationWorkers = c.MPTMigrationWorkers
+	enc.MPTMigrationMemoryCap = c.MPTMigrationMemoryCap
+	enc.MPTMigrationValidation = c.MPTMigrationValidation
+	enc.MPTMigrationSampleAccounts = c.MPTMigrationSampleAccounts
+	enc.MPTMigrationSampleSlots = c.MPTMigrationSampleSlots
+	enc.MPTMigrationSampleSeed = c.MPTMigrationSampleSeed
+	enc.RollupSequencerHTTP = c.RollupSequencerHTTP
+	enc.RollupSequencerHTTPTimeout = c.RollupSequencerHTTPTimeout
+	enc.RollupSequencerHTTPRetries = c.RollupSequencerHTTPRetries
 	enc.RollupHistoricalRPC = c.RollupHistoricalRPC
 	enc.RollupHistoricalRPCTimeout = c.RollupHistoricalRPCTimeout
+	enc.RollupDisableTxPoolAdmission = c.RollupDisableTxPoolAdmission
+	enc.RollupDisableTxPoolGossip = c.RollupDisableTxPoolGossip
+	enc.RollupHaltOnIncompatibleProtocolVersion = c.RollupHaltOnIncompatibleProtocolVersion
+	enc.RollupSequencerTxConditionalEnabled = c.RollupSequencerTxConditionalEnabled
+	enc.RollupSequencerTxConditionalCostRateLimit = c.RollupSequencerTxConditionalCostRateLimit
 	enc.MPTWitness = c.MPTWitness
 	enc.CircuitParams = c.CircuitParams
 	enc.KromaZKTrie = c.KromaZKTrie
+	enc.BlockTraceCache = c.BlockTraceCache
+	enc.BlockTraceCacheOnImport = c.BlockTraceCacheOnImport
+	enc.BlockTraceCacheRetention = c.BlockTraceCacheRetention
+	enc.BlockTraceCacheSize = c.BlockTraceCacheSize
 	return &enc, nil
 }
 
 // UnmarshalTOML unmarshals from TOML.
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                    *core.Genesis `toml:",omitempty"`
-		NetworkId                  *uint64
-		SyncMode                   *downloader.SyncMode
-		EthDiscoveryURLs           []string
-		SnapDiscoveryURLs          []string
-		NoPruning                  *bool
-		NoPrefetch                 *bool
-		TxLookupLimit              *uint64                `toml:",omitempty"`
-		TransactionHistory         *uint64                `toml:",omitempty"`
-		StateHistory               *uint64                `toml:",omitempty"`
-		StateScheme                *string                `toml:",omitempty"`
-		RequiredBlocks             map[uint64]common.Hash `toml:"-"`
-		LightServ                  *int                   `toml:",omitempty"`
-		LightIngress               *int                   `toml:",omitempty"`
-		LightEgress                *int                   `toml:",omitempty"`
-		LightPeers                 *int                   `toml:",omitempty"`
-		LightNoPrune               *bool                  `toml:",omitempty"`
-		LightNoSyncServe           *bool                  `toml:",omitempty"`
-		SkipBcVersionCheck         *bool                  `toml:"-"`
-		DatabaseHandles            *int                   `toml:"-"`
-		DatabaseCache              *int
-		DatabaseFreezer            *string
-		TrieCleanCache             *int
-		TrieDirtyCache             *int
-		TrieTimeout                *time.Duration
-		SnapshotCache              *int
-		Preimages                  *bool
-		FilterLogCacheSize         *int
-		Miner                      *miner.Config
-		TxPool                     *legacypool.Config
-		BlobPool                   *blobpool.Config
-		GPO                        *gasprice.Config
-		EnablePreimageRecording    *bool
-		DocRoot                    *string `toml:"-"`
-		RPCGasCap                  *uint64
-		RPCEVMTimeout              *time.Duration
-		RPCTxFeeCap                *float64
-		OverrideCancun             *uint64 `toml:",omitempty"`
-		OverrideVerkle             *uint64 `toml:",omitempty"`
-		OverrideOptimismCanyon     *uint64 `toml:",omitempty"`
-		OverrideOptimismEcotone    *uint64 `toml:",omitempty"`
-		OverrideOptimismInterop    *uint64 `toml:",omitempty"`
-		OverrideKromaMPT           *uint64 `toml:",omitempty"`
-		DisableMPTMigration        *bool
-		RollupHistoricalRPC        *string
-		RollupHistoricalRPCTimeout *time.Duration
-		MPTWitness                 *int
-		CircuitParams              *params.CircuitParams
-		KromaZKTrie                *bool
+		Genesis                                   *core.Genesis `toml:",omitempty"`
+		NetworkId                                 *uint64
+		SyncMode                                  *downloader.SyncMode
+		EthDiscoveryURLs                          []string
+		SnapDiscoveryURLs                         []string
+		NoPruning                                 *bool
+		NoPrefetch                                *bool
+		TxLookupLimit                             *uint64                `toml:",omitempty"`
+		TransactionHistory                        *uint64                `toml:",omitempty"`
+		StateHistory                              *uint64                `toml:",omitempty"`
+		StateScheme                               *string                `toml:",omitempty"`
+		RequiredBlocks                            map[uint64]common.Hash `toml:"-"`
+		LightServ                                 *int                   `toml:",omitempty"`
+		LightIngress                              *int                   `toml:",omitempty"`
+		LightEgress                               *int                   `toml:",omitempty"`
+		LightPeers                                *int                   `toml:",omitempty"`
+		LightNoPrune                              *bool                  `toml:",omitempty"`
+		LightNoSyncServe                          *bool                  `toml:",omitempty"`
+		SkipBcVersionCheck                        *bool                  `toml:"-"`
+		DatabaseHandles                           *int                   `toml:"-"`
+		DatabaseCache                             *int
+		DatabaseFreezer                           *string
+		TrieCleanCache                            *int
+		TrieDirtyCache                            *int
+		TrieTimeout                               *time.Duration
+		SnapshotCache                             *int
+		Preimages                                 *bool
+		FilterLogCacheSize                        *int
+		Miner                                     *miner.Config
+		TxPool                                    *legacypool.Config
+		BlobPool                                  *blobpool.Config
+		GPO                                       *gasprice.Config
+		EnablePreimageRecording                   *bool
+		DocRoot                                   *string `toml:"-"`
+		RPCGasCap                                 *uint64
+		RPCEVMTimeout                             *time.Duration
+		RPCTxFeeCap                               *float64
+		OverrideCancun                            *uint64 `toml:",omitempty"`
+		OverrideVerkle                            *uint64 `toml:",omitempty"`
+		OverrideOptimismCanyon                    *uint64 `toml:",omitempty"`
+		OverrideOptimismEcotone                   *uint64 `toml:",omitempty"`
+		OverrideOptimismInterop                   *uint64 `toml:",omitempty"`
+		OverrideKromaMPT                          *uint64 `toml:",omitempty"`
+		KromaChainConfig                          *string `toml:",omitempty"`
+		DisableMPTMigration                       *bool
+		MPTMigrationWorkers                       *int
+		MPTMigrationMemoryCap                     *int
+		MPTMigrationValidation                    *string
+		MPTMigrationSampleAccounts                *int
+		MPTMigrationSampleSlots                   *int
+		MPTMigrationSampleSeed                    *int64 `toml:",omitempty"`
+		RollupSequencerHTTP                       *string
+		RollupSequencerHTTPTimeout                *time.Duration
+		RollupSequencerHTTPRetries                *int
+		RollupHistoricalRPC                       *string
+		RollupHistoricalRPCTimeout                *time.Duration
+		RollupDisableTxPoolAdmission              *bool
+		RollupDisableTxPoolGossip                 *bool
+		RollupHaltOnIncompatibleProtocolVersion   *string
+		RollupSequencerTxConditionalEnabled       *bool
+		RollupSequencerTxConditionalCostRateLimit *float64
+		MPTWitness                                *int
+		CircuitParams                             *params.CircuitParams
+		KromaZKTrie                               *bool
+		BlockTraceCache                           *bool
+		BlockTraceCacheOnImport                   *bool
+		BlockTraceCacheRetention                  *uint64
+		BlockTraceCacheSize                       *int
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -307,15 +364,60 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.OverrideKromaMPT != nil {
 		c.OverrideKromaMPT = dec.OverrideKromaMPT
 	}
+	if dec.KromaChainConfig != nil {
+		c.KromaChainConfig = *dec.KromaChainConfig
+	}
 	if dec.DisableMPTMigration != nil {
 		c.DisableMPTMigration = *dec.DisableMPTMigration
 	}
+	if dec.MPTMigrationWorkers != nil {
+		c.MPTMigrationWorkers = *dec.MPTMigrationWorkers
+	}
+	if dec.MPTMigrationMemoryCap != nil {
+		c.MPTMigrationMemoryCap = *dec.MPTMigrationMemoryCap
+	}
+	if dec.MPTMigrationValidation != nil {
+		c.MPTMigrationValidation = *dec.MPTMigrationValidation
+	}
+	if dec.MPTMigrationSampleAccounts != nil {
+		c.MPTMigrationSampleAccounts = *dec.MPTMigrationSampleAccounts
+	}
+	if dec.MPTMigrationSampleSlots != nil {
+		c.MPTMigrationSampleSlots = *dec.MPTMigrationSampleSlots
+	}
+	if dec.MPTMigrationSampleSeed != nil {
+		c.MPTMigrationSampleSeed = *dec.MPTMigrationSampleSeed
+	}
+	if dec.RollupSequencerHTTP != nil {
+		c.RollupSequencerHTTP = *dec.RollupSequencerHTTP
+	}
+	if dec.RollupSequencerHTTPTimeout != nil {
+		c.RollupSequencerHTTPTimeout = *dec.RollupSequencerHTTPTimeout
+	}
+	if dec.RollupSequencerHTTPRetries != nil {
+		c.RollupSequencerHTTPRetries = *dec.RollupSequencerHTTPRetries
+	}
 	if dec.RollupHistoricalRPC != nil {
 		c.RollupHistoricalRPC = *dec.RollupHistoricalRPC
 	}
 	if dec.RollupHistoricalRPCTimeout != nil {
 		c.RollupHistoricalRPCTimeout = *dec.RollupHistoricalRPCTimeout
 	}
+	if dec.RollupDisableTxPoolAdmission != nil {
+		c.RollupDisableTxPoolAdmission = *dec.RollupDisableTxPoolAdmission
+	}
+	if dec.RollupDisableTxPoolGossip != nil {
+		c.RollupDisableTxPoolGossip = *dec.RollupDisableTxPoolGossip
+	}
+	if dec.RollupHaltOnIncompatibleProtocolVersion != nil {
+		c.RollupHaltOnIncompatibleProtocolVersion = *dec.RollupHaltOnIncompatibleProtocolVersion
+	}
+	if dec.RollupSequencerTxConditionalEnabled != nil {
+		c.RollupSequencerTxConditionalEnabled = *dec.RollupSequencerTxConditionalEnabled
+	}
+	if dec.RollupSequencerTxConditionalCostRateLimit != nil {
+		c.RollupSequencerTxConditionalCostRateLimit = *dec.RollupSequencerTxConditionalCostRateLimit
+	}
 	if dec.MPTWitness != nil {
 		c.MPTWitness = *dec.MPTWitness
 	}
@@ -325,5 +427,17 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.KromaZKTrie != nil {
 		c.KromaZKTrie = *dec.KromaZKTrie
 	}
+	if dec.BlockTraceCache != nil {
+		c.BlockTraceCache = *dec.BlockTraceCache
+	}
+	if dec.BlockTraceCacheOnImport != nil {
+		c.BlockTraceCacheOnImport = *dec.BlockTraceCacheOnImport
+	}
+	if dec.BlockTraceCacheRetention != nil {
+		c.BlockTraceCacheRetention = *dec.BlockTraceCacheRetention
+	}
+	if dec.BlockTraceCacheSize != nil {
+		c.BlockTraceCacheSize = *dec.BlockTraceCacheSize
+	}
 	return nil
 }
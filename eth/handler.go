@@ -93,9 +93,7 @@ type handlerConfig struct {
 	BloomCache     uint64                 // Megabytes to alloc for snap sync bloom
 	EventMux       *event.TypeMux         // Legacy event mux, deprecate for `feed`
 	RequiredBlocks map[uint64]common.Hash // Hard coded map of required block hashes for sync challenges
-	/* [kroma unsupported]
 	NoTxGossip     bool                   // Disable P2P transaction gossip
-	*/
 }
 
 type handler struct {
@@ -110,9 +108,7 @@ type handler struct {
 	chain    *core.BlockChain
 	maxPeers int
 
-	/* [kroma unsupported]
 	noTxGossip bool
-	*/
 
 	downloader   *downloader.Downloader
 	blockFetcher *fetcher.BlockFetcher
@@ -156,8 +152,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		quitSync:       make(chan struct{}),
 		handlerDoneCh:  make(chan struct{}),
 		handlerStartCh: make(chan struct{}),
-		// [kroma unsupported]
-		// noTxGossip:     config.NoTxGossip,
+		noTxGossip:     config.NoTxGossip,
 	}
 	if config.Sync == downloader.FullSync {
 		// The database seems empty as the current block is the genesis. Yet the snap
@@ -412,7 +407,9 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 
 	// Propagate existing transactions. new transactions appearing
 	// after this will be sent via broadcasts.
-	h.syncTransactions(peer)
+	if !h.noTxGossip {
+		h.syncTransactions(peer)
+	}
 
 	// Create a notification channel for pending requests if the peer goes down
 	dead := make(chan struct{})
@@ -530,11 +527,14 @@ func (h *handler) unregisterPeer(id string) {
 func (h *handler) Start(maxPeers int) {
 	h.maxPeers = maxPeers
 
-	// broadcast and announce transactions (only new ones, not resurrected ones)
-	h.wg.Add(1)
-	h.txsCh = make(chan core.NewTxsEvent, txChanSize)
-	h.txsSub = h.txpool.SubscribeTransactions(h.txsCh, false)
-	go h.txBroadcastLoop()
+	// broadcast and announce transactions (only new ones, not resurrected ones),
+	// unless transaction gossip is disabled to keep them private
+	if !h.noTxGossip {
+		h.wg.Add(1)
+		h.txsCh = make(chan core.NewTxsEvent, txChanSize)
+		h.txsSub = h.txpool.SubscribeTransactions(h.txsCh, false)
+		go h.txBroadcastLoop()
+	}
 
 	// broadcast mined blocks
 	h.wg.Add(1)
@@ -551,7 +551,9 @@ func (h *handler) Start(maxPeers int) {
 }
 
 func (h *handler) Stop() {
-	h.txsSub.Unsubscribe()        // quits txBroadcastLoop
+	if h.txsSub != nil {
+		h.txsSub.Unsubscribe() // quits txBroadcastLoop
+	}
 	h.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
 
 	// Quit chainSync and txsync64.
@@ -631,6 +633,13 @@ func (h *handler) BroadcastTransactions(txs types.Transactions) {
 	)
 	// Broadcast transactions to a batch of peers not knowing about it
 	for _, tx := range txs {
+		// [Kroma: START]
+		// Conditional transactions are never broadcast, as the conditional is not part
+		// of the transaction encoding and would be lost on the way.
+		if tx.Conditional() != nil {
+			continue
+		}
+		// [Kroma: END]
 		peers := h.peers.peersWithoutTransaction(tx.Hash())
 
 		var numDirect int
@@ -450,6 +450,80 @@ func testTransactionPropagation(t *testing.T, protocol uint) {
 	}
 }
 
+// Tests that a handler with transaction gossip disabled neither propagates its
+// pooled transactions nor accepts transactions from its peers.
+func TestNoTxGossip68(t *testing.T) { testNoTxGossip(t, eth.ETH68) }
+
+func testNoTxGossip(t *testing.T, protocol uint) {
+	t.Parallel()
+
+	handler := newTestHandlerWithTxGossip(0, false)
+	defer handler.close()
+
+	handler.handler.synced.Store(true) // mark synced, which would accept transactions otherwise
+
+	txs := make(chan core.NewTxsEvent, 1)
+	sub := handler.txpool.SubscribeTransactions(txs, false)
+	defer sub.Unsubscribe()
+
+	// Fill the pool before the peer joins, to check the initial sync as well
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+	tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+	handler.txpool.Add([]*types.Transaction{tx}, false, false)
+	<-txs
+
+	p2pSrc, p2pSink := p2p.MsgPipe()
+	defer p2pSrc.Close()
+	defer p2pSink.Close()
+
+	src := eth.NewPeer(protocol, p2p.NewPeerPipe(enode.ID{1}, "", nil, p2pSrc), p2pSrc, handler.txpool)
+	sink := eth.NewPeer(protocol, p2p.NewPeerPipe(enode.ID{2}, "", nil, p2pSink), p2pSink, handler.txpool)
+	defer src.Close()
+	defer sink.Close()
+
+	go handler.handler.runEthPeer(src, func(peer *eth.Peer) error {
+		return eth.Handle((*ethHandler)(handler.handler), peer)
+	})
+	var (
+		genesis = handler.chain.Genesis()
+		head    = handler.chain.CurrentBlock()
+		td      = handler.chain.GetTd(head.Hash(), head.Number.Uint64())
+	)
+	if err := sink.Handshake(1, td, head.Hash(), genesis.Hash(), forkid.NewIDWithChain(handler.chain), forkid.NewFilter(handler.chain)); err != nil {
+		t.Fatalf("failed to run protocol handshake")
+	}
+	backend := new(testEthHandler)
+
+	anns := make(chan []common.Hash)
+	annSub := backend.txAnnounces.Subscribe(anns)
+	defer annSub.Unsubscribe()
+
+	bcasts := make(chan []*types.Transaction)
+	bcastSub := backend.txBroadcasts.Subscribe(bcasts)
+	defer bcastSub.Unsubscribe()
+
+	go eth.Handle(backend, sink)
+
+	// Add a new transaction after the peer joined, and send one from the peer
+	tx, _ = types.SignTx(types.NewTransaction(1, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil), types.HomesteadSigner{}, testKey)
+	handler.txpool.Add([]*types.Transaction{tx}, false, false)
+	<-txs
+
+	tx, _ = types.SignTx(types.NewTransaction(2, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil), types.HomesteadSigner{}, testKey)
+	if err := sink.SendTransactions([]*types.Transaction{tx}); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	select {
+	case hashes := <-anns:
+		t.Errorf("transactions announced: %v", hashes)
+	case txs := <-bcasts:
+		t.Errorf("transactions broadcast: %d", len(txs))
+	case event := <-txs:
+		t.Errorf("transactions accepted from peer: %d", len(event.Txs))
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
 // Tests that blocks are broadcast to a sqrt number of peers only.
 func TestBroadcastBlock1Peer(t *testing.T)    { testBroadcastBlock(t, 1, 1) }
 func TestBroadcastBlock2Peers(t *testing.T)   { testBroadcastBlock(t, 2, 1) }
@@ -145,6 +145,12 @@ func newTestHandler() *testHandler {
 // newTestHandlerWithBlocks creates a new handler for testing purposes, with a
 // given number of initial blocks.
 func newTestHandlerWithBlocks(blocks int) *testHandler {
+	return newTestHandlerWithTxGossip(blocks, true)
+}
+
+// newTestHandlerWithTxGossip creates a new handler for testing purposes, with a
+// given number of initial blocks and transaction gossip enabled or disabled.
+func newTestHandlerWithTxGossip(blocks int, gossip bool) *testHandler {
 	// Create a database pre-initialize with a genesis block
 	db := rawdb.NewMemoryDatabase()
 	gspec := &core.Genesis{
@@ -167,6 +173,7 @@ func newTestHandlerWithBlocks(blocks int) *testHandler {
 		Network:    1,
 		Sync:       downloader.SnapSync,
 		BloomCache: 1,
+		NoTxGossip: !gossip,
 	})
 	handler.Start(1000)
 
@@ -37,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
@@ -89,11 +90,13 @@ type Backend interface {
 	StateAtBlock(ctx context.Context, block *types.Block, reexec uint64, base *state.StateDB, readOnly bool, preferDisk bool) (*state.StateDB, StateReleaseFunc, error)
 	StateAtTransaction(ctx context.Context, block *types.Block, txIndex int, reexec uint64) (*core.Message, vm.BlockContext, *state.StateDB, StateReleaseFunc, error)
 	HistoricalRPCService() *rpc.Client
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
 }
 
 // API is the collection of tracing APIs exposed over the private debugging endpoint.
 type API struct {
-	backend Backend
+	backend    Backend
+	traceCache *BlockTraceCache // Store of the generated block traces, nil if disabled
 }
 
 // NewAPI creates a new API definition for the tracing methods of the Ethereum service.
@@ -158,6 +161,13 @@ type TraceConfig struct {
 	// Config specific to given tracer. Note struct logger
 	// config are historically embedded in main object.
 	TracerConfig json.RawMessage
+
+	// [Kroma: START]
+	// Profile and output format of the block traces, which are only honoured by
+	// the kroma_getBlockTraceByNumberOrHash methods and debug_blockTraceToFile.
+	Profile *string
+	*BlockTraceOutput
+	// [Kroma: END]
 }
 
 // TraceCallConfig is the config for traceCall API. It holds one more
@@ -1030,19 +1040,36 @@ func (api *API) traceTx(ctx context.Context, message *core.Message, txctx *Conte
 
 // APIs return the collection of RPC services the tracer package offers.
 func APIs(backend Backend) []rpc.API {
+	return APIsWithBlockTraceCache(backend, nil)
+}
+
+// APIsWithBlockTraceCache returns the collection of RPC services the tracer
+// package offers, serving the block traces from the given cache if not nil.
+func APIsWithBlockTraceCache(backend Backend, traceCache *BlockTraceCache) []rpc.API {
+	api := &API{backend: backend, traceCache: traceCache}
+
 	// Append all the local APIs and return
 	return []rpc.API{
 		{
 			Namespace: "debug",
-			Service:   NewAPI(backend),
+			Service:   api,
 		},
 		// [Scroll: START]
 		{
 			Namespace: "kroma",
 			Version:   "1.0",
-			Service:   TraceBlock(NewAPI(backend)),
+			Service:   TraceBlock(api),
+			Public:    true,
+		},
+		{
+			Namespace: "eth",
+			Service:   &BlockTraceAPI{api: api},
 			Public:    true,
 		},
+		{
+			Namespace: "debug",
+			Service:   &BlockTraceFileAPI{api: api},
+		},
 		// [Scroll: END]
 	}
 }
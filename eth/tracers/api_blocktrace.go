@@ -3,6 +3,7 @@
 package tracers
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -19,16 +20,21 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie/zkproof"
+	"golang.org/x/exp/slices"
 )
 
 type TraceBlock interface {
-	GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (trace *types.BlockTrace, err error)
+	GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (*types.BlockTrace, error)
 }
 
 type traceEnv struct {
-	config *TraceConfig
+	config  *TraceConfig
+	profile *blockTraceProfile
 
 	coinbase common.Address
+	// mpt is set if the block is executed on the MPT, for which the MPT storage
+	// trace is produced.
+	mpt bool
 
 	// rMu lock is used to protect txs executed in parallel.
 	signer   types.Signer
@@ -46,7 +52,34 @@ type traceEnv struct {
 }
 
 // GetBlockTraceByNumberOrHash replays the block and returns the structured BlockResult by hash or number.
-func (api *API) GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (trace *types.BlockTrace, err error) {
+//
+// The parts of the trace produced are selected by the profile of the config, the
+// full trace is produced by default.
+func (api *API) GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (*types.BlockTrace, error) {
+	if config != nil && !config.BlockTraceOutput.isDefault() {
+		return nil, errors.New("block trace output format is only supported by the encoded block traces")
+	}
+	return api.blockTrace(ctx, blockNrOrHash, config)
+}
+
+// blockTrace replays the block and returns the structured BlockResult by hash or
+// number, ignoring the output format of the config.
+func (api *API) blockTrace(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (trace *types.BlockTrace, err error) {
+	if config != nil && config.Tracer != nil {
+		return nil, errors.New("tracer is not supported by block traces, select a trace profile instead")
+	}
+	// The output format doesn't affect the trace itself, so the config is treated
+	// as the default one if nothing else is set.
+	if config != nil && config.LogConfig == nil && config.Timeout == nil && config.Reexec == nil && len(config.TracerConfig) == 0 &&
+		(config.Profile == nil || *config.Profile == BlockTraceProfileFull) {
+		config = nil
+	}
+	var profile *blockTraceProfile
+	if config != nil {
+		if profile, err = lookupBlockTraceProfile(config.Profile); err != nil {
+			return nil, err
+		}
+	}
 	var block *types.Block
 	if number, ok := blockNrOrHash.Number(); ok {
 		block, err = api.blockByNumber(ctx, number)
@@ -60,6 +93,13 @@ func (api *API) GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash r
 	if block.NumberU64() == 0 {
 		return nil, errors.New("genesis is not traceable")
 	}
+	// Only the traces generated with the default config are cached.
+	cacheable := api.traceCache != nil && config == nil
+	if cacheable {
+		if trace := api.traceCache.get(block); trace != nil {
+			return trace, nil
+		}
+	}
 	if config == nil {
 		config = &TraceConfig{
 			LogConfig: &vm.LogConfig{
@@ -67,22 +107,24 @@ func (api *API) GetBlockTraceByNumberOrHash(ctx context.Context, blockNrOrHash r
 				EnableReturnData: true,
 			},
 		}
-	} else if config.Tracer != nil {
-		config.Tracer = nil
-		log.Warn("Tracer params is unsupported")
+		profile = blockTraceProfiles[BlockTraceProfileFull]
 	}
 
 	// create current execution environment.
-	env, err := api.createTraceEnv(ctx, config, block)
+	env, err := api.createTraceEnv(ctx, config, profile, block)
 	if err != nil {
 		return nil, err
 	}
 
-	return api.getBlockTrace(block, env)
+	trace, err = api.getBlockTrace(block, env)
+	if err == nil && cacheable {
+		api.traceCache.put(ctx, block, trace)
+	}
+	return trace, err
 }
 
 // Make trace environment for current block.
-func (api *API) createTraceEnv(ctx context.Context, config *TraceConfig, block *types.Block) (*traceEnv, error) {
+func (api *API) createTraceEnv(ctx context.Context, config *TraceConfig, profile *blockTraceProfile, block *types.Block) (*traceEnv, error) {
 	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
 	if err != nil {
 		return nil, err
@@ -105,7 +147,9 @@ func (api *API) createTraceEnv(ctx context.Context, config *TraceConfig, block *
 
 	env := &traceEnv{
 		config:   config,
+		profile:  profile,
 		coinbase: coinbase,
+		mpt:      !statedb.IsZktrie() && !api.backend.ChainConfig().IsKromaMPTActivationBlock(block.Time()),
 		signer:   types.MakeSigner(api.backend.ChainConfig(), block.Number(), block.Time()),
 		state:    statedb,
 		blockCtx: core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil, api.backend.ChainConfig(), statedb),
@@ -225,7 +269,7 @@ func (api *API) getTxResult(env *traceEnv, state *state.StateDB, index int, bloc
 		}
 	}
 
-	tracer := vm.NewStructLogger(env.config.LogConfig)
+	tracer := vm.NewStructLogger(env.profile.logConfig(env.config.LogConfig))
 	// Run the transaction with tracing enabled.
 	vmenv := vm.NewEVM(env.blockCtx, core.NewEVMTxContext(msg), state, api.backend.ChainConfig(), vm.Config{Tracer: tracer, NoBaseFee: true})
 
@@ -252,7 +296,16 @@ func (api *API) getTxResult(env *traceEnv, state *state.StateDB, index int, bloc
 		to = &createdAcc.Address
 	}
 	// collect affected account after tx being applied
-	for _, acc := range []common.Address{from, *to, env.coinbase} {
+	affected := []common.Address{from, *to, env.coinbase}
+	if env.mpt {
+		// The MPT storage trace replays every update on the state, the fees paid
+		// to the vaults included.
+		for _, vault := range mptFeeRecipients(api.backend.ChainConfig(), tx) {
+			affected = append(affected, vault)
+			tracer.UpdatedAccounts()[vault] = struct{}{}
+		}
+	}
+	for _, acc := range affected {
 		after = append(after, &types.AccountWrapper{
 			Address:  acc,
 			Nonce:    state.GetNonce(acc),
@@ -367,13 +420,115 @@ func (api *API) fillBlockTrace(env *traceEnv, block *types.Block) (*types.BlockT
 			evmTrace.ByteCode = hexutil.Encode(tx.Data())
 		}
 	}
+	if env.mpt {
+		if err := api.fillDeletionProofs(env, blockTrace); err != nil {
+			return nil, fmt.Errorf("failed to fill deletion proofs: %w", err)
+		}
+	}
 
 	// only zktrie model has the ability to get `mptwitness`.
-	if api.backend.ChainConfig().Zktrie {
+	if api.backend.ChainConfig().Zktrie && env.profile.MPTWitness {
 		if err := zkproof.FillBlockTraceForMPTWitness(zkproof.MPTWitnessType(api.backend.CacheConfig().MPTWitness), blockTrace); err != nil {
 			log.Error("fill mpt witness fail", "error", err)
 		}
+	} else if env.mpt && env.profile.MPTWitness {
+		// The MPT blocks get the ordered updates on the MPT in place of the witness.
+		if err := zkproof.FillBlockTraceForMPTStorageTrace(zkproof.MPTWitnessType(api.backend.CacheConfig().MPTWitness), blockTrace); err != nil {
+			log.Error("fill mpt storage trace fail", "error", err)
+		}
 	}
 
 	return blockTrace, nil
 }
+
+// fillDeletionProofs fills the deletion proofs with the nodes needed to apply
+// the deletions of the block on the MPT besides the account and storage proofs,
+// which are the siblings collapsed on the deletions.
+//
+// The deletions are the keys written empty by the ordered updates of the block
+// trace, including the ones written again later. They are replayed alone on the
+// tries before the block, so that the siblings are resolved regardless of the
+// order the updates are applied in.
+func (api *API) fillDeletionProofs(env *traceEnv, blockTrace *types.BlockTrace) error {
+	accounts, slots, err := zkproof.MPTDeletions(blockTrace)
+	if err != nil {
+		return err
+	}
+	db := env.state.Database()
+	tr, err := db.OpenTrie(env.RootBefore)
+	if err != nil {
+		return err
+	}
+	witness := make(map[string]struct{})
+	for addr, keys := range slots {
+		acc, err := tr.GetAccount(addr)
+		if err != nil {
+			return err
+		}
+		if acc == nil || acc.Root == types.EmptyRootHash {
+			continue
+		}
+		st, err := db.OpenStorageTrie(env.RootBefore, addr, acc.Root, tr)
+		if err != nil {
+			return err
+		}
+		for key := range keys {
+			if before, err := st.GetStorage(addr, key.Bytes()); err != nil {
+				return err
+			} else if len(before) == 0 {
+				continue
+			}
+			if err := st.DeleteStorage(addr, key.Bytes()); err != nil {
+				return err
+			}
+		}
+		st.Hash()
+		for node := range st.Witness() {
+			witness[node] = struct{}{}
+		}
+	}
+	for addr := range accounts {
+		if acc, err := tr.GetAccount(addr); err != nil {
+			return err
+		} else if acc == nil {
+			continue
+		}
+		if err := tr.DeleteAccount(addr); err != nil {
+			return err
+		}
+	}
+	tr.Hash()
+	for node := range tr.Witness() {
+		witness[node] = struct{}{}
+	}
+
+	// Drop the nodes already in the proofs.
+	for _, proof := range env.Proofs {
+		for _, node := range proof {
+			delete(witness, string(node))
+		}
+	}
+	for _, proofs := range env.StorageProofs {
+		for _, proof := range proofs {
+			for _, node := range proof {
+				delete(witness, string(node))
+			}
+		}
+	}
+	for node := range witness {
+		env.DeletionProofs = append(env.DeletionProofs, []byte(node))
+	}
+	slices.SortFunc(env.DeletionProofs, func(a, b hexutil.Bytes) int {
+		return bytes.Compare(a, b)
+	})
+	return nil
+}
+
+// mptFeeRecipients returns the vaults the fees of the transaction are paid to
+// besides the coinbase after the KromaMPT fork.
+func mptFeeRecipients(config *params.ChainConfig, tx *types.Transaction) []common.Address {
+	if !config.IsKroma() || tx.IsDepositTx() {
+		return nil
+	}
+	return []common.Address{params.OptimismBaseFeeRecipient, params.OptimismL1FeeRecipient}
+}
@@ -0,0 +1,194 @@
+package tracers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// blockTraceRefreshInterval is the interval at which the block trace
+	// subscription checks the followed head even without new chain head events,
+	// since the safe and finalized heads are updated silently.
+	blockTraceRefreshInterval = 2 * time.Second
+
+	// maxBlockTraceLag is the maximum number of blocks a block trace subscription
+	// may fall behind the followed head. The blocks beyond are skipped, since
+	// their states are unlikely to be available for tracing anyway.
+	maxBlockTraceLag = defaultTraceReexec
+)
+
+// BlockTraceSubscriptionConfig is the config for the newBlockTrace subscription.
+type BlockTraceSubscriptionConfig struct {
+	TraceConfig
+	// Tag is the head followed by the subscription, which is one of latest,
+	// safe and finalized. Defaults to latest.
+	Tag *rpc.BlockNumber `json:"tag"`
+}
+
+// BlockTraceAPI provides the block trace subscription for the provers.
+type BlockTraceAPI struct {
+	api *API
+}
+
+// NewBlockTraceAPI creates a new API definition for the block trace subscription.
+func NewBlockTraceAPI(backend Backend) *BlockTraceAPI {
+	return &BlockTraceAPI{api: NewAPI(backend)}
+}
+
+// NewBlockTrace sends the execution trace of each block appended to the followed
+// head. The blocks are traced one by one, so that the subscription may lag behind
+// the chain without holding it up. When a reorg reverts the traced blocks, their
+// headers are sent with the removed flag set before the traces of the new blocks.
+func (api *BlockTraceAPI) NewBlockTrace(ctx context.Context, config *BlockTraceSubscriptionConfig) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	var (
+		tag         = rpc.LatestBlockNumber
+		traceConfig *TraceConfig
+	)
+	if config != nil {
+		if config.Tag != nil {
+			tag = *config.Tag
+		}
+		traceConfig = &config.TraceConfig
+	}
+	switch tag {
+	case rpc.LatestBlockNumber, rpc.SafeBlockNumber, rpc.FinalizedBlockNumber:
+	default:
+		return nil, fmt.Errorf("unsupported block tag %s, expected latest, safe or finalized", tag)
+	}
+	// The traces start from the block after the current head, which might not be
+	// available yet for the safe and finalized tags.
+	head, err := api.api.backend.HeaderByNumber(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	sub := notifier.CreateSubscription()
+	go api.followBlockTraces(notifier, sub, tag, head, traceConfig)
+	return sub, nil
+}
+
+// followBlockTraces keeps sending the traces of the followed head until the
+// subscription is closed.
+func (api *BlockTraceAPI) followBlockTraces(notifier *rpc.Notifier, sub *rpc.Subscription, tag rpc.BlockNumber, last *types.Header, config *TraceConfig) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Coalesce the chain head events into a single pending signal, the feed must
+	// not be blocked by the slow tracing.
+	wake := make(chan struct{}, 1)
+	go func() {
+		heads := make(chan core.ChainHeadEvent, 16)
+		headSub := api.api.backend.SubscribeChainHeadEvent(heads)
+		defer headSub.Unsubscribe()
+
+		for {
+			select {
+			case <-heads:
+				select {
+				case wake <- struct{}{}:
+				default:
+				}
+			case <-headSub.Err():
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	ticker := time.NewTicker(blockTraceRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wake:
+		case <-ticker.C:
+		case <-sub.Err():
+			return
+		case <-notifier.Closed():
+			return
+		}
+		var err error
+		if last, err = api.sendBlockTraces(ctx, notifier, sub.ID, tag, last, config); err != nil {
+			log.Warn("Failed to send block traces", "id", sub.ID, "err", err)
+		}
+	}
+}
+
+// sendBlockTraces sends the traces of the blocks from the last sent one up to the
+// current followed head, reverting the sent blocks which are no longer canonical
+// first. It returns the last sent block, which is nil if nothing was sent yet.
+func (api *BlockTraceAPI) sendBlockTraces(ctx context.Context, notifier *rpc.Notifier, id rpc.ID, tag rpc.BlockNumber, last *types.Header, config *TraceConfig) (*types.Header, error) {
+	backend := api.api.backend
+	target, err := backend.HeaderByNumber(ctx, tag)
+	if err != nil || target == nil {
+		return last, err
+	}
+	if last == nil {
+		// The followed head has just become available, start with it.
+		if target.Number.Sign() == 0 {
+			return target, nil
+		}
+		if last, err = backend.HeaderByHash(ctx, target.ParentHash); err != nil || last == nil {
+			return nil, fmt.Errorf("parent of block #%d not found: %v", target.Number, err)
+		}
+	}
+	// Revert the sent blocks which are no longer in the canonical chain.
+	for last.Number.Sign() > 0 {
+		if last.Number.Cmp(target.Number) <= 0 {
+			canon, err := backend.HeaderByNumber(ctx, rpc.BlockNumber(last.Number.Int64()))
+			if err != nil {
+				return last, err
+			}
+			if canon != nil && canon.Hash() == last.Hash() {
+				break
+			}
+		}
+		parent, err := backend.HeaderByHash(ctx, last.ParentHash)
+		if err != nil || parent == nil {
+			return last, fmt.Errorf("parent of block #%d not found: %v", last.Number, err)
+		}
+		if err := notifier.Notify(id, &types.BlockTrace{Header: last, Removed: true}); err != nil {
+			return last, err
+		}
+		last = parent
+	}
+	// Skip the blocks which are too far behind.
+	if lag := target.Number.Uint64() - last.Number.Uint64(); target.Number.Cmp(last.Number) > 0 && lag > maxBlockTraceLag {
+		number := target.Number.Uint64() - maxBlockTraceLag
+		log.Warn("Block trace subscription lagging behind, skipping blocks", "id", id, "from", last.Number.Uint64()+1, "to", number)
+		skipped, err := backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil || skipped == nil {
+			return last, fmt.Errorf("block #%d not found: %v", number, err)
+		}
+		last = skipped
+	}
+	for last.Number.Cmp(target.Number) < 0 {
+		number := last.Number.Uint64() + 1
+		header, err := backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil || header == nil {
+			return last, fmt.Errorf("block #%d not found: %v", number, err)
+		}
+		// The chain has been reorged in the meantime, retry in the next round.
+		if header.ParentHash != last.Hash() {
+			return last, nil
+		}
+		trace, err := api.api.blockTrace(ctx, rpc.BlockNumberOrHashWithHash(header.Hash(), false), config)
+		if err != nil {
+			return last, err
+		}
+		if err := notifier.Notify(id, trace); err != nil {
+			return last, err
+		}
+		last = header
+	}
+	return last, nil
+}
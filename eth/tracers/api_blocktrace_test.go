@@ -3,20 +3,31 @@
 package tracers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie/zkproof"
 )
 
 // erc20MetaData contains all meta data concerning the ERC20 contract.
@@ -225,3 +236,536 @@ func createTx(b *core.BlockGen, auth *bind.TransactOpts, to *common.Address, val
 	}
 	return signedTx, err
 }
+
+func TestBlockTraceAPI_NewBlockTrace(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		signer = types.HomesteadSigner{}
+	)
+	transfer := func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+	}
+	backend := newTestBackend(t, 2, genesis, transfer)
+	defer backend.teardown()
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", NewBlockTraceAPI(backend)); err != nil {
+		t.Fatalf("failed to register api: %v", err)
+	}
+	client := ethclient.NewClient(rpc.DialInProc(server))
+	defer client.Close()
+
+	traces := make(chan *types.BlockTrace, 16)
+	sub, err := client.SubscribeNewBlockTrace(context.Background(), traces)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	expect := func(header *types.Header, removed bool) {
+		t.Helper()
+		select {
+		case trace := <-traces:
+			if trace.Header.Hash() != header.Hash() || trace.Removed != removed {
+				t.Fatalf("unexpected trace: have #%d %x (removed %v), want #%d %x (removed %v)",
+					trace.Header.Number, trace.Header.Hash(), trace.Removed, header.Number, header.Hash(), removed)
+			}
+			if !removed && len(trace.Transactions) != 1 {
+				t.Fatalf("block #%d: unexpected transactions: %d", header.Number, len(trace.Transactions))
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("block #%d: trace not received", header.Number)
+		}
+	}
+	// The traces of the new blocks are sent in order.
+	parent := backend.chain.GetBlockByNumber(2)
+	blocks, _ := core.GenerateChain(backend.chainConfig, parent, backend.engine, backend.chaindb, 2, transfer)
+	if _, err := backend.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	for _, block := range blocks {
+		expect(block.Header(), false)
+	}
+	// A reorg reverts the traced blocks before sending the new ones.
+	fork, _ := core.GenerateChain(backend.chainConfig, parent, backend.engine, backend.chaindb, 3, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+		transfer(i, b)
+	})
+	if _, err := backend.chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	expect(blocks[1].Header(), true)
+	expect(blocks[0].Header(), true)
+	for _, block := range fork {
+		expect(block.Header(), false)
+	}
+}
+
+func TestBlockTraceCache(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		signer = types.HomesteadSigner{}
+	)
+	transfer := func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+	}
+	backend := newTestBackend(t, 4, genesis, transfer)
+	defer backend.teardown()
+
+	cache := NewBlockTraceCache(backend, BlockTraceCacheConfig{Retention: 2, OnImport: true})
+	api := &API{backend: backend, traceCache: cache}
+	getTrace := func(number uint64, config *TraceConfig) *types.BlockTrace {
+		t.Helper()
+		trace, err := api.GetBlockTraceByNumberOrHash(context.Background(), rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number)), config)
+		if err != nil {
+			t.Fatalf("failed to trace block #%d: %v", number, err)
+		}
+		return trace
+	}
+	stored := func(number uint64) bool {
+		return rawdb.HasBlockTrace(backend.chaindb, backend.chain.GetCanonicalHash(number), number)
+	}
+	// The traces generated with the default config are stored, unless they are
+	// too old to be retained.
+	getTrace(4, nil)
+	getTrace(3, &TraceConfig{LogConfig: &vm.LogConfig{EnableMemory: true}})
+	getTrace(1, nil)
+	if !stored(4) || stored(3) || stored(1) {
+		t.Fatalf("unexpected stored traces: #4 %v, #3 %v, #1 %v", stored(4), stored(3), stored(1))
+	}
+	// The stored trace is served directly.
+	hash := backend.chain.GetCanonicalHash(4)
+	blob, _ := json.Marshal(&types.BlockTrace{ChainID: 42, Header: backend.chain.GetHeaderByNumber(4)})
+	rawdb.WriteBlockTrace(backend.chaindb, hash, 4, blob)
+	if trace := getTrace(4, nil); trace.ChainID != 42 {
+		t.Fatalf("stored trace not served, chain id %d", trace.ChainID)
+	}
+	// The traces of the lowest blocks are pruned first beyond the size limit.
+	cache.config.Size = cache.size + 1
+	getTrace(3, nil)
+	if stored(3) || !stored(4) {
+		t.Fatalf("unexpected stored traces after pruning: #3 %v, #4 %v", stored(3), stored(4))
+	}
+	cache.config.Size = 0
+
+	// The imported blocks are traced in the background.
+	if err := cache.Start(); err != nil {
+		t.Fatalf("failed to start cache: %v", err)
+	}
+	defer cache.Stop()
+
+	blocks, _ := core.GenerateChain(backend.chainConfig, backend.chain.GetBlockByNumber(4), backend.engine, backend.chaindb, 2, transfer)
+	if _, err := backend.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); !stored(6); {
+		if time.Now().After(deadline) {
+			t.Fatal("imported block not traced")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// The traces fallen out of the retention window are pruned.
+	if stored(4) {
+		t.Fatal("stale trace not pruned")
+	}
+}
+
+// newCallTraceBackend creates a backend with a block holding a transfer and a
+// call to a contract, which touches the memory and the storage and calls into
+// another contract.
+func newCallTraceBackend(t *testing.T) *testBackend {
+	var (
+		accounts = newAccounts(2)
+		callee   = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		caller   = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				// The callee stores a word and returns.
+				callee: {Code: []byte{
+					byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP),
+				}},
+				// The caller writes the memory and the storage, calls the callee
+				// and returns a word.
+				caller: {Code: []byte{
+					byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x00, byte(vm.MSTORE),
+					byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+					byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.POP),
+					byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00,
+					byte(vm.PUSH2), 0xbb, 0xbb, byte(vm.GAS), byte(vm.CALL), byte(vm.POP),
+					byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+				}},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	return newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		tx, _ = types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID:    genesis.Config.ChainID,
+			Nonce:      b.TxNonce(accounts[0].addr),
+			GasTipCap:  common.Big0,
+			GasFeeCap:  b.BaseFee(),
+			Gas:        200000,
+			To:         &caller,
+			AccessList: types.AccessList{{Address: callee, StorageKeys: []common.Hash{{}}}},
+		}), signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+}
+
+func TestBlockTraceOutput(t *testing.T) {
+	t.Parallel()
+
+	backend := newCallTraceBackend(t)
+	defer backend.teardown()
+
+	var (
+		api     = NewAPI(backend)
+		fileAPI = NewBlockTraceFileAPI(backend)
+		handler = NewBlockTraceHandler(backend, nil)
+		logs    = &vm.LogConfig{EnableMemory: true, EnableReturnData: true}
+	)
+	plain, err := api.GetBlockTraceByNumberOrHash(context.Background(), rpc.BlockNumberOrHashWithNumber(1), &TraceConfig{LogConfig: logs})
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	want, err := json.Marshal(plain)
+	if err != nil {
+		t.Fatalf("failed to encode trace: %v", err)
+	}
+	// The traces decoded from every output format must match the JSON one.
+	var size int
+	for _, encoding := range []string{BlockTraceEncodingJSON, BlockTraceEncodingBinary} {
+		for _, compression := range []string{"", BlockTraceCompressionGzip, BlockTraceCompressionZstd} {
+			output := &BlockTraceOutput{Encoding: encoding, Compression: compression}
+			config := &TraceConfig{LogConfig: logs, BlockTraceOutput: output}
+			res := requestBlockTrace(t, handler, rpc.BlockNumberOrHashWithNumber(1), config)
+			if res.Code != http.StatusOK {
+				t.Fatalf("failed to trace block with output %+v: %d %s", output, res.Code, res.Body)
+			}
+			blob := res.Body.Bytes()
+			if encoding == BlockTraceEncodingBinary && compression == "" {
+				size = len(blob)
+			}
+			decoded, err := ReadBlockTrace(bytes.NewReader(blob), output)
+			if err != nil {
+				t.Fatalf("failed to decode trace with output %+v: %v", output, err)
+			}
+			if have, _ := json.Marshal(decoded); !bytes.Equal(have, want) {
+				t.Fatalf("trace mismatch with output %+v\nhave %s\nwant %s", output, have, want)
+			}
+			// The trace written to the file must match as well.
+			name, err := fileAPI.BlockTraceToFile(context.Background(), rpc.BlockNumberOrHashWithNumber(1), config)
+			if err != nil {
+				t.Fatalf("failed to write trace file with output %+v: %v", output, err)
+			}
+			file, err := os.Open(name)
+			if err != nil {
+				t.Fatalf("failed to open trace file: %v", err)
+			}
+			decoded, err = ReadBlockTrace(file, output)
+			file.Close()
+			os.Remove(name)
+			if err != nil {
+				t.Fatalf("failed to decode trace file with output %+v: %v", output, err)
+			}
+			if have, _ := json.Marshal(decoded); !bytes.Equal(have, want) {
+				t.Fatalf("trace file mismatch with output %+v", output)
+			}
+		}
+	}
+	if size >= len(want) {
+		t.Fatalf("binary trace not smaller than the JSON one: %d >= %d", size, len(want))
+	}
+	// The unknown formats are rejected, and the typed traces can't be encoded.
+	config := &TraceConfig{BlockTraceOutput: &BlockTraceOutput{Encoding: "xml"}}
+	if res := requestBlockTrace(t, handler, rpc.BlockNumberOrHashWithNumber(1), config); res.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status with unknown encoding: %d", res.Code)
+	}
+	config = &TraceConfig{BlockTraceOutput: &BlockTraceOutput{Encoding: BlockTraceEncodingBinary}}
+	if _, err := api.GetBlockTraceByNumberOrHash(context.Background(), rpc.BlockNumberOrHashWithNumber(1), config); err == nil {
+		t.Fatal("expected failure encoding the typed trace")
+	}
+}
+
+// requestBlockTrace requests the block trace from the handler.
+func requestBlockTrace(t *testing.T, handler http.Handler, block rpc.BlockNumberOrHash, config *TraceConfig) *httptest.ResponseRecorder {
+	body, err := json.Marshal(&blockTraceRequest{Block: block, Config: config})
+	if err != nil {
+		t.Fatalf("failed to encode request: %v", err)
+	}
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/blocktrace", bytes.NewReader(body)))
+	return res
+}
+
+// TestBlockTraceFileAPINamespace checks the file output is only registered in
+// the debug namespace, since the kroma namespace is public.
+func TestBlockTraceFileAPINamespace(t *testing.T) {
+	for _, api := range APIs(nil) {
+		if _, ok := api.Service.(*BlockTraceFileAPI); ok && (api.Namespace != "debug" || api.Public) {
+			t.Fatalf("block trace file output registered in %q namespace, public %v", api.Namespace, api.Public)
+		}
+		if _, ok := api.Service.(*API); !ok {
+			continue
+		}
+		if _, ok := reflect.TypeOf(api.Service).MethodByName("BlockTraceToFile"); ok {
+			t.Fatalf("block trace file output exposed in %q namespace", api.Namespace)
+		}
+	}
+}
+
+func TestBlockTraceProfiles(t *testing.T) {
+	t.Parallel()
+
+	backend := newCallTraceBackend(t)
+	defer backend.teardown()
+
+	api := NewAPI(backend)
+	trace := func(profile *string) *types.BlockTrace {
+		t.Helper()
+		config := &TraceConfig{LogConfig: &vm.LogConfig{EnableReturnData: true}, Profile: profile}
+		result, err := api.GetBlockTraceByNumberOrHash(context.Background(), rpc.BlockNumberOrHashWithNumber(1), config)
+		if err != nil {
+			t.Fatalf("failed to trace block with profile %v: %v", profile, err)
+		}
+		return result
+	}
+	var (
+		name       = BlockTraceProfileFull
+		storage    = BlockTraceProfileStorage
+		proofs     = BlockTraceProfileProofs
+		unknown    = "unknown"
+		full       = trace(nil)
+		withLogs   = trace(&storage)
+		withoutAny = trace(&proofs)
+	)
+	want, _ := json.Marshal(full)
+	if have, _ := json.Marshal(trace(&name)); !bytes.Equal(have, want) {
+		t.Fatal("full profile differs from the default one")
+	}
+	// The proofs and the account states are the same for every profile.
+	for name, trace := range map[string]*types.BlockTrace{storage: withLogs, proofs: withoutAny} {
+		if !reflect.DeepEqual(trace.StorageTrace, full.StorageTrace) {
+			t.Errorf("%s: storage trace mismatch", name)
+		}
+		for i, result := range trace.ExecutionResults {
+			want := full.ExecutionResults[i]
+			if !reflect.DeepEqual(result.From, want.From) || !reflect.DeepEqual(result.To, want.To) || !reflect.DeepEqual(result.AccountsAfter, want.AccountsAfter) {
+				t.Errorf("%s: account states of tx %d mismatch", name, i)
+			}
+			if result.Gas != want.Gas || result.ReturnValue != want.ReturnValue {
+				t.Errorf("%s: result of tx %d mismatch", name, i)
+			}
+		}
+	}
+	// The storage profile drops the codes and the callers from the extra data.
+	var codes, callers, states int
+	for i, result := range withLogs.ExecutionResults {
+		if len(result.StructLogs) != len(full.ExecutionResults[i].StructLogs) {
+			t.Fatalf("storage: struct logs of tx %d mismatch", i)
+		}
+		for _, log := range result.StructLogs {
+			if log.ExtraData != nil {
+				codes += len(log.ExtraData.CodeList)
+				callers += len(log.ExtraData.Caller)
+				states += len(log.ExtraData.StateList)
+			}
+		}
+	}
+	if codes != 0 || callers != 0 || states == 0 {
+		t.Fatalf("storage: unexpected extra data, codes %d callers %d states %d", codes, callers, states)
+	}
+	// The proofs profile drops the struct logs entirely.
+	for i, result := range withoutAny.ExecutionResults {
+		if len(result.StructLogs) != 0 {
+			t.Fatalf("proofs: struct logs of tx %d produced", i)
+		}
+	}
+	// The unknown profiles and the custom tracers are rejected.
+	for _, config := range []*TraceConfig{{Profile: &unknown}, {Tracer: &unknown}} {
+		if _, err := api.GetBlockTraceByNumberOrHash(context.Background(), rpc.BlockNumberOrHashWithNumber(1), config); err == nil {
+			t.Fatalf("expected failure with config %+v", config)
+		}
+	}
+}
+
+func TestBlockTraceMPTStorageTrace(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(3)
+		contract = common.HexToAddress("0x000000000000000000000000000000000000dddd")
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				// The coinbase must exist before the block for its reward to be
+				// replayed.
+				accounts[2].addr: {Balance: big.NewInt(params.Ether)},
+				// The contract clears the slot 1, which collapses the root of its
+				// storage trie onto the untouched slot 0, writes the slot 3 and reads
+				// the absent slot 9.
+				contract: {
+					Code: []byte{
+						byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x01, byte(vm.SSTORE),
+						byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x03, byte(vm.SSTORE),
+						byte(vm.PUSH1), 0x09, byte(vm.SLOAD), byte(vm.POP), byte(vm.STOP),
+					},
+					Storage: map[common.Hash]common.Hash{
+						common.BigToHash(big.NewInt(0)): common.BigToHash(big.NewInt(1)),
+						common.BigToHash(big.NewInt(1)): common.BigToHash(big.NewInt(1)),
+					},
+				},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(accounts[2].addr)
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+		tx, _ = types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), contract, common.Big0, 100000, b.BaseFee(), nil), signer, accounts[0].key)
+		b.AddTx(tx)
+	})
+	defer backend.teardown()
+
+	api := NewAPI(backend)
+	trace := func() *types.BlockTrace {
+		t.Helper()
+		result, err := api.GetBlockTraceByNumberOrHash(context.Background(), rpc.BlockNumberOrHashWithNumber(1), nil)
+		if err != nil {
+			t.Fatalf("failed to trace block: %v", err)
+		}
+		return result
+	}
+	// The sibling collapsed on the deletion is only needed for the updates.
+	blockTrace := trace()
+	// The block reward of ethash is paid to the coinbase outside the transactions.
+	reward := new(big.Int).Add(blockTrace.Coinbase.Balance.ToInt(), ethash.ConstantinopleBlockReward)
+	blockTrace.Coinbase.Balance = (*hexutil.Big)(reward)
+	if len(blockTrace.StorageTrace.DeletionProofs) == 0 {
+		t.Fatal("no deletion proofs produced")
+	}
+	if blockTrace.MPTWitness != nil {
+		t.Fatal("mpt storage trace produced without the witness enabled")
+	}
+	for _, scheme := range []zkproof.MPTWitnessType{zkproof.MPTWitnessRWTbl, zkproof.MPTWitnessNatural} {
+		if _, err := zkproof.HandleBlockTraceMPT(blockTrace, scheme); err != nil {
+			t.Fatalf("failed to replay the updates with scheme %d: %v", scheme, err)
+		}
+	}
+	withoutDeletion := *blockTrace.StorageTrace
+	withoutDeletion.DeletionProofs = nil
+	if _, err := zkproof.HandleBlockTraceMPT(&types.BlockTrace{StorageTrace: &withoutDeletion, Coinbase: blockTrace.Coinbase, ExecutionResults: blockTrace.ExecutionResults}, zkproof.MPTWitnessRWTbl); err == nil {
+		t.Fatal("expected failure replaying the updates without the deletion proofs")
+	}
+
+	// The ordered updates are encoded in the witness of the block trace.
+	if err := zkproof.FillBlockTraceForMPTStorageTrace(zkproof.MPTWitnessRWTbl, blockTrace); err != nil {
+		t.Fatalf("failed to fill mpt storage trace: %v", err)
+	}
+	var updates []*zkproof.MPTStorageTrace
+	if err := json.Unmarshal(*blockTrace.MPTWitness, &updates); err != nil {
+		t.Fatalf("failed to decode mpt storage trace: %v", err)
+	}
+	var deleted, written, read bool
+	for i, update := range updates {
+		if i > 0 && !bytes.Equal(update.AccountPath[0].Root, updates[i-1].AccountPath[1].Root) {
+			t.Fatalf("update %d doesn't start from the root the previous one ends with", i)
+		}
+		before, after := update.StateUpdate[0], update.StateUpdate[1]
+		switch {
+		case before != nil && after == nil:
+			deleted = deleted || common.BytesToHash(before.Key) == common.BigToHash(big.NewInt(1))
+		case before == nil && after != nil:
+			written = written || common.BytesToHash(after.Key) == common.BigToHash(big.NewInt(3))
+		case before != nil && bytes.Equal(before.Key, after.Key) && bytes.Equal(before.Value, after.Value):
+			read = read || common.BytesToHash(before.Key) == common.BigToHash(big.NewInt(9))
+		}
+	}
+	if !deleted || !written || !read {
+		t.Fatalf("storage updates missing: deleted %v, written %v, read %v", deleted, written, read)
+	}
+	if root := common.BytesToHash(updates[len(updates)-1].AccountPath[1].Root); root != blockTrace.StorageTrace.RootAfter {
+		t.Fatalf("root mismatch: have %x, want %x", root, blockTrace.StorageTrace.RootAfter)
+	}
+}
+
+func TestBlockTraceMPTStorageTraceRewrite(t *testing.T) {
+	t.Parallel()
+
+	var (
+		accounts = newAccounts(2)
+		contract = common.HexToAddress("0x000000000000000000000000000000000000dddd")
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+				accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+				// The contract stores the calldata word at the slot 1, whose
+				// clearing collapses the root of its storage trie onto the slot 0.
+				contract: {
+					Code: []byte{
+						byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0x01, byte(vm.SSTORE), byte(vm.STOP),
+					},
+					Storage: map[common.Hash]common.Hash{
+						common.BigToHash(big.NewInt(0)): common.BigToHash(big.NewInt(1)),
+						common.BigToHash(big.NewInt(1)): common.BigToHash(big.NewInt(1)),
+					},
+				},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	// The slot 1 is cleared and written again within the block, so that it's
+	// only deleted by the updates in the natural order.
+	backend := newTestBackend(t, 1, genesis, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(accounts[1].addr)
+		for _, value := range []common.Hash{{}, common.BigToHash(big.NewInt(2))} {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), contract, common.Big0, 100000, b.BaseFee(), value.Bytes()), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	})
+	defer backend.teardown()
+
+	blockTrace, err := NewAPI(backend).GetBlockTraceByNumberOrHash(context.Background(), rpc.BlockNumberOrHashWithNumber(1), nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(blockTrace.StorageTrace.DeletionProofs) == 0 {
+		t.Fatal("no deletion proofs produced")
+	}
+	// The block reward of ethash is paid to the coinbase outside the transactions.
+	reward := new(big.Int).Add(blockTrace.Coinbase.Balance.ToInt(), ethash.ConstantinopleBlockReward)
+	blockTrace.Coinbase.Balance = (*hexutil.Big)(reward)
+	for _, scheme := range []zkproof.MPTWitnessType{zkproof.MPTWitnessNatural, zkproof.MPTWitnessRWTbl} {
+		if _, err := zkproof.HandleBlockTraceMPT(blockTrace, scheme); err != nil {
+			t.Fatalf("failed to replay the updates with scheme %d: %v", scheme, err)
+		}
+	}
+}
@@ -45,6 +45,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
@@ -175,8 +176,13 @@ func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*type
 }
 
 func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
-	if number == rpc.PendingBlockNumber || number == rpc.LatestBlockNumber {
+	switch number {
+	case rpc.PendingBlockNumber, rpc.LatestBlockNumber:
 		return b.chain.CurrentHeader(), nil
+	case rpc.SafeBlockNumber:
+		return b.chain.CurrentSafeBlock(), nil
+	case rpc.FinalizedBlockNumber:
+		return b.chain.CurrentFinalBlock(), nil
 	}
 	return b.chain.GetHeaderByNumber(uint64(number)), nil
 }
@@ -274,6 +280,10 @@ func (b *testBackend) StateAtTransaction(ctx context.Context, block *types.Block
 	return nil, vm.BlockContext{}, nil, nil, fmt.Errorf("transaction index %d out of range for block %#x", txIndex, block.Hash())
 }
 
+func (b *testBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.chain.SubscribeChainHeadEvent(ch)
+}
+
 func (b *testBackend) HistoricalRPCService() *rpc.Client {
 	return b.historical
 }
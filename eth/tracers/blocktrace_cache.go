@@ -0,0 +1,254 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	blockTraceCacheHitMeter  = metrics.NewRegisteredMeter("kroma/tracecache/hit", nil)
+	blockTraceCacheMissMeter = metrics.NewRegisteredMeter("kroma/tracecache/miss", nil)
+	blockTraceCacheSizeGauge = metrics.NewRegisteredGauge("kroma/tracecache/size", nil)
+)
+
+// BlockTraceCacheConfig contains the settings of the block trace cache.
+type BlockTraceCacheConfig struct {
+	Retention uint64 // Number of recent blocks whose traces are retained, zero to keep all
+	Size      uint64 // Maximum total size of the stored traces in bytes, zero for unlimited
+	OnImport  bool   // Whether to generate the traces of the blocks as they are imported
+}
+
+// BlockTraceCache stores the block traces on disk, so that the repeated requests
+// for the same block are served without re-executing it. Only the traces generated
+// with the default trace config are stored.
+type BlockTraceCache struct {
+	api    *API
+	db     ethdb.Database
+	config BlockTraceCacheConfig
+
+	lock sync.Mutex
+	size uint64 // Total size of the stored traces
+	last uint64 // Number of the last block traced on import
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewBlockTraceCache creates the block trace cache on top of the chain database
+// of the given backend.
+func NewBlockTraceCache(backend Backend, config BlockTraceCacheConfig) *BlockTraceCache {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &BlockTraceCache{
+		db:     backend.ChainDb(),
+		config: config,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	c.api = &API{backend: backend, traceCache: c}
+
+	var count int
+	rawdb.IterateBlockTraces(c.db, func(number uint64, hash common.Hash, size int) bool {
+		c.size += uint64(size)
+		count++
+		return true
+	})
+	blockTraceCacheSizeGauge.Update(int64(c.size))
+	log.Info("Opened block trace cache", "traces", count, "size", common.StorageSize(c.size), "retention", config.Retention, "onimport", config.OnImport)
+	return c
+}
+
+// Start implements node.Lifecycle, starting the generation of the traces of the
+// imported blocks if enabled.
+func (c *BlockTraceCache) Start() error {
+	if c.config.OnImport {
+		heads := make(chan core.ChainHeadEvent, 16)
+		sub := c.api.backend.SubscribeChainHeadEvent(heads)
+
+		c.wg.Add(1)
+		go c.loop(heads, sub)
+	}
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the trace generation.
+func (c *BlockTraceCache) Stop() error {
+	c.cancel()
+	c.wg.Wait()
+	return nil
+}
+
+// get retrieves the stored trace of the given block, or nil if it's not stored.
+func (c *BlockTraceCache) get(block *types.Block) *types.BlockTrace {
+	blob := rawdb.ReadBlockTrace(c.db, block.Hash(), block.NumberU64())
+	if len(blob) == 0 {
+		blockTraceCacheMissMeter.Mark(1)
+		return nil
+	}
+	trace := new(types.BlockTrace)
+	if err := json.Unmarshal(blob, trace); err != nil {
+		log.Warn("Failed to decode stored block trace", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+		blockTraceCacheMissMeter.Mark(1)
+		return nil
+	}
+	blockTraceCacheHitMeter.Mark(1)
+	return trace
+}
+
+// put stores the trace of the given block, pruning the stale traces afterwards.
+func (c *BlockTraceCache) put(ctx context.Context, block *types.Block, trace *types.BlockTrace) {
+	head, err := c.api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil || head == nil {
+		return
+	}
+	threshold := c.threshold(head.Number.Uint64())
+	if block.NumberU64() < threshold {
+		return
+	}
+	blob, err := json.Marshal(trace)
+	if err != nil {
+		log.Warn("Failed to encode block trace", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if rawdb.HasBlockTrace(c.db, block.Hash(), block.NumberU64()) {
+		return
+	}
+	rawdb.WriteBlockTrace(c.db, block.Hash(), block.NumberU64(), blob)
+	c.size += uint64(len(blob))
+	c.prune(threshold)
+}
+
+// threshold returns the lowest block number whose trace is retained.
+func (c *BlockTraceCache) threshold(head uint64) uint64 {
+	if c.config.Retention == 0 || head < c.config.Retention {
+		return 0
+	}
+	return head - c.config.Retention + 1
+}
+
+// prune deletes the traces of the blocks below the threshold, and then the oldest
+// ones until the total size fits in the limit. It assumes the lock is held.
+func (c *BlockTraceCache) prune(threshold uint64) {
+	batch := c.db.NewBatch()
+	rawdb.IterateBlockTraces(c.db, func(number uint64, hash common.Hash, size int) bool {
+		if number >= threshold && (c.config.Size == 0 || c.size <= c.config.Size) {
+			return false
+		}
+		rawdb.DeleteBlockTrace(batch, hash, number)
+		c.size -= uint64(size)
+		return true
+	})
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to prune block traces", "err", err)
+	}
+	blockTraceCacheSizeGauge.Update(int64(c.size))
+}
+
+// loop generates the traces of the new canonical blocks. The chain head events
+// received during the generation are coalesced, so that the block import is not
+// blocked by the slow tracing.
+func (c *BlockTraceCache) loop(heads chan core.ChainHeadEvent, sub event.Subscription) {
+	defer c.wg.Done()
+	defer sub.Unsubscribe()
+
+	var (
+		pending *types.Block  // Latest head waiting to be traced
+		done    chan struct{} // Non-nil if the generation is running
+	)
+	generate := func(head *types.Block) {
+		done = make(chan struct{})
+		go func() {
+			defer close(done)
+			c.generate(head)
+		}()
+	}
+	for {
+		select {
+		case ev := <-heads:
+			if done == nil {
+				generate(ev.Block)
+			} else {
+				pending = ev.Block
+			}
+		case <-done:
+			done = nil
+			if pending != nil {
+				generate(pending)
+				pending = nil
+			}
+		case <-sub.Err():
+			if done != nil {
+				<-done
+			}
+			return
+		case <-c.ctx.Done():
+			if done != nil {
+				<-done
+			}
+			return
+		}
+	}
+}
+
+// generate traces the canonical blocks from the last traced one up to the given
+// head, skipping the ones already stored.
+func (c *BlockTraceCache) generate(head *types.Block) {
+	number := head.NumberU64()
+	if number == 0 {
+		return
+	}
+	// Start with the head itself if nothing was traced yet or the chain was
+	// rewound, and never fall behind by too many blocks.
+	from := c.last + 1
+	if c.last == 0 || from > number {
+		from = number
+	}
+	if number-from >= maxBlockTraceLag {
+		from = number - maxBlockTraceLag + 1
+	}
+	for ; from <= number; from++ {
+		header, err := c.api.backend.HeaderByNumber(c.ctx, rpc.BlockNumber(from))
+		if err != nil || header == nil {
+			return
+		}
+		if !rawdb.HasBlockTrace(c.db, header.Hash(), from) {
+			if _, err := c.api.blockTrace(c.ctx, rpc.BlockNumberOrHashWithHash(header.Hash(), false), nil); err != nil {
+				if c.ctx.Err() == nil {
+					log.Warn("Failed to generate block trace", "number", from, "hash", header.Hash(), "err", err)
+				}
+				return
+			}
+		}
+		c.last = from
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// BlockTraceEncodingJSON is the JSON encoding of the block traces, which is
+	// the default one.
+	BlockTraceEncodingJSON = "json"
+	// BlockTraceEncodingBinary is the compact binary encoding of the block
+	// traces, see types.EncodeBlockTrace.
+	BlockTraceEncodingBinary = "binary"
+
+	// BlockTraceCompressionGzip frames the encoded block traces with gzip.
+	BlockTraceCompressionGzip = "gzip"
+	// BlockTraceCompressionZstd frames the encoded block traces with zstd.
+	BlockTraceCompressionZstd = "zstd"
+)
+
+// BlockTraceOutput selects how the block traces are encoded by the block trace
+// HTTP handler and debug_blockTraceToFile.
+type BlockTraceOutput struct {
+	Encoding    string `json:"encoding,omitempty"`    // Encoding of the trace, json or binary
+	Compression string `json:"compression,omitempty"` // Optional framing of the encoded trace, gzip or zstd
+}
+
+// validate checks that the encoding and the compression are known.
+func (o *BlockTraceOutput) validate() error {
+	switch o.Encoding {
+	case "", BlockTraceEncodingJSON, BlockTraceEncodingBinary:
+	default:
+		return fmt.Errorf("unknown block trace encoding %q", o.Encoding)
+	}
+	switch o.Compression {
+	case "", BlockTraceCompressionGzip, BlockTraceCompressionZstd:
+	default:
+		return fmt.Errorf("unknown block trace compression %q", o.Compression)
+	}
+	return nil
+}
+
+// isDefault returns whether the trace is returned as a plain JSON object.
+func (o *BlockTraceOutput) isDefault() bool {
+	return o == nil || ((o.Encoding == "" || o.Encoding == BlockTraceEncodingJSON) && o.Compression == "")
+}
+
+// fileSuffix returns the suffix of the files the traces are written to.
+func (o *BlockTraceOutput) fileSuffix() string {
+	suffix := ".json"
+	if o.Encoding == BlockTraceEncodingBinary {
+		suffix = ".bin"
+	}
+	switch o.Compression {
+	case BlockTraceCompressionGzip:
+		suffix += ".gz"
+	case BlockTraceCompressionZstd:
+		suffix += ".zst"
+	}
+	return suffix
+}
+
+// WriteBlockTrace encodes the block trace into w with the given output format.
+func WriteBlockTrace(w io.Writer, trace *types.BlockTrace, output *BlockTraceOutput) error {
+	if output == nil {
+		output = new(BlockTraceOutput)
+	}
+	if err := output.validate(); err != nil {
+		return err
+	}
+	var compressor io.WriteCloser
+	switch output.Compression {
+	case BlockTraceCompressionGzip:
+		compressor = gzip.NewWriter(w)
+	case BlockTraceCompressionZstd:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return err
+		}
+		compressor = zw
+	}
+	if compressor != nil {
+		w = compressor
+	}
+	var err error
+	if output.Encoding == BlockTraceEncodingBinary {
+		err = types.EncodeBlockTrace(w, trace)
+	} else {
+		err = json.NewEncoder(w).Encode(trace)
+	}
+	if compressor != nil {
+		if cerr := compressor.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// ReadBlockTrace decodes a block trace written with the given output format
+// from r.
+func ReadBlockTrace(r io.Reader, output *BlockTraceOutput) (*types.BlockTrace, error) {
+	if output == nil {
+		output = new(BlockTraceOutput)
+	}
+	if err := output.validate(); err != nil {
+		return nil, err
+	}
+	switch output.Compression {
+	case BlockTraceCompressionGzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	case BlockTraceCompressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	if output.Encoding == BlockTraceEncodingBinary {
+		return types.DecodeBlockTrace(r)
+	}
+	trace := new(types.BlockTrace)
+	if err := json.NewDecoder(r).Decode(trace); err != nil {
+		return nil, err
+	}
+	return trace, nil
+}
+
+// BlockTraceFileAPI provides the block trace file output. It's only registered
+// in the debug namespace, since it writes to the local disk of the node.
+type BlockTraceFileAPI struct {
+	api *API
+}
+
+// NewBlockTraceFileAPI creates a new API definition for the block trace file
+// output.
+func NewBlockTraceFileAPI(backend Backend) *BlockTraceFileAPI {
+	return &BlockTraceFileAPI{api: NewAPI(backend)}
+}
+
+// BlockTraceToFile replays the block and writes its trace into a temporary file
+// with the output format of the config, returning the name of the file.
+func (api *BlockTraceFileAPI) BlockTraceToFile(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash, config *TraceConfig) (string, error) {
+	output, err := blockTraceOutput(config)
+	if err != nil {
+		return "", err
+	}
+	trace, err := api.api.blockTrace(ctx, blockNrOrHash, config)
+	if err != nil {
+		return "", err
+	}
+	return writeBlockTraceToFile(trace, output)
+}
+
+// blockTraceRequest is the body of the requests to the block trace handler,
+// holding the same parameters as kroma_getBlockTraceByNumberOrHash.
+type blockTraceRequest struct {
+	Block  rpc.BlockNumberOrHash `json:"block"`
+	Config *TraceConfig          `json:"config"`
+}
+
+// maxBlockTraceRequestSize is the limit of the body of the requests to the block
+// trace handler.
+const maxBlockTraceRequestSize = 64 * 1024
+
+// BlockTraceHandler serves the block traces over HTTP, writing the trace encoded
+// in the output format of the config straight into the response, so that the
+// large traces are neither buffered by the node nor wrapped in JSON-RPC.
+type BlockTraceHandler struct {
+	api *API
+}
+
+// NewBlockTraceHandler creates the HTTP handler of the block traces, serving them
+// from the given cache if not nil.
+func NewBlockTraceHandler(backend Backend, traceCache *BlockTraceCache) *BlockTraceHandler {
+	return &BlockTraceHandler{api: &API{backend: backend, traceCache: traceCache}}
+}
+
+// ServeHTTP replays the block of the request and streams its trace.
+func (h *BlockTraceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req blockTraceRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBlockTraceRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	output, err := blockTraceOutput(req.Config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	trace, err := h.api.blockTrace(r.Context(), req.Block, req.Config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if output.isDefault() {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	buf := bufio.NewWriter(w)
+	if err = WriteBlockTrace(buf, trace, output); err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		// The status is already sent, so the response is cut off for the client
+		// to notice the truncated trace.
+		log.Debug("Failed to stream block trace", "err", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// blockTraceOutput returns the validated output format of the config.
+func blockTraceOutput(config *TraceConfig) (*BlockTraceOutput, error) {
+	if config == nil || config.BlockTraceOutput == nil {
+		return new(BlockTraceOutput), nil
+	}
+	if err := config.BlockTraceOutput.validate(); err != nil {
+		return nil, err
+	}
+	return config.BlockTraceOutput, nil
+}
+
+// writeBlockTraceToFile streams the block trace into a temporary file, and
+// returns the name of the file.
+func writeBlockTraceToFile(trace *types.BlockTrace, output *BlockTraceOutput) (string, error) {
+	prefix := "blocktrace_"
+	if trace.Header != nil {
+		prefix = fmt.Sprintf("blocktrace_%d-%#x-", trace.Header.Number, trace.Header.Hash().Bytes()[:4])
+	}
+	dump, err := os.CreateTemp(os.TempDir(), prefix+"*"+output.fileSuffix())
+	if err != nil {
+		return "", err
+	}
+	buf := bufio.NewWriter(dump)
+	if err = WriteBlockTrace(buf, trace, output); err == nil {
+		err = buf.Flush()
+	}
+	if cerr := dump.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dump.Name())
+		return "", err
+	}
+	log.Info("Wrote block trace", "file", dump.Name())
+	return dump.Name(), nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+const (
+	// BlockTraceProfileFull produces the whole block traces, which is the
+	// default profile.
+	BlockTraceProfileFull = "full"
+	// BlockTraceProfileStorage produces the struct logs with the account and
+	// storage states in the extra data, but no codes, callers or MPT witness.
+	BlockTraceProfileStorage = "storage"
+	// BlockTraceProfileProofs produces no struct logs at all, only the proofs of
+	// the touched accounts and storages along with the account states before and
+	// after each transaction.
+	BlockTraceProfileProofs = "proofs"
+)
+
+// blockTraceProfile selects the parts of the block traces which are produced.
+type blockTraceProfile struct {
+	StructLogs bool // Whether the struct logs are produced
+	CodeList   bool // Whether ExtraData.CodeList of the struct logs is produced
+	StateList  bool // Whether ExtraData.StateList of the struct logs is produced
+	Caller     bool // Whether ExtraData.Caller of the struct logs is produced
+	MPTWitness bool // Whether the MPT witness is produced, which needs the full struct logs
+}
+
+var blockTraceProfiles = map[string]*blockTraceProfile{
+	BlockTraceProfileFull:    {StructLogs: true, CodeList: true, StateList: true, Caller: true, MPTWitness: true},
+	BlockTraceProfileStorage: {StructLogs: true, StateList: true},
+	BlockTraceProfileProofs:  {},
+}
+
+// lookupBlockTraceProfile returns the block trace profile with the given name,
+// the full one if nil.
+func lookupBlockTraceProfile(name *string) (*blockTraceProfile, error) {
+	if name == nil {
+		return blockTraceProfiles[BlockTraceProfileFull], nil
+	}
+	profile, ok := blockTraceProfiles[*name]
+	if !ok {
+		return nil, fmt.Errorf("unknown block trace profile %q", *name)
+	}
+	return profile, nil
+}
+
+// logConfig returns the struct logger configuration capturing the parts of the
+// struct logs selected by the profile on top of the given one.
+func (p *blockTraceProfile) logConfig(config *vm.LogConfig) *vm.LogConfig {
+	var cfg vm.LogConfig
+	if config != nil {
+		cfg = *config
+	}
+	cfg.DisableStructLogs = !p.StructLogs
+	cfg.DisableCodeList = !p.CodeList
+	cfg.DisableStateList = !p.StateList
+	cfg.DisableCaller = !p.Caller
+	return &cfg
+}
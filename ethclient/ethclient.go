@@ -361,6 +361,8 @@ func (ec *Client) GetBlockTraceByNumber(ctx context.Context, number *big.Int) (*
 }
 
 // SubscribeNewBlockTrace subscribes to block execution trace when a new block is created.
+// For the blocks reverted by a reorg, a trace holding only the header is sent with
+// the Removed flag set.
 func (ec *Client) SubscribeNewBlockTrace(ctx context.Context, ch chan<- *types.BlockTrace) (ethereum.Subscription, error) {
 	return ec.c.EthSubscribe(ctx, ch, "newBlockTrace")
 }
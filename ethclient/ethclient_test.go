@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
@@ -219,17 +220,62 @@ var testTx2 = types.MustSignNewTx(testKey, types.LatestSigner(genesis.Config), &
 	To:       &common.Address{2},
 })
 
-func newTestBackend(t *testing.T, enableHistoricalState bool) (*node.Node, []*types.Block) {
+// genesisForHistorical is the genesis of the chain whose blocks before bedrock are
+// served by the historical backend.
+var genesisForHistorical = &core.Genesis{
+	Config:    params.KromaTestConfig,
+	Alloc:     core.GenesisAlloc{testAddr: {Balance: testBalance}},
+	ExtraData: []byte("test genesis"),
+	Timestamp: 9000,
+	BaseFee:   big.NewInt(params.InitialBaseFee),
+}
+
+// historicalReceipt is the receipt served by the historical backend, to tell the
+// proxied receipts apart from those of the local chain.
+var historicalReceipt = &types.Receipt{
+	Status:            types.ReceiptStatusSuccessful,
+	CumulativeGasUsed: params.TxGas,
+	Logs:              []*types.Log{},
+	TxHash:            common.HexToHash("0x1234"),
+	GasUsed:           params.TxGas,
+	BlockHash:         common.HexToHash("0x5678"),
+	BlockNumber:       big.NewInt(1),
+}
+
+// mockHistoricalBackend is the stand-in historical RPC serving the pre-bedrock
+// blocks.
+type mockHistoricalBackend struct{}
+
+func (m *mockHistoricalBackend) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	return []*types.Receipt{historicalReceipt}, nil
+}
+
+func newMockHistoricalBackend(t *testing.T) string {
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", new(mockHistoricalBackend)); err != nil {
+		t.Fatalf("can't register historical backend: %v", err)
+	}
+	httpsrv := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpsrv.Close()
+		server.Stop()
+	})
+	return httpsrv.URL
+}
+
+// newTestBackend creates the node serving the test chain. With the historical
+// state enabled, the chain is made of the pre-bedrock blocks, which are forwarded
+// to the given historical RPC if any.
+func newTestBackend(t *testing.T, enableHistoricalState bool, historicalRPC string) (*node.Node, []*types.Block) {
 	var consensusEngine consensus.Engine
 	var actualGenesis *core.Genesis
 	var chainLength int
 	if enableHistoricalState {
-		t.Fatal("kroma does not support enableHistoricalState")
-		/* [kroma unsupported]
+		// The blocks before bedrock are generated with the proof-of-work, as kroma
+		// verifies them with the pre-bedrock engine.
 		actualGenesis = genesisForHistorical
-		consensusEngine = beacon.New(ethash.NewFaker())
-		chainLength = 10
-		*/
+		consensusEngine = ethash.NewFaker()
+		chainLength = 2
 	} else {
 		actualGenesis = genesis
 		consensusEngine = ethash.NewFaker()
@@ -245,7 +291,11 @@ func newTestBackend(t *testing.T, enableHistoricalState bool) (*node.Node, []*ty
 		t.Fatalf("can't create new node: %v", err)
 	}
 	// Create Ethereum Service
-	config := &ethconfig.Config{Genesis: genesis}
+	config := &ethconfig.Config{Genesis: actualGenesis}
+	if historicalRPC != "" {
+		config.RollupHistoricalRPC = historicalRPC
+		config.RollupHistoricalRPCTimeout = 5 * time.Second
+	}
 	ethservice, err := eth.New(n, config)
 	if err != nil {
 		t.Fatalf("can't create new ethereum service: %v", err)
@@ -285,10 +335,8 @@ func generateTestChain(consensusEngine consensus.Engine, genesis *core.Genesis,
 	return append([]*types.Block{genesis.ToBlock()}, blocks...)
 }
 
-// [kroma unsupported] remove TestEthClientHistoricalBackend
-
 func TestEthClient(t *testing.T) {
-	backend, chain := newTestBackend(t, false)
+	backend, chain := newTestBackend(t, false, "")
 	client := backend.Attach()
 	defer backend.Close()
 	defer client.Close()
@@ -329,6 +377,9 @@ func TestEthClient(t *testing.T) {
 		"EstimateGas": {
 			func(t *testing.T) { testEstimateGas(t, client) },
 		},
+		"BlockReceipts": {
+			func(t *testing.T) { testBlockReceipts(t, chain, client) },
+		},
 	}
 
 	t.Parallel()
@@ -377,6 +428,95 @@ func testHeader(t *testing.T, chain []*types.Block, client *rpc.Client) {
 	}
 }
 
+func testBlockReceipts(t *testing.T, chain []*types.Block, client *rpc.Client) {
+	ec := NewClient(client)
+	block := chain[2]
+
+	tests := map[string]struct {
+		blockNrOrHash rpc.BlockNumberOrHash
+		wantErr       error
+	}{
+		"by_number": {
+			blockNrOrHash: rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(block.NumberU64())),
+		},
+		"by_hash": {
+			blockNrOrHash: rpc.BlockNumberOrHashWithHash(block.Hash(), false),
+		},
+		"future_block": {
+			blockNrOrHash: rpc.BlockNumberOrHashWithNumber(1000000000),
+			wantErr:       ethereum.NotFound,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			receipts, err := ec.BlockReceipts(context.Background(), tt.blockNrOrHash)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BlockReceipts(%v) error = %q, want %q", tt.blockNrOrHash, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			txs := block.Transactions()
+			if len(receipts) != len(txs) {
+				t.Fatalf("receipt count mismatch: have %d, want %d", len(receipts), len(txs))
+			}
+			for i, receipt := range receipts {
+				if receipt.TxHash != txs[i].Hash() {
+					t.Errorf("receipt %d: tx hash mismatch: have %x, want %x", i, receipt.TxHash, txs[i].Hash())
+				}
+				if receipt.BlockHash != block.Hash() {
+					t.Errorf("receipt %d: block hash mismatch: have %x, want %x", i, receipt.BlockHash, block.Hash())
+				}
+				if receipt.Status != types.ReceiptStatusSuccessful {
+					t.Errorf("receipt %d: unexpected status %d", i, receipt.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestBlockReceiptsHistoricalBackend(t *testing.T) {
+	// The receipts of the pre-bedrock blocks are proxied from the historical backend.
+	backend, chain := newTestBackend(t, true, newMockHistoricalBackend(t))
+	client := backend.Attach()
+	defer backend.Close()
+	defer client.Close()
+
+	ec := NewClient(client)
+	block := chain[2]
+	for _, blockNrOrHash := range []rpc.BlockNumberOrHash{
+		rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(block.NumberU64())),
+		rpc.BlockNumberOrHashWithHash(block.Hash(), false),
+	} {
+		receipts, err := ec.BlockReceipts(context.Background(), blockNrOrHash)
+		if err != nil {
+			t.Fatalf("BlockReceipts(%v) error = %q", blockNrOrHash, err)
+		}
+		if len(receipts) != 1 || receipts[0].TxHash != historicalReceipt.TxHash || receipts[0].BlockHash != historicalReceipt.BlockHash {
+			t.Fatalf("BlockReceipts(%v) not proxied from historical backend: %v", blockNrOrHash, receipts)
+		}
+	}
+}
+
+func TestBlockReceiptsNoHistoricalBackend(t *testing.T) {
+	// Without the historical backend, the receipts of the pre-bedrock blocks are
+	// not available.
+	backend, chain := newTestBackend(t, true, "")
+	client := backend.Attach()
+	defer backend.Close()
+	defer client.Close()
+
+	ec := NewClient(client)
+	blockNrOrHash := rpc.BlockNumberOrHashWithHash(chain[2].Hash(), false)
+	receipts, err := ec.BlockReceipts(context.Background(), blockNrOrHash)
+	if err == nil || err.Error() != rpc.ErrNoHistoricalFallback.Error() {
+		t.Fatalf("BlockReceipts(%v) error = %q, want %q", blockNrOrHash, err, rpc.ErrNoHistoricalFallback)
+	}
+	if receipts != nil {
+		t.Fatalf("BlockReceipts(%v) = %v, want nil", blockNrOrHash, receipts)
+	}
+}
+
 func testBalanceAt(t *testing.T, client *rpc.Client) {
 	tests := map[string]struct {
 		account common.Address
@@ -0,0 +1,213 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gethclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	zktrie "github.com/kroma-network/zktrie/trie"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// VerifiedAccount is an account whose values are proven against the state root
+// of a block.
+type VerifiedAccount struct {
+	Address     common.Address
+	Exists      bool // Whether the account is in the state trie
+	Nonce       uint64
+	Balance     *big.Int
+	CodeHash    common.Hash
+	StorageHash common.Hash
+	Storage     []VerifiedStorage
+	Zktrie      bool // Whether the proofs are verified against the zk trie
+}
+
+// VerifiedStorage is a storage slot whose value is proven against the storage
+// root of its account.
+type VerifiedStorage struct {
+	Key    common.Hash
+	Exists bool // Whether the slot is in the storage trie
+	Value  *big.Int
+}
+
+// GetVerifiedProof retrieves the proof of the account and the given storage keys
+// at the given block, and verifies it against the state root of the header.
+func (ec *Client) GetVerifiedProof(ctx context.Context, config *params.ChainConfig, header *types.Header, account common.Address, keys []string) (*VerifiedAccount, error) {
+	result, err := ec.GetProof(ctx, account, keys, header.Number)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyProof(config, header, result)
+}
+
+// VerifyProof verifies the account and storage proofs of a GetProof result
+// against the state root of the header. The proofs are verified on the zk trie
+// for the blocks before the KromaMPT fork of zk trie chains, and on the MPT
+// otherwise.
+//
+// An error is returned if any proof is invalid, or doesn't prove the values of
+// the result.
+func VerifyProof(config *params.ChainConfig, header *types.Header, result *AccountResult) (*VerifiedAccount, error) {
+	zk := config.IsZkState(header.Time)
+
+	account, err := verifyAccountProof(zk, header.Root, result)
+	if err != nil {
+		return nil, err
+	}
+	for _, storage := range result.StorageProof {
+		slot, err := verifyStorageProof(zk, account.StorageHash, storage)
+		if err != nil {
+			return nil, fmt.Errorf("storage %s of account %x: %w", storage.Key, result.Address, err)
+		}
+		account.Storage = append(account.Storage, *slot)
+	}
+	return account, nil
+}
+
+// verifyAccountProof verifies the account proof of the result against the state
+// root.
+func verifyAccountProof(zk bool, root common.Hash, result *AccountResult) (*VerifiedAccount, error) {
+	blob, err := verifyProofValue(zk, root, result.Address.Bytes(), result.AccountProof)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account proof of %x: %w", result.Address, err)
+	}
+	account := &VerifiedAccount{Address: result.Address, Zktrie: zk}
+	if blob == nil {
+		// The account doesn't exist, so the result must be empty.
+		if result.Nonce != 0 || (result.Balance != nil && result.Balance.Sign() != 0) ||
+			(result.CodeHash != (common.Hash{}) && result.CodeHash != types.EmptyCodeHash) ||
+			(result.StorageHash != (common.Hash{}) && result.StorageHash != types.GetEmptyRootHash(zk)) {
+			return nil, fmt.Errorf("account %x is proven absent, but the result is not empty", result.Address)
+		}
+		account.Balance = new(big.Int)
+		account.StorageHash = result.StorageHash
+		return account, nil
+	}
+	state, err := types.NewStateAccount(blob, zk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account %x in proof: %w", result.Address, err)
+	}
+	codeHash := common.BytesToHash(state.CodeHash)
+	switch {
+	case state.Nonce != result.Nonce:
+		return nil, fmt.Errorf("nonce mismatch of account %x: have %d, proven %d", result.Address, result.Nonce, state.Nonce)
+	case result.Balance == nil || state.Balance.Cmp(result.Balance) != 0:
+		return nil, fmt.Errorf("balance mismatch of account %x: have %v, proven %v", result.Address, result.Balance, state.Balance)
+	case codeHash != result.CodeHash:
+		return nil, fmt.Errorf("code hash mismatch of account %x: have %x, proven %x", result.Address, result.CodeHash, codeHash)
+	case state.Root != result.StorageHash:
+		return nil, fmt.Errorf("storage hash mismatch of account %x: have %x, proven %x", result.Address, result.StorageHash, state.Root)
+	}
+	account.Exists = true
+	account.Nonce = state.Nonce
+	account.Balance = state.Balance
+	account.CodeHash = codeHash
+	account.StorageHash = state.Root
+	return account, nil
+}
+
+// verifyStorageProof verifies the storage proof against the storage root of the
+// account.
+func verifyStorageProof(zk bool, root common.Hash, storage StorageResult) (*VerifiedStorage, error) {
+	key, err := hexutil.DecodeBig(storage.Key)
+	if err != nil {
+		// The keys given as 32-byte hashes have leading zeros, which is not a
+		// valid quantity.
+		b, err := hexutil.Decode(storage.Key)
+		if err != nil || len(b) != common.HashLength {
+			return nil, fmt.Errorf("invalid storage key %q", storage.Key)
+		}
+		key = new(big.Int).SetBytes(b)
+	}
+	if key.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid storage key %q", storage.Key)
+	}
+	slot := &VerifiedStorage{Key: common.BigToHash(key), Value: new(big.Int)}
+
+	var blob []byte
+	if root == (common.Hash{}) || root == types.GetEmptyRootHash(zk) {
+		// The storage trie is empty, there is nothing to prove.
+		if len(storage.Proof) != 0 {
+			return nil, fmt.Errorf("unexpected proof for the empty storage")
+		}
+	} else if blob, err = verifyProofValue(zk, root, slot.Key.Bytes(), storage.Proof); err != nil {
+		return nil, err
+	}
+	if blob != nil {
+		slot.Exists = true
+		if zk {
+			slot.Value.SetBytes(blob)
+		} else {
+			_, content, _, err := rlp.Split(blob)
+			if err != nil {
+				return nil, fmt.Errorf("invalid storage value in proof: %w", err)
+			}
+			slot.Value.SetBytes(content)
+		}
+	}
+	if storage.Value == nil || slot.Value.Cmp(storage.Value) != 0 {
+		return nil, fmt.Errorf("value mismatch: have %v, proven %v", storage.Value, slot.Value)
+	}
+	return slot, nil
+}
+
+// verifyProofValue verifies the proof of the key in the trie with the given root,
+// returning the value of the key, or nil if it's proven absent. The key is hashed
+// as the trie does, with the keccak hash in the MPT and the poseidon hash in the
+// zk trie.
+func verifyProofValue(zk bool, root common.Hash, key []byte, proof []string) ([]byte, error) {
+	db := memorydb.New()
+	for i, hexNode := range proof {
+		node, err := hexutil.Decode(hexNode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proof node %d: %w", i, err)
+		}
+		if !zk {
+			db.Put(crypto.Keccak256(node), node)
+			continue
+		}
+		// The zk trie proofs end with the magic bytes distinguishing them from
+		// the MPT ones.
+		if bytes.Equal(node, zktrie.ProofMagicBytes()) {
+			continue
+		}
+		n, err := zktrie.NewNodeFromBytes(node)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zk trie proof node %d: %w", i, err)
+		}
+		hash, err := n.NodeHash()
+		if err != nil {
+			return nil, fmt.Errorf("invalid zk trie proof node %d: %w", i, err)
+		}
+		db.Put(hash[:], node)
+	}
+	if zk {
+		return trie.VerifyProofSMT(root, key, db)
+	}
+	return trie.VerifyProof(root, crypto.Keccak256(key), db)
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gethclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// newProofTestBackend creates a node holding the test chain in the MPT, or in
+// the zk trie if zktrie is set. The kroma zk trie is used instead of the legacy
+// one if kromaZK is set.
+func newProofTestBackend(t *testing.T, zktrie bool, kromaZK bool) (*node.Node, *params.ChainConfig, *types.Header) {
+	config := *params.AllEthashProtocolChanges
+	config.Zktrie = zktrie
+
+	genesis := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			testAddr:     {Balance: testBalance, Storage: map[common.Hash]common.Hash{testSlot: testValue, {1}: {2}}},
+			testContract: {Nonce: 1, Code: []byte{0x13, 0x37}},
+			testEmpty:    {Balance: big.NewInt(1)},
+		},
+		Timestamp: 9000,
+	}
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 1, func(i int, g *core.BlockGen) {
+		g.OffsetTime(5)
+	})
+	n, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("can't create new node: %v", err)
+	}
+	ethservice, err := eth.New(n, &ethconfig.Config{Genesis: genesis, KromaZKTrie: kromaZK})
+	if err != nil {
+		t.Fatalf("can't create new ethereum service: %v", err)
+	}
+	if err := n.Start(); err != nil {
+		t.Fatalf("can't start test node: %v", err)
+	}
+	if _, err := ethservice.BlockChain().InsertChain(blocks); err != nil {
+		t.Fatalf("can't import test blocks: %v", err)
+	}
+	return n, &config, blocks[0].Header()
+}
+
+func TestVerifyProof(t *testing.T) {
+	tests := []struct {
+		name    string
+		zktrie  bool
+		kromaZK bool
+	}{
+		{"mpt", false, false},
+		{"zktrie", true, false},
+		{"kromazk", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, config, header := newProofTestBackend(t, tt.zktrie, tt.kromaZK)
+			defer backend.Close()
+			rpcClient := backend.Attach()
+			defer rpcClient.Close()
+			client := New(rpcClient)
+
+			keys := []string{testSlot.String(), "0x1", "0x2"}
+			account, err := client.GetVerifiedProof(context.Background(), config, header, testAddr, keys)
+			if err != nil {
+				t.Fatalf("failed to verify proof: %v", err)
+			}
+			if !account.Exists || account.Balance.Cmp(testBalance) != 0 || account.Zktrie != tt.zktrie {
+				t.Fatalf("unexpected account: %+v", account)
+			}
+			if len(account.Storage) != len(keys) {
+				t.Fatalf("storage length mismatch: have %d, want %d", len(account.Storage), len(keys))
+			}
+			want := []VerifiedStorage{
+				{Key: testSlot, Exists: true, Value: testValue.Big()},
+				{Key: common.BigToHash(big.NewInt(1)), Exists: false, Value: new(big.Int)},
+				{Key: common.BigToHash(big.NewInt(2)), Exists: false, Value: new(big.Int)},
+			}
+			for i, slot := range account.Storage {
+				if slot.Key != want[i].Key || slot.Exists != want[i].Exists || slot.Value.Cmp(want[i].Value) != 0 {
+					t.Errorf("slot %d mismatch: have %+v, want %+v", i, slot, want[i])
+				}
+			}
+			// The contract without storage and the absent account are proven as well.
+			account, err = client.GetVerifiedProof(context.Background(), config, header, testContract, []string{testSlot.String()})
+			if err != nil {
+				t.Fatalf("failed to verify contract proof: %v", err)
+			}
+			if !account.Exists || account.Nonce != 1 || account.Storage[0].Exists {
+				t.Fatalf("unexpected contract: %+v", account)
+			}
+			absent := common.HexToAddress("0xdead")
+			account, err = client.GetVerifiedProof(context.Background(), config, header, absent, []string{testSlot.String()})
+			if err != nil {
+				t.Fatalf("failed to verify absent account proof: %v", err)
+			}
+			if account.Exists || account.Storage[0].Exists {
+				t.Fatalf("unexpected absent account: %+v", account)
+			}
+			// Any tampering with the result or the proofs is detected.
+			result, err := client.GetProof(context.Background(), testAddr, []string{testSlot.String()}, header.Number)
+			if err != nil {
+				t.Fatalf("failed to get proof: %v", err)
+			}
+			result.Balance = new(big.Int).Add(result.Balance, common.Big1)
+			if _, err := VerifyProof(config, header, result); err == nil {
+				t.Error("expected failure verifying a tampered balance")
+			}
+			result.Balance.Sub(result.Balance, common.Big1)
+			result.StorageProof[0].Value = new(big.Int).Add(result.StorageProof[0].Value, common.Big1)
+			if _, err := VerifyProof(config, header, result); err == nil {
+				t.Error("expected failure verifying a tampered storage value")
+			}
+			result.StorageProof[0].Value.Sub(result.StorageProof[0].Value, common.Big1)
+			if _, err := VerifyProof(config, header, result); err != nil {
+				t.Fatalf("failed to verify restored proof: %v", err)
+			}
+			result.AccountProof = result.AccountProof[1:]
+			if _, err := VerifyProof(config, header, result); err == nil {
+				t.Error("expected failure verifying a truncated account proof")
+			}
+			// The proofs are verified on the trie of the block's fork.
+			mismatch := *config
+			mismatch.Zktrie = !tt.zktrie
+			if _, err := client.GetVerifiedProof(context.Background(), &mismatch, header, testAddr, nil); err == nil {
+				t.Error("expected failure verifying the proof on the other trie")
+			}
+		})
+	}
+}
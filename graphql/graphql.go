@@ -517,6 +517,25 @@ func (t *Transaction) BlobGasPrice(ctx context.Context) (*hexutil.Big, error) {
 	return ret, nil
 }
 
+// [Kroma: START]
+func (t *Transaction) ValidatorReward(ctx context.Context) (*hexutil.Big, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(receipt.ValidatorReward), nil
+}
+
+func (t *Transaction) ProtocolFee(ctx context.Context) (*hexutil.Big, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(receipt.ProtocolFee), nil
+}
+
+// [Kroma: END]
+
 func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
 	receipt, err := t.getReceipt(ctx)
 	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
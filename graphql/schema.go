@@ -147,6 +147,14 @@ const schema string = `
         blobGasUsed: Long
         # blobGasPrice is the actual value per blob gas deducted from the senders account.
         blobGasPrice: BigInt
+        # ValidatorReward is the part of the transaction fee paid to the validators.
+        # This is null if the transaction has not yet been mined, is a deposit
+        # transaction, or was included after the Kroma MPT upgrade.
+        validatorReward: BigInt
+        # ProtocolFee is the part of the transaction fee paid to the protocol.
+        # This is null if the transaction has not yet been mined, is a deposit
+        # transaction, or was included after the Kroma MPT upgrade.
+        protocolFee: BigInt
         # CreatedContract is the account that was created by a contract creation
         # transaction. If the transaction was not a contract creation transaction,
         # or it has not yet been mined, this field will be null.
@@ -788,7 +788,7 @@ func (s *BlockChainAPI) GetProof(ctx context.Context, address common.Address, st
 
 func (s *BlockChainAPI) newStateTrie(id *trie.ID, db *trie.Database) (state.Trie, error) {
 	if db.IsKromaZK() {
-		return trie.NewZkMerkleStateTrie(id.Root, db)
+		return trie.NewZkMerkleStateTrieWithID(id, db)
 	}
 	if db.IsZk() {
 		return trie.NewZkTrie(id.Root, db)
@@ -1018,6 +1018,20 @@ func (s *BlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.
 		// as per specification.
 		return nil, nil
 	}
+
+	if s.b.ChainConfig().IsOptimismPreBedrock(block.Number()) {
+		if s.b.HistoricalRPCService() != nil {
+			var res []map[string]interface{}
+			err := s.b.HistoricalRPCService().CallContext(ctx, &res, "eth_getBlockReceipts", blockNrOrHash)
+			if err != nil {
+				return nil, fmt.Errorf("historical backend error: %w", err)
+			}
+			return res, nil
+		} else {
+			return nil, rpc.ErrNoHistoricalFallback
+		}
+	}
+
 	receipts, err := s.b.GetReceipts(ctx, block.Hash())
 	if err != nil {
 		return nil, err
@@ -1137,6 +1151,38 @@ func (diff *BlockOverrides) Apply(blockCtx *vm.BlockContext) {
 	}
 }
 
+// MakeHeader returns a new header object with the overridden fields.
+// Note: MakeHeader ignores BlobBaseFee if set. That's because the header
+// has no such field.
+func (diff *BlockOverrides) MakeHeader(header *types.Header) *types.Header {
+	if diff == nil {
+		return header
+	}
+	h := types.CopyHeader(header)
+	if diff.Number != nil {
+		h.Number = diff.Number.ToInt()
+	}
+	if diff.Difficulty != nil {
+		h.Difficulty = diff.Difficulty.ToInt()
+	}
+	if diff.Time != nil {
+		h.Time = uint64(*diff.Time)
+	}
+	if diff.GasLimit != nil {
+		h.GasLimit = uint64(*diff.GasLimit)
+	}
+	if diff.Coinbase != nil {
+		h.Coinbase = *diff.Coinbase
+	}
+	if diff.Random != nil {
+		h.MixDigest = *diff.Random
+	}
+	if diff.BaseFee != nil {
+		h.BaseFee = diff.BaseFee.ToInt()
+	}
+	return h
+}
+
 // ChainContextBackend provides methods required to implement ChainContext.
 type ChainContextBackend interface {
 	Engine() consensus.Engine
@@ -1196,6 +1242,11 @@ func doCall(ctx context.Context, b Backend, args TransactionArgs, state *state.S
 	}
 	evm := b.GetEVM(ctx, msg, state, header, &vm.Config{NoBaseFee: true}, &blockCtx)
 
+	gp := new(core.GasPool).AddGas(math.MaxUint64)
+	return applyMessageWithEVM(ctx, evm, msg, state, timeout, gp)
+}
+
+func applyMessageWithEVM(ctx context.Context, evm *vm.EVM, msg *core.Message, state *state.StateDB, timeout time.Duration, gp *core.GasPool) (*core.ExecutionResult, error) {
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
 	go func() {
@@ -1204,7 +1255,6 @@ func doCall(ctx context.Context, b Backend, args TransactionArgs, state *state.S
 	}()
 
 	// Execute the message.
-	gp := new(core.GasPool).AddGas(math.MaxUint64)
 	result, err := core.ApplyMessage(evm, msg, gp)
 	if err := state.Error(); err != nil {
 		return nil, err
@@ -1302,6 +1352,61 @@ func (s *BlockChainAPI) Call(ctx context.Context, args TransactionArgs, blockNrO
 	return result.Return(), result.Err
 }
 
+// SimulateV1 executes series of transactions on top of a base state.
+// The transactions are packed into blocks. For each block, block header
+// fields can be overridden. The state can also be overridden prior to
+// execution of each block.
+//
+// Note, this function doesn't make any changes in the state/blockchain and is
+// useful to execute and retrieve values.
+func (s *BlockChainAPI) SimulateV1(ctx context.Context, opts simOpts, blockNrOrHash *rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	if len(opts.BlockStateCalls) == 0 {
+		return nil, &invalidParamsError{message: "empty input"}
+	} else if len(opts.BlockStateCalls) > maxSimulateBlocks {
+		return nil, &clientLimitExceededError{message: "too many blocks"}
+	}
+	if blockNrOrHash == nil {
+		n := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &n
+	}
+	state, base, err := s.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if s.b.ChainConfig().IsOptimismPreBedrock(base.Number) {
+		return nil, &invalidParamsError{message: "simulation on top of a pre-bedrock block is not supported"}
+	}
+	gasCap := s.b.RPCGasCap()
+	if gasCap == 0 {
+		gasCap = math.MaxUint64
+	}
+	sim := &simulator{
+		b:           s.b,
+		state:       state,
+		base:        base,
+		chainConfig: s.b.ChainConfig(),
+		// Each tx and all the series of txes shouldn't consume more gas than cap
+		gp:             new(core.GasPool).AddGas(gasCap),
+		traceTransfers: opts.TraceTransfers,
+		validate:       opts.Validation,
+		fullTx:         opts.ReturnFullTransactions,
+	}
+	// [Kroma: START]
+	// The derived blocks start with the L1 attributes deposit, which updates the
+	// L1 attributes the L1 fees are computed from.
+	if sim.chainConfig.IsKroma() {
+		block, err := s.b.BlockByHash(ctx, base.Hash())
+		if err != nil {
+			return nil, err
+		}
+		if block != nil && len(block.Transactions()) > 0 && block.Transactions()[0].IsDepositTx() {
+			sim.l1Info = block.Transactions()[0]
+		}
+	}
+	// [Kroma: END]
+	return sim.execute(ctx, opts.BlockStateCalls)
+}
+
 // DoEstimateGas returns the lowest possible gas limit that allows the transaction to run
 // successfully at block `blockNrOrHash`. It returns error if the transaction would revert, or if
 // there are unexpected failures. The gas limit is capped by both `args.Gas` (if non-nil &
@@ -1962,6 +2067,23 @@ func marshalReceipt(receipt *types.Receipt, blockHash common.Hash, blockNumber u
 		if receipt.FeeScalar != nil { // removed in Ecotone
 			fields["l1FeeScalar"] = receipt.FeeScalar.String()
 		}
+		if receipt.L1BlobBaseFee != nil { // added in Ecotone
+			fields["l1BlobBaseFee"] = (*hexutil.Big)(receipt.L1BlobBaseFee)
+		}
+		if receipt.L1BaseFeeScalar != nil { // added in Ecotone
+			fields["l1BaseFeeScalar"] = hexutil.Uint64(*receipt.L1BaseFeeScalar)
+		}
+		if receipt.L1BlobBaseFeeScalar != nil { // added in Ecotone
+			fields["l1BlobBaseFeeScalar"] = hexutil.Uint64(*receipt.L1BlobBaseFeeScalar)
+		}
+		// [Kroma: START]
+		if receipt.ValidatorReward != nil { // removed in Kroma MPT
+			fields["validatorReward"] = (*hexutil.Big)(receipt.ValidatorReward)
+		}
+		if receipt.ProtocolFee != nil { // removed in Kroma MPT
+			fields["protocolFee"] = (*hexutil.Big)(receipt.ProtocolFee)
+		}
+		// [Kroma: END]
 	}
 	if chainConfig.Kroma != nil && tx.IsDepositTx() && receipt.DepositNonce != nil {
 		fields["depositNonce"] = hexutil.Uint64(*receipt.DepositNonce)
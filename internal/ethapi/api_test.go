@@ -961,6 +961,73 @@ func TestEstimateGas(t *testing.T) {
 	}
 }
 
+func TestEstimateTotalFee(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		config   = *params.TestChainConfig
+		zeroTime = uint64(0)
+		l1Fee    = big.NewInt(1000)
+		overhead = big.NewInt(2100)
+		scalar   = big.NewInt(1_500_000)
+	)
+	config.Kroma = &params.KromaConfig{EIP1559Elasticity: 50, EIP1559Denominator: 10}
+	config.BedrockBlock = big.NewInt(0)
+	config.RegolithTime = &zeroTime
+	genesis := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			types.KromaL1BlockAddr: {
+				Balance: common.Big0,
+				Storage: map[common.Hash]common.Hash{
+					types.L1BaseFeeSlot: common.BigToHash(l1Fee),
+					types.OverheadSlot:  common.BigToHash(overhead),
+					types.ScalarSlot:    common.BigToHash(scalar),
+				},
+			},
+		},
+	}
+	api := NewKromaAPI(newTestBackend(t, 1, genesis, ethash.NewFaker(), func(i int, b *core.BlockGen) {}))
+
+	var (
+		header   = api.b.CurrentHeader()
+		tip      = big.NewInt(params.GWei)
+		feeCap   = new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), tip)
+		gasPrice = new(big.Int).Add(header.BaseFee, tip)
+	)
+	result, err := api.EstimateTotalFee(context.Background(), TransactionArgs{
+		From:                 &accounts[0].addr,
+		To:                   &accounts[1].addr,
+		Value:                (*hexutil.Big)(big.NewInt(1000)),
+		MaxFeePerGas:         (*hexutil.Big)(feeCap),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tip),
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to estimate total fee: %v", err)
+	}
+	require.Equal(t, hexutil.Uint64(params.TxGas), result.GasUsed)
+	require.Equal(t, gasPrice, result.GasPrice.ToInt())
+	require.Equal(t, new(big.Int).Mul(gasPrice, big.NewInt(int64(params.TxGas))), result.L2Fee.ToInt())
+
+	require.Equal(t, l1Fee, result.L1GasPrice.ToInt())
+	require.Equal(t, "1.5", result.L1FeeScalar)
+	require.Nil(t, result.L1BlobBaseFee)
+	require.Nil(t, result.L1BaseFeeScalar)
+	require.Nil(t, result.L1BlobBaseFeeScalar)
+	require.Greater(t, result.L1GasUsed.ToInt().Cmp(overhead), 0)
+	wantL1Fee := new(big.Int).Mul(result.L1GasUsed.ToInt(), l1Fee)
+	wantL1Fee.Mul(wantL1Fee, scalar).Div(wantL1Fee, big.NewInt(1_000_000))
+	require.Equal(t, wantL1Fee, result.L1Fee.ToInt())
+	require.Equal(t, new(big.Int).Add(result.L2Fee.ToInt(), result.L1Fee.ToInt()), result.TotalFee.ToInt())
+
+	// The estimation fails on non-Kroma chains.
+	genesis.Config = params.TestChainConfig
+	api = NewKromaAPI(newTestBackend(t, 1, genesis, ethash.NewFaker(), func(i int, b *core.BlockGen) {}))
+	_, err = api.EstimateTotalFee(context.Background(), TransactionArgs{From: &accounts[0].addr, To: &accounts[1].addr}, nil, nil)
+	require.Error(t, err)
+}
+
 func TestCall(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -1130,6 +1197,289 @@ func TestCall(t *testing.T) {
 	}
 }
 
+func TestSimulateV1(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		backend = newTestBackend(t, 1, genesis, ethash.NewFaker(), func(i int, b *core.BlockGen) {})
+		api     = NewBlockChainAPI(backend)
+		head    = backend.CurrentHeader()
+
+		value   = (*hexutil.Big)(big.NewInt(1000))
+		emitter = common.Address{0xe1}
+		reverts = common.Address{0xe2}
+	)
+	simulate := func(opts simOpts) ([]map[string]interface{}, error) {
+		return api.SimulateV1(context.Background(), opts, nil)
+	}
+	calls := func(t *testing.T, block map[string]interface{}) []simCallResult {
+		t.Helper()
+		results, ok := block["calls"].([]simCallResult)
+		if !ok {
+			t.Fatalf("missing call results")
+		}
+		return results
+	}
+
+	// Calls are executed sequentially over the blocks, with the logs of every call.
+	results, err := simulate(simOpts{
+		TraceTransfers: true,
+		BlockStateCalls: []simBlock{{
+			StateOverrides: &StateOverride{
+				emitter: OverrideAccount{Code: hex2Bytes("600160006000a100")},         // LOG1(0, 0, 1)
+				reverts: OverrideAccount{Code: hex2Bytes("600160006000a160006000fd")}, // LOG1(0, 0, 1) REVERT(0, 0)
+			},
+			Calls: []TransactionArgs{
+				{From: &accounts[0].addr, To: &accounts[1].addr, Value: value},
+				{From: &accounts[0].addr, To: &emitter},
+			},
+		}, {
+			Calls: []TransactionArgs{
+				{From: &accounts[0].addr, To: &reverts, Value: value},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("block count mismatch: have %d, want 2", len(results))
+	}
+	for i, block := range results {
+		if have, want := block["number"].(*hexutil.Big).ToInt().Uint64(), head.Number.Uint64()+uint64(i)+1; have != want {
+			t.Errorf("block %d: number mismatch: have %d, want %d", i, have, want)
+		}
+	}
+	if results[1]["parentHash"] != results[0]["hash"] {
+		t.Errorf("simulated blocks are not chained")
+	}
+	first := calls(t, results[0])
+	if len(first) != 2 {
+		t.Fatalf("call count mismatch: have %d, want 2", len(first))
+	}
+	// The value transfer is logged as an ERC-7528 transfer.
+	if first[0].Status != hexutil.Uint64(types.ReceiptStatusSuccessful) || first[0].GasUsed != hexutil.Uint64(params.TxGas) {
+		t.Errorf("transfer: unexpected result: status %d, gas %d", first[0].Status, first[0].GasUsed)
+	}
+	if len(first[0].Logs) != 1 {
+		t.Fatalf("transfer: log count mismatch: have %d, want 1", len(first[0].Logs))
+	}
+	transfer := first[0].Logs[0]
+	if transfer.Address != transferAddress || transfer.Topics[0] != transferTopic ||
+		transfer.Topics[1] != common.BytesToHash(accounts[0].addr.Bytes()) || transfer.Topics[2] != common.BytesToHash(accounts[1].addr.Bytes()) {
+		t.Errorf("transfer: unexpected log %+v", transfer)
+	}
+	// The logs of the block are indexed within the block, with the block hash repaired.
+	if len(first[1].Logs) != 1 {
+		t.Fatalf("emitter: log count mismatch: have %d, want 1", len(first[1].Logs))
+	}
+	if log := first[1].Logs[0]; log.Address != emitter || log.Index != 1 || log.TxIndex != 1 || log.BlockHash != results[0]["hash"] {
+		t.Errorf("emitter: unexpected log %+v", log)
+	}
+	// The logs of a reverted call are dropped.
+	second := calls(t, results[1])
+	if second[0].Status != hexutil.Uint64(types.ReceiptStatusFailed) || second[0].Error == nil || second[0].Error.Code != errCodeReverted {
+		t.Errorf("revert: unexpected result %+v", second[0])
+	}
+	if len(second[0].Logs) != 0 {
+		t.Errorf("revert: have %d logs, want none", len(second[0].Logs))
+	}
+
+	// Gaps in block numbers are filled with empty blocks.
+	results, err = simulate(simOpts{
+		BlockStateCalls: []simBlock{{
+			BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(new(big.Int).Add(head.Number, big.NewInt(3)))},
+			Calls:          []TransactionArgs{{From: &accounts[0].addr, To: &accounts[1].addr}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("block count mismatch: have %d, want 3", len(results))
+	}
+	if len(calls(t, results[0])) != 0 || len(calls(t, results[2])) != 1 {
+		t.Errorf("unexpected calls in the filled blocks")
+	}
+	if results[0]["timestamp"].(hexutil.Uint64) != hexutil.Uint64(head.Time+timestampIncrement) {
+		t.Errorf("unexpected timestamp of the filled block: %v", results[0]["timestamp"])
+	}
+
+	// Invalid requests are rejected with the corresponding error codes.
+	var (
+		nonce  = hexutil.Uint64(1)
+		feeCap = (*hexutil.Big)(big.NewInt(params.GWei))
+		past   = (*hexutil.Big)(head.Number)
+		before = hexutil.Uint64(head.Time)
+	)
+	for i, tt := range []struct {
+		opts simOpts
+		code int
+	}{
+		{simOpts{}, errCodeInvalidParams},
+		{simOpts{BlockStateCalls: make([]simBlock, maxSimulateBlocks+1)}, errCodeClientLimitExceeded},
+		{simOpts{BlockStateCalls: []simBlock{{BlockOverrides: &BlockOverrides{Number: past}}}}, errCodeBlockNumberInvalid},
+		{simOpts{BlockStateCalls: []simBlock{{BlockOverrides: &BlockOverrides{Time: &before}}}}, errCodeBlockTimestampInvalid},
+		{simOpts{Validation: true, BlockStateCalls: []simBlock{{Calls: []TransactionArgs{{From: &accounts[0].addr, To: &accounts[1].addr, Nonce: &nonce, MaxFeePerGas: feeCap}}}}}, errCodeNonceTooHigh},
+		{simOpts{Validation: true, BlockStateCalls: []simBlock{{Calls: []TransactionArgs{{From: &accounts[1].addr, To: &accounts[0].addr, Value: value, MaxFeePerGas: feeCap}}}}}, errCodeInsufficientFunds},
+	} {
+		_, err := simulate(tt.opts)
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != tt.code {
+			t.Errorf("test %d: error code mismatch: have %v, want %d", i, err, tt.code)
+		}
+	}
+}
+
+func TestSimulateV1KromaL1Fee(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		config   = *params.TestChainConfig
+		zeroTime = uint64(0)
+	)
+	config.Kroma = &params.KromaConfig{EIP1559Elasticity: 50, EIP1559Denominator: 10}
+	config.BedrockBlock = big.NewInt(0)
+	config.RegolithTime = &zeroTime
+	genesis := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			types.KromaL1BlockAddr: {
+				Balance: common.Big0,
+				Storage: map[common.Hash]common.Hash{
+					types.L1BaseFeeSlot: common.BigToHash(big.NewInt(1000)),
+					types.OverheadSlot:  common.BigToHash(big.NewInt(2100)),
+					types.ScalarSlot:    common.BigToHash(big.NewInt(1_000_000)),
+				},
+			},
+		},
+	}
+	var (
+		backend = newTestBackend(t, 1, genesis, ethash.NewFaker(), func(i int, b *core.BlockGen) {})
+		api     = NewBlockChainAPI(backend)
+		feeCap  = (*hexutil.Big)(big.NewInt(params.GWei))
+		call    = TransactionArgs{From: &accounts[0].addr, To: &accounts[1].addr, MaxFeePerGas: feeCap}
+	)
+	// The L1 data fee is charged with or without the validation.
+	for _, validation := range []bool{false, true} {
+		results, err := api.SimulateV1(context.Background(), simOpts{
+			Validation:      validation,
+			BlockStateCalls: []simBlock{{Calls: []TransactionArgs{call}}},
+		}, nil)
+		if err != nil {
+			t.Fatalf("simulation failed: %v", err)
+		}
+		res := results[0]["calls"].([]simCallResult)[0]
+		require.NotNil(t, res.L1Fee)
+		require.Greater(t, res.L1Fee.ToInt().Sign(), 0)
+
+		// The sender unable to pay the fee is rejected.
+		_, err = api.SimulateV1(context.Background(), simOpts{
+			Validation:      validation,
+			BlockStateCalls: []simBlock{{Calls: []TransactionArgs{{From: &accounts[1].addr, To: &accounts[0].addr, MaxFeePerGas: feeCap}}}},
+		}, nil)
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errCodeInsufficientFunds {
+			t.Fatalf("validation %v: error code mismatch: have %v, want %d", validation, err, errCodeInsufficientFunds)
+		}
+	}
+}
+
+func TestSimulateV1KromaDeposit(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts  = newAccounts(2)
+		config    = *params.TestChainConfig
+		zeroTime  = uint64(0)
+		depositor = common.HexToAddress("0xdeaddeaddeaddeaddeaddeaddeaddeaddead0001")
+	)
+	config.Kroma = &params.KromaConfig{EIP1559Elasticity: 50, EIP1559Denominator: 10}
+	config.BedrockBlock = big.NewInt(0)
+	config.RegolithTime = &zeroTime
+	genesis := &core.Genesis{
+		Config: &config,
+		Alloc: core.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			types.KromaL1BlockAddr: {
+				Balance: common.Big0,
+				Storage: map[common.Hash]common.Hash{
+					types.L1BaseFeeSlot: common.BigToHash(big.NewInt(1000)),
+					types.OverheadSlot:  common.BigToHash(big.NewInt(2100)),
+					types.ScalarSlot:    common.BigToHash(big.NewInt(1_000_000)),
+				},
+			},
+		},
+	}
+	// The head block starts with the L1 attributes deposit, encoded as before the
+	// MPT transition.
+	l1Info := types.NewTx(&types.KromaDepositTx{
+		SourceHash: common.Hash{0x01},
+		From:       depositor,
+		To:         &types.KromaL1BlockAddr,
+		Value:      new(big.Int),
+		Gas:        1_000_000,
+	})
+	var (
+		backend = newTestBackend(t, 1, genesis, ethash.NewFaker(), func(i int, b *core.BlockGen) { b.AddTx(l1Info) })
+		api     = NewBlockChainAPI(backend)
+
+		mint   = big.NewInt(params.Ether)
+		value  = big.NewInt(1000)
+		reader = common.Address{0xe1}
+		source = common.Hash{0x02}
+	)
+	// The deposit mints to a sender without any balance, which is neither checked
+	// nor charged in validation mode. The reader returns the balance of the sender.
+	results, err := api.SimulateV1(context.Background(), simOpts{
+		Validation:             true,
+		ReturnFullTransactions: true,
+		BlockStateCalls: []simBlock{{
+			StateOverrides: &StateOverride{
+				reader: OverrideAccount{Code: hex2Bytes("73" + common.Bytes2Hex(accounts[1].addr.Bytes()) + "3160005260206000f3")}, // MSTORE(0, BALANCE(addr)) RETURN(0, 32)
+			},
+			Calls: []TransactionArgs{
+				{From: &accounts[1].addr, To: &accounts[0].addr, Value: (*hexutil.Big)(value), SourceHash: &source, Mint: (*hexutil.Big)(mint)},
+				{From: &accounts[0].addr, To: &reader, MaxFeePerGas: (*hexutil.Big)(big.NewInt(params.GWei))},
+			},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("simulation failed: %v", err)
+	}
+	calls := results[0]["calls"].([]simCallResult)
+	if len(calls) != 2 {
+		t.Fatalf("call count mismatch: have %d, want 2", len(calls))
+	}
+	if deposit := calls[0]; deposit.Status != hexutil.Uint64(types.ReceiptStatusSuccessful) || deposit.GasUsed != hexutil.Uint64(params.TxGas) || deposit.L1Fee != nil {
+		t.Errorf("deposit: unexpected result %+v", deposit)
+	}
+	if have, want := new(big.Int).SetBytes(calls[1].ReturnValue), new(big.Int).Sub(mint, value); have.Cmp(want) != 0 {
+		t.Errorf("minted balance mismatch: have %v, want %v", have, want)
+	}
+	// The L1 attributes deposit of the head is prepended to the calls.
+	txs := results[0]["transactions"].([]interface{})
+	if len(txs) != 3 {
+		t.Fatalf("transaction count mismatch: have %d, want 3", len(txs))
+	}
+	if tx := txs[0].(*RPCTransaction); tx.Hash != l1Info.Hash() || tx.From != depositor {
+		t.Errorf("unexpected L1 attributes deposit %+v", tx)
+	}
+	if tx := txs[1].(*RPCTransaction); tx.Type != hexutil.Uint64(types.DepositTxType) || tx.From != accounts[1].addr ||
+		tx.SourceHash == nil || *tx.SourceHash != source || tx.Mint == nil || tx.Mint.ToInt().Cmp(mint) != 0 {
+		t.Errorf("unexpected deposit %+v", tx)
+	}
+	if results[0]["gasUsed"].(hexutil.Uint64) != hexutil.Uint64(2*params.TxGas)+calls[1].GasUsed {
+		t.Errorf("unexpected gas used by the block: %v", results[0]["gasUsed"])
+	}
+}
+
 type account struct {
 	key  *ecdsa.PrivateKey
 	addr common.Address
@@ -1899,3 +2249,72 @@ func testRPCResponseWithFile(t *testing.T, testid int, result interface{}, rpc s
 	}
 	require.JSONEqf(t, string(want), string(data), "test %d: json not match, want: %s, have: %s", testid, string(want), string(data))
 }
+
+func TestSendRawTransactionConditional(t *testing.T) {
+	t.Parallel()
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{accounts[0].addr: {Balance: big.NewInt(params.Ether)}},
+		}
+		backend = newTestBackend(t, 1, genesis, ethash.NewFaker(), func(i int, b *core.BlockGen) {})
+		signer  = types.LatestSigner(params.TestChainConfig)
+	)
+	tx := types.MustSignNewTx(accounts[0].key, signer, &types.LegacyTx{To: &accounts[1].addr, Gas: params.TxGas, GasPrice: big.NewInt(params.GWei)})
+	input, _ := tx.MarshalBinary()
+
+	slots := func(n int) types.KnownAccounts {
+		storage := make(map[common.Hash]common.Hash)
+		for i := 0; i < n; i++ {
+			storage[common.BigToHash(big.NewInt(int64(i)))] = common.Hash{0x01}
+		}
+		return types.KnownAccounts{accounts[1].addr: {StorageSlots: storage}}
+	}
+	var tests = []struct {
+		cond types.TransactionConditional
+		code int
+	}{
+		// cost above the maximum
+		{types.TransactionConditional{KnownAccounts: slots(types.TransactionConditionalMaxCost)}, TransactionConditionalCostExceededErrCode},
+		// invalid block number range
+		{types.TransactionConditional{BlockNumberMin: big.NewInt(2), BlockNumberMax: big.NewInt(1)}, TransactionConditionalRejectedErrCode},
+		// block number out of range
+		{types.TransactionConditional{BlockNumberMax: big.NewInt(0)}, TransactionConditionalRejectedErrCode},
+		// storage slot mismatch
+		{types.TransactionConditional{KnownAccounts: slots(1)}, TransactionConditionalRejectedErrCode},
+	}
+	api := NewTransactionConditionalAPI(backend, nil, 1000)
+	for i, tt := range tests {
+		_, err := api.SendRawTransactionConditional(context.Background(), input, tt.cond)
+		if err == nil {
+			t.Fatalf("test %d: expected error", i)
+		}
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != tt.code {
+			t.Errorf("test %d: error code mismatch: have %v, want %d", i, err, tt.code)
+		}
+	}
+	// Conditionals above the rate limit are rejected
+	api = NewTransactionConditionalAPI(backend, nil, 0)
+	cond := types.TransactionConditional{KnownAccounts: slots(types.TransactionConditionalMaxCost / 2)}
+
+	// Malformed requests don't use up the rate limit
+	invalid := cond
+	invalid.BlockNumberMin, invalid.BlockNumberMax = big.NewInt(2), big.NewInt(1)
+	for i := 0; i < 3; i++ {
+		if _, err := api.SendRawTransactionConditional(context.Background(), hexutil.Bytes{0x01}, cond); err == nil {
+			t.Fatalf("request %d: expected decoding error", i)
+		}
+		if _, err := api.SendRawTransactionConditional(context.Background(), input, invalid); err == nil {
+			t.Fatalf("request %d: expected validation error", i)
+		}
+	}
+	for i, code := range []int{TransactionConditionalRejectedErrCode, TransactionConditionalCostExceededErrCode} {
+		_, err := api.SendRawTransactionConditional(context.Background(), input, cond)
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != code {
+			t.Errorf("request %d: error code mismatch: have %v, want %d", i, err, code)
+		}
+	}
+}
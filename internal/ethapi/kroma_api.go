@@ -0,0 +1,132 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// placeholderSignature is attached to the estimated transaction, as the data availability
+// fee depends on the size of the signed transaction. A signature of non-zero bytes is used
+// so that the fee is not underestimated.
+var placeholderSignature = append(bytes.Repeat([]byte{0xff}, 64), 0x01)
+
+// KromaAPI provides an API to access Kroma specific information.
+type KromaAPI struct {
+	b Backend
+}
+
+// NewKromaAPI creates a new Kroma API.
+func NewKromaAPI(b Backend) *KromaAPI {
+	return &KromaAPI{b}
+}
+
+// TotalFeeEstimate is the breakdown of the estimated fee of a transaction, which is the sum
+// of the L2 execution fee and the L1 data availability fee.
+type TotalFeeEstimate struct {
+	GasUsed             hexutil.Uint64  `json:"gasUsed"`
+	GasPrice            *hexutil.Big    `json:"gasPrice"`
+	L2Fee               *hexutil.Big    `json:"l2Fee"`
+	L1Fee               *hexutil.Big    `json:"l1Fee"`
+	L1GasUsed           *hexutil.Big    `json:"l1GasUsed"`
+	L1GasPrice          *hexutil.Big    `json:"l1GasPrice"`
+	L1FeeScalar         string          `json:"l1FeeScalar,omitempty"`         // removed in Ecotone
+	L1BlobBaseFee       *hexutil.Big    `json:"l1BlobBaseFee,omitempty"`       // added in Ecotone
+	L1BaseFeeScalar     *hexutil.Uint64 `json:"l1BaseFeeScalar,omitempty"`     // added in Ecotone
+	L1BlobBaseFeeScalar *hexutil.Uint64 `json:"l1BlobBaseFeeScalar,omitempty"` // added in Ecotone
+	TotalFee            *hexutil.Big    `json:"totalFee"`
+}
+
+// EstimateTotalFee estimates the total fee of the transaction at block `blockNrOrHash`, or the
+// latest block if `blockNrOrHash` is unspecified. The L2 gas is estimated as by eth_estimateGas
+// and charged at the effective gas price of the transaction, defaulting the fee fields as
+// eth_sendTransaction does. The L1 data fee is computed from the L1 gas parameters of the
+// L1Block contract at that block.
+func (api *KromaAPI) EstimateTotalFee(ctx context.Context, args TransactionArgs, blockNrOrHash *rpc.BlockNumberOrHash, overrides *StateOverride) (*TotalFeeEstimate, error) {
+	config := api.b.ChainConfig()
+	if config.Kroma == nil {
+		return nil, errors.New("total fee estimation is only supported on Kroma chains")
+	}
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	gas, err := DoEstimateGas(ctx, api.b, args, bNrOrHash, overrides, api.b.RPCGasCap())
+	if err != nil {
+		return nil, err
+	}
+	state, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, bNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, err
+	}
+	// Fill in the remaining fields to build the transaction as it would be sent.
+	args.Gas = &gas
+	if err := args.setFeeDefaults(ctx, api.b); err != nil {
+		return nil, err
+	}
+	if args.Value == nil {
+		args.Value = new(hexutil.Big)
+	}
+	if args.Nonce == nil {
+		nonce := hexutil.Uint64(state.GetNonce(args.from()))
+		args.Nonce = &nonce
+	}
+	if args.ChainID == nil {
+		args.ChainID = (*hexutil.Big)(config.ChainID)
+	}
+	tx, err := args.toTransaction().WithSignature(types.LatestSignerForChainID(config.ChainID), placeholderSignature)
+	if err != nil {
+		return nil, err
+	}
+
+	gasPrice := tx.GasPrice()
+	if header.BaseFee != nil {
+		gasPrice = new(big.Int).Add(header.BaseFee, tx.EffectiveGasTipValue(header.BaseFee))
+	}
+	l2Fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(uint64(gas)))
+	result := &TotalFeeEstimate{
+		GasUsed:  gas,
+		GasPrice: (*hexutil.Big)(gasPrice),
+		L2Fee:    (*hexutil.Big)(l2Fee),
+		TotalFee: (*hexutil.Big)(l2Fee),
+	}
+	l1Cost := types.EstimateL1Cost(config, state, header.Time, tx.RollupCostData())
+	if l1Cost == nil {
+		return result, nil
+	}
+	result.L1Fee = (*hexutil.Big)(l1Cost.Fee)
+	result.L1GasUsed = (*hexutil.Big)(l1Cost.GasUsed)
+	result.L1GasPrice = (*hexutil.Big)(l1Cost.L1BaseFee)
+	if l1Cost.FeeScalar != nil {
+		result.L1FeeScalar = l1Cost.FeeScalar.String()
+	}
+	result.L1BlobBaseFee = (*hexutil.Big)(l1Cost.L1BlobBaseFee)
+	result.L1BaseFeeScalar = (*hexutil.Uint64)(l1Cost.L1BaseFeeScalar)
+	result.L1BlobBaseFeeScalar = (*hexutil.Uint64)(l1Cost.L1BlobBaseFeeScalar)
+	result.TotalFee = (*hexutil.Big)(new(big.Int).Add(l2Fee, l1Cost.Fee))
+	return result, nil
+}
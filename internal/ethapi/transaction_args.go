@@ -59,6 +59,13 @@ type TransactionArgs struct {
 	// Introduced by EIP-4844.
 	BlobFeeCap *hexutil.Big  `json:"maxFeePerBlobGas"`
 	BlobHashes []common.Hash `json:"blobVersionedHashes,omitempty"`
+
+	// [Kroma: START]
+	// Introduced by DepositTxType transaction, which can only be simulated.
+	SourceHash *common.Hash `json:"sourceHash,omitempty"`
+	Mint       *hexutil.Big `json:"mint,omitempty"`
+	IsSystemTx *bool        `json:"isSystemTx,omitempty"`
+	// [Kroma: END]
 }
 
 // from retrieves the transaction sender address.
@@ -69,6 +76,11 @@ func (args *TransactionArgs) from() common.Address {
 	return *args.From
 }
 
+// isDeposit reports whether the arguments describe a deposit transaction.
+func (args *TransactionArgs) isDeposit() bool {
+	return args.SourceHash != nil
+}
+
 // data retrieves the transaction calldata. Input field is preferred.
 func (args *TransactionArgs) data() []byte {
 	if args.Input != nil {
@@ -82,6 +94,11 @@ func (args *TransactionArgs) data() []byte {
 
 // setDefaults fills in default values for unspecified tx fields.
 func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend) error {
+	// [Kroma: START]
+	if args.isDeposit() {
+		return errors.New("deposit transactions can't be sent or signed")
+	}
+	// [Kroma: END]
 	if err := args.setFeeDefaults(ctx, b); err != nil {
 		return err
 	}
@@ -250,6 +267,59 @@ func (args *TransactionArgs) setLondonFeeDefaults(ctx context.Context, head *typ
 	return nil
 }
 
+// CallDefaults sanitizes the transaction arguments, often filling in zero values,
+// for the purpose of eth_call class of RPC methods.
+func (args *TransactionArgs) CallDefaults(globalGasCap uint64, baseFee *big.Int, chainID *big.Int) error {
+	// Reject invalid combinations of pre- and post-1559 fee styles
+	if args.GasPrice != nil && (args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil) {
+		return errors.New("both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) specified")
+	}
+	if args.ChainID == nil {
+		args.ChainID = (*hexutil.Big)(chainID)
+	} else {
+		if have := (*big.Int)(args.ChainID); have.Cmp(chainID) != 0 {
+			return fmt.Errorf("chainId does not match node's (have=%v, want=%v)", have, chainID)
+		}
+	}
+	if args.Gas == nil {
+		gas := globalGasCap
+		if gas == 0 {
+			gas = uint64(math.MaxUint64 / 2)
+		}
+		args.Gas = (*hexutil.Uint64)(&gas)
+	} else {
+		if globalGasCap > 0 && globalGasCap < uint64(*args.Gas) {
+			log.Warn("Caller gas above allowance, capping", "requested", args.Gas, "cap", globalGasCap)
+			args.Gas = (*hexutil.Uint64)(&globalGasCap)
+		}
+	}
+	if args.Nonce == nil {
+		args.Nonce = new(hexutil.Uint64)
+	}
+	if args.Value == nil {
+		args.Value = new(hexutil.Big)
+	}
+	if baseFee == nil || (args.GasPrice != nil && args.BlobHashes == nil) {
+		// If there's no basefee, then it must be a non-1559 execution. A legacy
+		// gas price is converted to 1559 typing when building the message.
+		if args.GasPrice == nil {
+			args.GasPrice = new(hexutil.Big)
+		}
+	} else {
+		// A basefee is provided, necessitating 1559-type execution
+		if args.MaxFeePerGas == nil {
+			args.MaxFeePerGas = new(hexutil.Big)
+		}
+		if args.MaxPriorityFeePerGas == nil {
+			args.MaxPriorityFeePerGas = new(hexutil.Big)
+		}
+	}
+	if args.BlobFeeCap == nil && args.BlobHashes != nil {
+		args.BlobFeeCap = new(hexutil.Big)
+	}
+	return nil
+}
+
 // ToMessage converts the transaction arguments to the Message type used by the
 // core evm. This method is used in calls and traces that do not require a real
 // live transaction.
@@ -337,6 +407,15 @@ func (args *TransactionArgs) ToMessage(globalGasCap uint64, baseFee *big.Int) (*
 		BlobHashes:        args.BlobHashes,
 		SkipAccountChecks: true,
 	}
+	// [Kroma: START]
+	if args.isDeposit() {
+		msg.IsDepositTx = true
+		msg.IsSystemTx = args.IsSystemTx != nil && *args.IsSystemTx
+		if args.Mint != nil {
+			msg.Mint = args.Mint.ToInt()
+		}
+	}
+	// [Kroma: END]
 	return msg, nil
 }
 
@@ -345,6 +424,19 @@ func (args *TransactionArgs) ToMessage(globalGasCap uint64, baseFee *big.Int) (*
 func (args *TransactionArgs) toTransaction() *types.Transaction {
 	var data types.TxData
 	switch {
+	// [Kroma: START]
+	case args.isDeposit():
+		data = &types.DepositTx{
+			SourceHash:          *args.SourceHash,
+			From:                args.from(),
+			To:                  args.To,
+			Mint:                (*big.Int)(args.Mint),
+			Value:               (*big.Int)(args.Value),
+			Gas:                 uint64(*args.Gas),
+			IsSystemTransaction: args.IsSystemTx != nil && *args.IsSystemTx,
+			Data:                args.data(),
+		}
+	// [Kroma: END]
 	case args.BlobHashes != nil:
 		al := types.AccessList{}
 		if args.AccessList != nil {
@@ -0,0 +1,149 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// TransactionConditionalRejectedErrCode is the JSON-RPC error code of a conditional
+	// transaction rejected for its conditional.
+	TransactionConditionalRejectedErrCode = -32003
+
+	// TransactionConditionalCostExceededErrCode is the JSON-RPC error code of a conditional
+	// transaction rejected for the cost of its conditional.
+	TransactionConditionalCostExceededErrCode = -32005
+)
+
+var (
+	txConditionalRequestCounter  = metrics.NewRegisteredCounter("rpc/sendRawTransactionConditional/requests", nil)
+	txConditionalAcceptedCounter = metrics.NewRegisteredCounter("rpc/sendRawTransactionConditional/accepted", nil)
+	txConditionalRejectedCounter = metrics.NewRegisteredCounter("rpc/sendRawTransactionConditional/rejected", nil)
+	txConditionalCostMeter       = metrics.NewRegisteredMeter("rpc/sendRawTransactionConditional/cost", nil)
+)
+
+// txConditionalError is an error of a conditional transaction submission, along with
+// its JSON-RPC error code.
+type txConditionalError struct {
+	error
+	code int
+}
+
+// ErrorCode returns the JSON error code of the conditional transaction error.
+func (e *txConditionalError) ErrorCode() int {
+	return e.code
+}
+
+// TransactionConditionalAPI provides the eth_sendRawTransactionConditional endpoint, which
+// accepts transactions to be included only if the given conditional holds.
+type TransactionConditionalAPI struct {
+	b           Backend
+	seqRPC      *rpc.Client
+	costLimiter *rate.Limiter
+}
+
+// NewTransactionConditionalAPI creates a new conditional transaction API. The submitted
+// transactions are forwarded to the sequencer if seqRPC is set, and the total cost of
+// the accepted conditionals is limited to costRateLimit per second.
+func NewTransactionConditionalAPI(b Backend, seqRPC *rpc.Client, costRateLimit float64) *TransactionConditionalAPI {
+	return &TransactionConditionalAPI{
+		b:           b,
+		seqRPC:      seqRPC,
+		costLimiter: rate.NewLimiter(rate.Limit(costRateLimit), types.TransactionConditionalMaxCost),
+	}
+}
+
+// SendRawTransactionConditional adds the signed transaction to the transaction pool, to be
+// included only if the conditional holds at the time of inclusion. The conditional must
+// also hold against the latest block at the time of submission.
+func (api *TransactionConditionalAPI) SendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, cond types.TransactionConditional) (common.Hash, error) {
+	txConditionalRequestCounter.Inc(1)
+
+	hash, err := api.sendRawTransactionConditional(ctx, input, &cond)
+	if err != nil {
+		txConditionalRejectedCounter.Inc(1)
+		return common.Hash{}, err
+	}
+	txConditionalAcceptedCounter.Inc(1)
+	return hash, nil
+}
+
+func (api *TransactionConditionalAPI) sendRawTransactionConditional(ctx context.Context, input hexutil.Bytes, cond *types.TransactionConditional) (common.Hash, error) {
+	// Reject the malformed requests before charging the rate limit, so that they
+	// don't use up the budget of the well-formed ones.
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := cond.Validate(); err != nil {
+		return common.Hash{}, &txConditionalError{err, TransactionConditionalRejectedErrCode}
+	}
+	cost := cond.Cost()
+	txConditionalCostMeter.Mark(int64(cost))
+	if cost > types.TransactionConditionalMaxCost {
+		return common.Hash{}, &txConditionalError{
+			fmt.Errorf("conditional cost %d exceeds the maximum %d", cost, types.TransactionConditionalMaxCost),
+			TransactionConditionalCostExceededErrCode,
+		}
+	}
+	if !api.costLimiter.AllowN(time.Now(), cost) {
+		return common.Hash{}, &txConditionalError{
+			fmt.Errorf("conditional cost %d exceeds the rate limit", cost),
+			TransactionConditionalCostExceededErrCode,
+		}
+	}
+	if err := api.checkConditional(ctx, cond); err != nil {
+		return common.Hash{}, &txConditionalError{err, TransactionConditionalRejectedErrCode}
+	}
+
+	// The conditional is not part of the transaction encoding, forward it as is.
+	if api.seqRPC != nil {
+		var hash common.Hash
+		if err := api.seqRPC.CallContext(ctx, &hash, "eth_sendRawTransactionConditional", input, cond); err != nil {
+			return common.Hash{}, err
+		}
+		return hash, nil
+	}
+	tx.SetConditional(cond)
+	return SubmitTransaction(ctx, api.b, tx)
+}
+
+// checkConditional checks the conditional against the latest block and its state.
+func (api *TransactionConditionalAPI) checkConditional(ctx context.Context, cond *types.TransactionConditional) error {
+	state, header, err := api.b.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return err
+	}
+	if err := cond.CheckBlockNumber(header.Number); err != nil {
+		return err
+	}
+	if err := cond.CheckTimestamp(header.Time); err != nil {
+		return err
+	}
+	return state.CheckTransactionConditional(cond)
+}
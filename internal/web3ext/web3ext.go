@@ -204,6 +204,14 @@ web3._extend({
 			name: 'datadir',
 			getter: 'admin_datadir'
 		}),
+		new web3._extend.Property({
+			name: 'migrationStatus',
+			getter: 'admin_migrationStatus'
+		}),
+		new web3._extend.Property({
+			name: 'migrationValidationStatus',
+			getter: 'admin_migrationValidationStatus'
+		}),
 	]
 });
 `
@@ -501,6 +509,12 @@ web3._extend({
 			call: 'debug_getTrieFlushInterval',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'executionWitness',
+			call: 'debug_executionWitness',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 	],
 	properties: []
 });
@@ -622,6 +636,12 @@ web3._extend({
 			call: 'eth_getBlockReceipts',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'simulateV1',
+			call: 'eth_simulateV1',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter],
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
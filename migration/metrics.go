@@ -0,0 +1,18 @@
+package migration
+
+import "github.com/ethereum/go-ethereum/metrics"
+
+var (
+	phaseGauge    = metrics.NewRegisteredGauge("migration/phase", nil)
+	targetGauge   = metrics.NewRegisteredGauge("migration/target", nil)
+	migratedGauge = metrics.NewRegisteredGauge("migration/migrated", nil)
+	accountsGauge = metrics.NewRegisteredGauge("migration/accounts", nil)
+	slotsGauge    = metrics.NewRegisteredGauge("migration/slots", nil)
+	etaGauge      = metrics.NewRegisteredGauge("migration/eta", nil)
+	errorCounter  = metrics.NewRegisteredCounter("migration/errors", nil)
+
+	validationRunningGauge    = metrics.NewRegisteredGauge("migration/validation/running", nil)
+	validationAccountsGauge   = metrics.NewRegisteredGauge("migration/validation/accounts", nil)
+	validationSlotsGauge      = metrics.NewRegisteredGauge("migration/validation/slots", nil)
+	validationMismatchCounter = metrics.NewRegisteredCounter("migration/validation/mismatches", nil)
+)
@@ -1,8 +1,10 @@
 package migration
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -24,6 +26,51 @@ import (
 // less than 32 bytes long or it will create an invalid block.
 var BedrockTransitionBlockExtraData = []byte("BEDROCK")
 
+const (
+	// checkpointAccounts is the number of migrated accounts after which the
+	// progress of the historical migration is persisted.
+	checkpointAccounts = 10000
+
+	// checkpointSlots is the number of migrated storage slots of a single
+	// account after which the progress of the historical migration is persisted.
+	checkpointSlots = 100000
+)
+
+// Validation modes of the migrated historical state.
+const (
+	// FullValidation cross-checks every account and storage slot before the
+	// migrated state is accepted.
+	FullValidation = "full"
+
+	// SampleValidation cross-checks a random sample of accounts and storage
+	// slots before the migrated state is accepted.
+	SampleValidation = "sample"
+
+	// BackgroundValidation accepts the migrated state right away and cross-checks
+	// every account and storage slot at low priority while following the chain.
+	BackgroundValidation = "background"
+)
+
+// Config contains the settings of the state migrator.
+type Config struct {
+	Workers   int // Number of concurrent workers migrating the state, the migration is sequential if not greater than 1
+	MemoryCap int // Memory allowance (MB) for buffering migrated storage trie nodes in the concurrent migration
+
+	Validation     string // Validation mode of the migrated historical state (full, sample or background)
+	SampleAccounts int    // Number of randomly sampled accounts in the sample validation
+	SampleSlots    int    // Number of randomly sampled storage slots per account in the sample validation
+	SampleSeed     int64  // Seed of the sample validation, a random seed is picked if zero
+}
+
+// DefaultConfig contains the default settings of the state migrator.
+var DefaultConfig = Config{
+	Workers:        1,
+	MemoryCap:      256,
+	Validation:     FullValidation,
+	SampleAccounts: 10000,
+	SampleSlots:    100,
+}
+
 type ethBackend interface {
 	ChainDb() ethdb.Database
 	BlockChain() *core.BlockChain
@@ -36,13 +83,54 @@ type StateMigrator struct {
 	mptdb         *trie.Database
 	allocPreimage map[common.Hash][]byte
 	migratedRef   *core.MigratedRef
+	config        Config
+	readers       chan struct{} // Semaphore limiting the concurrent storage readers
+
+	validation   ValidationStatus // Progress of the latest validation of the migrated state
+	validationMu sync.Mutex
+
+	phase            string        // Current phase of the migration
+	phaseStart       time.Time     // Time the current phase started at
+	target           uint64        // Block number of the historical migration
+	lastErr          error         // Latest error of the migration
+	startProgress    float64       // Progress of the historical migration when it was (re)started
+	progress         atomic.Uint64 // Progress of the historical migration, as float64 bits
+	migratedAccounts atomic.Uint64 // Accounts migrated by the historical migration
+	migratedSlots    atomic.Uint64 // Storage slots migrated since the historical migration started
+	statusMu         sync.Mutex
+
+	checkpointAccounts uint64
+	checkpointSlots    uint64
 
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
-func NewStateMigrator(backend ethBackend) (*StateMigrator, error) {
+func NewStateMigrator(backend ethBackend, config *Config) (*StateMigrator, error) {
 	db := backend.ChainDb()
+	if config == nil {
+		config = &DefaultConfig
+	}
+	cfg := *config
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.MemoryCap <= 0 {
+		cfg.MemoryCap = DefaultConfig.MemoryCap
+	}
+	switch cfg.Validation {
+	case "":
+		cfg.Validation = DefaultConfig.Validation
+	case FullValidation, SampleValidation, BackgroundValidation:
+	default:
+		return nil, fmt.Errorf("unknown validation mode %q", cfg.Validation)
+	}
+	if cfg.SampleAccounts <= 0 {
+		cfg.SampleAccounts = DefaultConfig.SampleAccounts
+	}
+	if cfg.SampleSlots <= 0 {
+		cfg.SampleSlots = DefaultConfig.SampleSlots
+	}
 
 	allocPreimage, err := zkPreimageFromAlloc(db)
 	if err != nil {
@@ -61,6 +149,14 @@ func NewStateMigrator(backend ethBackend) (*StateMigrator, error) {
 		mptdb:         trie.NewDatabase(db, &trie.Config{Preimages: true}),
 		allocPreimage: allocPreimage,
 		migratedRef:   core.NewMigratedRef(db),
+		config:        cfg,
+		readers:       make(chan struct{}, cfg.Workers),
+
+		checkpointAccounts: checkpointAccounts,
+		checkpointSlots:    checkpointSlots,
+
+		phase:      PhaseWaiting,
+		phaseStart: time.Now(),
 
 		ctx:    ctx,
 		cancel: cancel,
@@ -69,12 +165,20 @@ func NewStateMigrator(backend ethBackend) (*StateMigrator, error) {
 
 func (m *StateMigrator) Start() {
 	log.Info("Start state migrator to migrate ZKT to MPT")
+	go m.reportStatus()
 	go func() {
 		if m.migratedRef.Root() == (common.Hash{}) {
 			targetBlock := m.backend.BlockChain().CurrentBlock()
+			// If a previous migration has been interrupted, resume it against the same block.
+			if checkpoint := core.ReadMigrationCheckpoint(m.db); checkpoint != nil {
+				if header := m.backend.BlockChain().GetHeaderByNumber(checkpoint.BlockNumber); header != nil && header.Root == checkpoint.ZkRoot {
+					targetBlock = header
+				}
+			}
 			if targetBlock == nil {
 				targetBlock = m.backend.BlockChain().Genesis().Header()
 			}
+			m.setTarget(targetBlock.Number.Uint64())
 
 			// Wait until migration becomes possible. For migration to be possible, the target block must become safe,
 			// and the state changes of the block following the target block must be stored in the db.
@@ -83,20 +187,13 @@ func (m *StateMigrator) Start() {
 
 			// Start migration for all state up to the safe block using the zk trie iterator.
 			// This process takes a long time.
-			log.Info("Start migrate past state", "block", targetBlock.Number)
-			err := m.migrateAccount(targetBlock)
-			if err != nil {
+			if err := m.MigrateState(targetBlock); err != nil {
 				log.Error("Failed to migrate past state", "error", err)
+				m.setError(err)
 				return
 			}
-
-			err = m.ValidateStateWithIterator(m.migratedRef.Root(), targetBlock.Root)
-			if err != nil {
-				log.Error("Migrated past state is invalid", "error", err)
-				return
-			}
-			log.Info("Migrated past state have been validated")
 		}
+		m.setPhase(PhaseFollowing)
 
 		ticker := time.NewTicker(time.Second)
 		defer ticker.Stop()
@@ -110,11 +207,13 @@ func (m *StateMigrator) Start() {
 					continue
 				}
 				if m.backend.BlockChain().Config().IsKromaMPT(safeBlock.Time) {
+					m.setPhase(PhaseFinished)
 					return
 				}
 				err := m.applyNewStateTransition(safeBlock.Number.Uint64())
 				if err != nil {
 					log.Error("Failed to apply new state transition", "error", err)
+					m.setError(err)
 				}
 			case <-m.ctx.Done():
 				return
@@ -128,85 +227,217 @@ func (m *StateMigrator) Stop() {
 	m.cancel()
 }
 
-func (m *StateMigrator) migrateAccount(header *types.Header) error {
+// reportStatus periodically reports the migration status into the metrics registry.
+func (m *StateMigrator) reportStatus() {
+	ticker := time.NewTicker(statusMetricsInterval)
+	defer ticker.Stop()
+	for {
+		m.updateStatusMetrics()
+		select {
+		case <-ticker.C:
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// MigrateState migrates the whole ZK state of the given block into an MPT, validates
+// the migrated state against the ZK state and records it as the migrated reference.
+// An interrupted migration is resumed from the last checkpoint.
+func (m *StateMigrator) MigrateState(header *types.Header) error {
+	log.Info("Start migrate past state", "block", header.Number)
+	m.setTarget(header.Number.Uint64())
+	m.setPhase(PhaseHistorical)
+	root, err := m.migrateAccount(header)
+	if err != nil {
+		return err
+	}
+	if m.config.Validation != BackgroundValidation {
+		m.setPhase(PhaseValidating)
+	}
+	switch m.config.Validation {
+	case FullValidation:
+		err = m.ValidateStateWithIterator(root, header.Root)
+	case SampleValidation:
+		err = m.ValidateStateWithSamples(root, header.Root, m.config.SampleAccounts, m.config.SampleSlots, m.config.SampleSeed)
+	}
+	if err != nil {
+		return fmt.Errorf("migrated past state is invalid: %w", err)
+	}
+	if m.config.Validation != BackgroundValidation {
+		log.Info("Migrated past state have been validated", "block", header.Number, "root", root, "mode", m.config.Validation)
+	}
+	if err := m.finishMigration(root, header); err != nil {
+		return err
+	}
+	if m.config.Validation == BackgroundValidation {
+		go m.validateInBackground(root, header.Root)
+	}
+	return nil
+}
+
+// MigratedRef returns the reference to the migrated state.
+func (m *StateMigrator) MigratedRef() *core.MigratedRef {
+	return m.migratedRef
+}
+
+func (m *StateMigrator) migrateAccount(header *types.Header) (common.Hash, error) {
 	log.Info("Migrate account", "root", header.Root, "number", header.Number)
+	checkpoint := m.loadCheckpoint(header)
+	// The progress of the concurrent migration can only be resumed concurrently.
+	if m.config.Workers > 1 || len(checkpoint.Partitions) > 0 {
+		return m.migrateAccountConcurrently(header, checkpoint)
+	}
+	return m.migrateAccountSequentially(header, checkpoint)
+}
+
+func (m *StateMigrator) migrateAccountSequentially(header *types.Header, checkpoint *core.MigrationCheckpoint) (common.Hash, error) {
 	startAt := time.Now()
-	var accounts atomic.Uint64
+	accounts := &m.migratedAccounts
+	m.resetProgress(checkpoint.Accounts, keyProgress(checkpoint.AccountKey))
 
-	ticker := time.NewTicker(time.Minute)
-	defer ticker.Stop()
+	// Report the progress until the migration returns or is stopped.
+	done := make(chan struct{})
+	defer close(done)
 	go func() {
-		for ; ; <-ticker.C {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			log.Info("Migrate accounts in progress", "accounts", accounts.Load())
 			select {
+			case <-ticker.C:
+			case <-done:
+				return
 			case <-m.ctx.Done():
 				return
-			default:
-				log.Info("Migrate accounts in progress", "accounts", accounts.Load())
 			}
 		}
 	}()
 
-	mpt, err := trie.NewStateTrie(trie.TrieID(types.EmptyRootHash), m.mptdb)
+	mpt, err := trie.NewStateTrie(trie.StateTrieID(checkpoint.Root), m.mptdb)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 
 	zkt, err := trie.NewZkMerkleStateTrie(header.Root, m.zktdb)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 
-	nodeIt, err := zkt.NodeIterator(nil)
+	var (
+		resumeKey = common.CopyBytes(checkpoint.AccountKey)
+		lastKey   = common.CopyBytes(checkpoint.AccountKey)
+		pending   uint64 // Number of accounts migrated since the last checkpoint
+	)
+	// saveAccounts commits the accounts migrated so far and records them in the checkpoint.
+	saveAccounts := func() error {
+		if pending > 0 {
+			root, err := m.commit(mpt, checkpoint.Root)
+			if err != nil {
+				return err
+			}
+			mpt, err = trie.NewStateTrie(trie.StateTrieID(root), m.mptdb)
+			if err != nil {
+				return err
+			}
+			checkpoint.Root = root
+			checkpoint.AccountKey = common.CopyBytes(lastKey)
+			checkpoint.Accounts = accounts.Load()
+			pending = 0
+		}
+		checkpoint.StorageAccountKey = nil
+		checkpoint.StorageKey = nil
+		checkpoint.StorageRoot = common.Hash{}
+		checkpoint.Slots = 0
+		return nil
+	}
+
+	nodeIt, err := zkt.NodeIterator(resumeKey)
 	if err != nil {
-		return fmt.Errorf("failed to open node iterator (root: %s): %w", zkt.Hash(), err)
+		return common.Hash{}, fmt.Errorf("failed to open node iterator (root: %s): %w", zkt.Hash(), err)
 	}
 	iter := trie.NewIterator(nodeIt)
 	for iter.Next() {
+		// When resuming, the iterator is positioned at the last migrated account.
+		if len(resumeKey) > 0 && bytes.Equal(iter.Key, resumeKey) {
+			continue
+		}
 		hk := trie.IteratorKeyToHash(iter.Key, true)
 		preimage, err := m.readZkPreimage(*hk)
 		if err != nil {
-			return err
+			return common.Hash{}, err
 		}
 		address := common.BytesToAddress(preimage)
 		log.Debug("Start migrate account", "address", address.Hex())
 		acc, err := types.NewStateAccount(iter.Value, true)
 		if err != nil {
-			return err
+			return common.Hash{}, err
+		}
+		accountKey := common.CopyBytes(iter.Key)
+		var progress *core.MigrationCheckpoint
+		if bytes.Equal(accountKey, checkpoint.StorageAccountKey) {
+			progress = checkpoint
 		}
-		acc.Root, err = m.migrateStorage(address, acc.Root)
+		acc.Root, err = m.migrateStorage(address, acc.Root, progress, func(key []byte, root common.Hash, slots uint64) error {
+			if err := saveAccounts(); err != nil {
+				return err
+			}
+			checkpoint.StorageAccountKey = accountKey
+			checkpoint.StorageKey = key
+			checkpoint.StorageRoot = root
+			checkpoint.Slots = slots
+			return m.writeCheckpoint(checkpoint)
+		})
 		if err != nil {
-			return err
+			return common.Hash{}, err
 		}
 		if err := mpt.UpdateAccount(address, acc); err != nil {
-			return err
+			return common.Hash{}, err
 		}
 		accounts.Add(1)
+		m.reportProgress(keyProgress(accountKey))
+		lastKey = accountKey
+		pending++
 		log.Trace("Account updated in MPT", "account", address.Hex(), "index", common.BytesToHash(iter.Key).Hex())
+
+		if pending >= m.checkpointAccounts {
+			if err := saveAccounts(); err != nil {
+				return common.Hash{}, err
+			}
+			if err := m.writeCheckpoint(checkpoint); err != nil {
+				return common.Hash{}, err
+			}
+		}
 		select {
 		case <-m.ctx.Done():
-			return m.ctx.Err()
+			return common.Hash{}, m.ctx.Err()
 		default:
 		}
 	}
 	if iter.Err != nil {
-		return fmt.Errorf("failed to traverse state trie (root: %s): %w", zkt.Hash(), iter.Err)
+		return common.Hash{}, fmt.Errorf("failed to traverse state trie (root: %s): %w", zkt.Hash(), iter.Err)
 	}
 
-	root, err := m.commit(mpt, types.EmptyRootHash)
-	if err != nil {
-		return err
+	if err := saveAccounts(); err != nil {
+		return common.Hash{}, err
+	}
+	if err := m.writeCheckpoint(checkpoint); err != nil {
+		return common.Hash{}, err
 	}
 	log.Info("Account migration finished", "accounts", accounts.Load(), "elapsed", time.Since(startAt))
 
-	if err := m.migratedRef.Update(root, header.Number.Uint64()); err != nil {
-		return err
-	}
-	return nil
+	return checkpoint.Root, nil
 }
 
+// migrateStorage migrates the storage trie of the given account into an MPT and returns its root.
+// If progress is not nil, the migration is resumed from the storage progress recorded in it.
+// The save callback is invoked periodically with the partially built storage root, so that
+// the progress can be persisted.
 func (m *StateMigrator) migrateStorage(
 	address common.Address,
 	zkStorageRoot common.Hash,
+	progress *core.MigrationCheckpoint,
+	save func(key []byte, root common.Hash, slots uint64) error,
 ) (common.Hash, error) {
 	startAt := time.Now()
 	log.Debug("Start migrate storage", "address", address.Hex())
@@ -214,8 +445,17 @@ func (m *StateMigrator) migrateStorage(
 		return types.EmptyRootHash, nil
 	}
 
-	id := trie.StorageTrieID(types.EmptyRootHash, crypto.Keccak256Hash(address.Bytes()), types.EmptyRootHash)
-	mpt, err := trie.NewStateTrie(id, m.mptdb)
+	var (
+		owner     = crypto.Keccak256Hash(address.Bytes())
+		root      = types.EmptyRootHash
+		resumeKey []byte
+		slots     uint64
+	)
+	if progress != nil {
+		root, resumeKey, slots = progress.StorageRoot, common.CopyBytes(progress.StorageKey), progress.Slots
+		log.Info("Resume storage migration", "address", address.Hex(), "slots", slots)
+	}
+	mpt, err := m.openStorageTrie(owner, root)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -225,13 +465,17 @@ func (m *StateMigrator) migrateStorage(
 		return common.Hash{}, err
 	}
 
-	nodeIt, err := zkt.NodeIterator(nil)
+	nodeIt, err := zkt.NodeIterator(resumeKey)
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to open node iterator (root: %s): %w", zkt.Hash(), err)
 	}
 	iter := trie.NewIterator(nodeIt)
-	var slots atomic.Uint64
+	var pending uint64
 	for iter.Next() {
+		// When resuming, the iterator is positioned at the last migrated slot.
+		if len(resumeKey) > 0 && bytes.Equal(iter.Key, resumeKey) {
+			continue
+		}
 		hk := trie.IteratorKeyToHash(iter.Key, true)
 		preimage, err := m.readZkPreimage(*hk)
 		if err != nil {
@@ -243,21 +487,88 @@ func (m *StateMigrator) migrateStorage(
 			return common.Hash{}, err
 		}
 
-		slots.Add(1)
+		slots++
+		pending++
+		m.migratedSlots.Add(1)
 		log.Trace("Updated storage slot to MPT", "contract", address.Hex(), "index", common.BytesToHash(iter.Key).Hex())
+
+		if pending >= m.checkpointSlots {
+			root, err = m.commit(mpt, root)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			mpt, err = m.openStorageTrie(owner, root)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			if err := save(common.CopyBytes(iter.Key), root, slots); err != nil {
+				return common.Hash{}, err
+			}
+			pending = 0
+		}
+		select {
+		case <-m.ctx.Done():
+			return common.Hash{}, m.ctx.Err()
+		default:
+		}
 	}
 	if iter.Err != nil {
 		return common.Hash{}, fmt.Errorf("failed to traverse state trie (root: %s): %w", zkt.Hash(), iter.Err)
 	}
 
-	root, err := m.commit(mpt, types.EmptyRootHash)
-	if err != nil {
-		return common.Hash{}, err
+	if pending > 0 {
+		root, err = m.commit(mpt, root)
+		if err != nil {
+			return common.Hash{}, err
+		}
 	}
-	log.Debug("Storage migration finished", "account", address, "slots", slots.Load(), "elapsed", time.Since(startAt))
+	log.Debug("Storage migration finished", "account", address, "slots", slots, "elapsed", time.Since(startAt))
 	return root, nil
 }
 
+// openStorageTrie opens the partially built storage trie of the given owner. Since no
+// state root references it yet, the storage root itself is used to identify the state.
+func (m *StateMigrator) openStorageTrie(owner common.Hash, root common.Hash) (*trie.StateTrie, error) {
+	return trie.NewStateTrie(trie.StorageTrieID(root, owner, root), m.mptdb)
+}
+
+// loadCheckpoint returns the stored checkpoint if it belongs to the given header,
+// otherwise a fresh checkpoint is returned.
+func (m *StateMigrator) loadCheckpoint(header *types.Header) *core.MigrationCheckpoint {
+	checkpoint := core.ReadMigrationCheckpoint(m.db)
+	if checkpoint != nil {
+		if checkpoint.BlockNumber == header.Number.Uint64() && checkpoint.ZkRoot == header.Root {
+			log.Info("Resume account migration from checkpoint", "number", checkpoint.BlockNumber, "root", checkpoint.Root, "accounts", checkpoint.Accounts)
+			return checkpoint
+		}
+		log.Warn("Discard migration checkpoint of another block", "number", checkpoint.BlockNumber, "root", checkpoint.ZkRoot)
+	}
+	return &core.MigrationCheckpoint{
+		BlockNumber: header.Number.Uint64(),
+		ZkRoot:      header.Root,
+		Root:        types.EmptyRootHash,
+	}
+}
+
+// finishMigration marks the historical migration of the given block as completed.
+func (m *StateMigrator) finishMigration(root common.Hash, header *types.Header) error {
+	if err := m.migratedRef.Update(root, header.Number.Uint64()); err != nil {
+		return err
+	}
+	if err := core.DeleteMigrationCheckpoint(m.db); err != nil {
+		return fmt.Errorf("failed to delete migration checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (m *StateMigrator) writeCheckpoint(checkpoint *core.MigrationCheckpoint) error {
+	if err := core.WriteMigrationCheckpoint(m.db, checkpoint); err != nil {
+		return err
+	}
+	log.Debug("Saved migration checkpoint", "root", checkpoint.Root, "accounts", checkpoint.Accounts, "slots", checkpoint.Slots)
+	return nil
+}
+
 func (m *StateMigrator) readZkPreimage(hashKey common.Hash) ([]byte, error) {
 	if preimage, ok := m.allocPreimage[hashKey]; ok {
 		return preimage, nil
@@ -280,6 +591,20 @@ func (m *StateMigrator) commit(mpt *trie.StateTrie, parentHash common.Hash) (com
 		return root, nil
 	}
 
+	if err := m.checkCollision(set); err != nil {
+		return common.Hash{}, err
+	}
+	if err := m.mptdb.Update(root, parentHash, 0, trienode.NewWithNodeSet(set), nil); err != nil {
+		return common.Hash{}, err
+	}
+	if err := m.mptdb.Commit(root, false); err != nil {
+		return common.Hash{}, err
+	}
+	return root, nil
+}
+
+// checkCollision ensures that none of the given MPT nodes overwrites a ZK trie node.
+func (m *StateMigrator) checkCollision(set *trienode.NodeSet) error {
 	// NOTE(pangssu): It is possible that the keccak256 and poseidon hashes collide, and data loss can occur.
 	for path, mptNode := range set.Nodes {
 		if mptNode.IsDeleted() {
@@ -290,17 +615,10 @@ func (m *StateMigrator) commit(mpt *trie.StateTrie, parentHash common.Hash) (com
 			continue
 		}
 		if node, err := zk.NewTreeNodeFromBlob(data); err == nil {
-			return common.Hash{}, fmt.Errorf("hash collision detected: hashKey: %v, path: %v, data: %v, zkNode: %v", mptNode.Hash, path, data, node.Hash())
+			return fmt.Errorf("hash collision detected: hashKey: %v, path: %v, data: %v, zkNode: %v", mptNode.Hash, path, data, node.Hash())
 		}
 	}
-
-	if err := m.mptdb.Update(root, parentHash, 0, trienode.NewWithNodeSet(set), nil); err != nil {
-		return common.Hash{}, err
-	}
-	if err := m.mptdb.Commit(root, false); err != nil {
-		return common.Hash{}, err
-	}
-	return root, nil
+	return nil
 }
 
 func (m *StateMigrator) FinalizeTransition(transitionBlock types.Block) {
@@ -0,0 +1,444 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+)
+
+const (
+	// partitionBits is the number of leading tree path bits used to partition
+	// the key space of a zk trie for the concurrent migration.
+	partitionBits = 4
+
+	// partitions is the number of key space partitions of a zk trie.
+	partitions = 1 << partitionBits
+)
+
+// partitionOf returns the key space partition of the given iterator key.
+func partitionOf(key []byte) int {
+	return int(key[0] >> (8 - partitionBits))
+}
+
+// partitionStart returns the first iterator key of the given partition.
+func partitionStart(partition int) []byte {
+	start := make([]byte, common.HashLength)
+	start[0] = byte(partition << (8 - partitionBits))
+	return start
+}
+
+// partitionEnd returns the last iterator key of the given partition.
+func partitionEnd(partition int) []byte {
+	end := bytes.Repeat([]byte{0xff}, common.HashLength)
+	end[0] = byte(partition<<(8-partitionBits)) | 0xff>>partitionBits
+	return end
+}
+
+// partitionWatermarks returns the iterator key of the last migrated account of
+// every partition recorded in the given checkpoint.
+func partitionWatermarks(checkpoint *core.MigrationCheckpoint) [][]byte {
+	watermarks := make([][]byte, partitions)
+	if len(checkpoint.Partitions) == partitions {
+		for i, key := range checkpoint.Partitions {
+			watermarks[i] = common.CopyBytes(key)
+		}
+		return watermarks
+	}
+	// The checkpoint has been written by the sequential migration, all accounts up to
+	// AccountKey have been migrated. The storage in progress is migrated again.
+	if len(checkpoint.AccountKey) > 0 {
+		last := partitionOf(checkpoint.AccountKey)
+		for i := 0; i < last; i++ {
+			watermarks[i] = partitionEnd(i)
+		}
+		watermarks[last] = common.CopyBytes(checkpoint.AccountKey)
+	}
+	return watermarks
+}
+
+// iteratePartition invokes fn for every leaf of the zk trie in the given partition,
+// skipping all leaves up to and including the given key.
+func (m *StateMigrator) iteratePartition(ctx context.Context, root common.Hash, partition int, after []byte, fn func(key, value []byte) error) error {
+	zkt, err := trie.NewZkMerkleStateTrie(root, m.zktdb)
+	if err != nil {
+		return err
+	}
+	start := partitionStart(partition)
+	if len(after) > 0 {
+		start = after
+	}
+	nodeIt, err := zkt.NodeIterator(start)
+	if err != nil {
+		return fmt.Errorf("failed to open node iterator (root: %s): %w", root, err)
+	}
+	iter := trie.NewIterator(nodeIt)
+	for iter.Next() {
+		if partitionOf(iter.Key) != partition {
+			break
+		}
+		if bytes.Equal(iter.Key, after) {
+			continue
+		}
+		if err := fn(iter.Key, iter.Value); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+	if iter.Err != nil {
+		return fmt.Errorf("failed to traverse state trie (root: %s): %w", root, iter.Err)
+	}
+	return nil
+}
+
+// nodeBuffer collects the dirty nodes of the migrated storage tries, and flushes
+// them into the database once the configured memory allowance is exceeded.
+type nodeBuffer struct {
+	m     *StateMigrator
+	limit common.StorageSize
+
+	lock  sync.Mutex
+	nodes *trienode.MergedNodeSet
+	roots []common.Hash
+	size  common.StorageSize
+}
+
+func newNodeBuffer(m *StateMigrator) *nodeBuffer {
+	return &nodeBuffer{
+		m:     m,
+		limit: common.StorageSize(m.config.MemoryCap * 1024 * 1024),
+		nodes: trienode.NewMergedNodeSet(),
+	}
+}
+
+// add merges the dirty nodes of the storage trie with the given root into the buffer.
+func (b *nodeBuffer) add(root common.Hash, set *trienode.NodeSet) error {
+	if set == nil {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.nodes.Merge(set); err != nil {
+		return err
+	}
+	b.roots = append(b.roots, root)
+	for path, n := range set.Nodes {
+		b.size += common.StorageSize(len(path) + n.Size())
+	}
+	if b.size < b.limit {
+		return nil
+	}
+	return b.flushLocked()
+}
+
+// flush writes all buffered nodes into the database.
+func (b *nodeBuffer) flush() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.flushLocked()
+}
+
+func (b *nodeBuffer) flushLocked() error {
+	if len(b.roots) == 0 {
+		return nil
+	}
+	start := time.Now()
+	for _, set := range b.nodes.Sets {
+		if err := b.m.checkCollision(set); err != nil {
+			return err
+		}
+	}
+	if err := b.m.mptdb.Update(types.EmptyRootHash, types.EmptyRootHash, 0, b.nodes, nil); err != nil {
+		return err
+	}
+	for _, root := range b.roots {
+		if err := b.m.mptdb.Commit(root, false); err != nil {
+			return err
+		}
+	}
+	log.Debug("Flushed migrated storage tries", "tries", len(b.roots), "size", b.size, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	b.nodes = trienode.NewMergedNodeSet()
+	b.roots = nil
+	b.size = 0
+	return nil
+}
+
+// migrateAccountConcurrently migrates the state of the given header by partitioning the
+// key space of the zk trie across the configured number of workers. The accounts are
+// inserted into a single MPT, whose progress is persisted per partition.
+func (m *StateMigrator) migrateAccountConcurrently(header *types.Header, checkpoint *core.MigrationCheckpoint) (common.Hash, error) {
+	log.Info("Migrate accounts concurrently", "workers", m.config.Workers, "memorycap", m.config.MemoryCap)
+	startAt := time.Now()
+	accounts, slots := &m.migratedAccounts, &m.migratedSlots
+	m.resetProgress(checkpoint.Accounts, partitionProgress(partitionWatermarks(checkpoint)))
+
+	// Report the progress until the migration returns or is stopped.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			log.Info("Migrate accounts in progress", "accounts", accounts.Load(), "slots", slots.Load())
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			case <-m.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		resume     = partitionWatermarks(checkpoint) // Read by the workers only
+		watermarks = partitionWatermarks(checkpoint) // Updated by the collector only
+	)
+	checkpoint.AccountKey = nil
+	checkpoint.StorageAccountKey = nil
+	checkpoint.StorageKey = nil
+	checkpoint.StorageRoot = common.Hash{}
+	checkpoint.Slots = 0
+
+	mpt, err := trie.NewStateTrie(trie.StateTrieID(checkpoint.Root), m.mptdb)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	buffer := newNodeBuffer(m)
+
+	type migratedAccount struct {
+		partition int
+		key       []byte
+		address   common.Address
+		account   *types.StateAccount
+	}
+	var (
+		results     = make(chan migratedAccount, 1024)
+		tasks       = make(chan int, partitions)
+		ctx, cancel = context.WithCancel(m.ctx)
+	)
+	defer cancel()
+	for i := 0; i < partitions; i++ {
+		tasks <- i
+	}
+	close(tasks)
+
+	group, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < m.config.Workers; i++ {
+		group.Go(func() error {
+			for partition := range tasks {
+				err := m.iteratePartition(ctx, header.Root, partition, resume[partition], func(key, value []byte) error {
+					hk := trie.IteratorKeyToHash(key, true)
+					preimage, err := m.readZkPreimage(*hk)
+					if err != nil {
+						return err
+					}
+					address := common.BytesToAddress(preimage)
+					acc, err := types.NewStateAccount(value, true)
+					if err != nil {
+						return err
+					}
+					acc.Root, err = m.migrateStorageConcurrently(ctx, address, acc.Root, buffer, slots)
+					if err != nil {
+						return err
+					}
+					select {
+					case results <- migratedAccount{partition, common.CopyBytes(key), address, acc}:
+						return nil
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	go func() {
+		group.Wait()
+		close(results)
+	}()
+
+	var pending uint64 // Number of accounts migrated since the last checkpoint
+	// save flushes the migrated storage tries, commits the accounts migrated so far
+	// and persists the progress of every partition.
+	save := func() error {
+		if pending == 0 {
+			return nil
+		}
+		if err := buffer.flush(); err != nil {
+			return err
+		}
+		root, err := m.commit(mpt, checkpoint.Root)
+		if err != nil {
+			return err
+		}
+		mpt, err = trie.NewStateTrie(trie.StateTrieID(root), m.mptdb)
+		if err != nil {
+			return err
+		}
+		checkpoint.Root = root
+		checkpoint.Accounts = accounts.Load()
+		checkpoint.Partitions = make([][]byte, partitions)
+		for i, key := range watermarks {
+			checkpoint.Partitions[i] = common.CopyBytes(key)
+		}
+		pending = 0
+		return m.writeCheckpoint(checkpoint)
+	}
+
+	// Accounts are inserted into the MPT by this goroutine only. Once an error occurs,
+	// the workers are canceled and the remaining results are drained.
+	var failure error
+	for result := range results {
+		if failure != nil {
+			continue
+		}
+		if err := mpt.UpdateAccount(result.address, result.account); err != nil {
+			failure = err
+			cancel()
+			continue
+		}
+		watermarks[result.partition] = result.key
+		accounts.Add(1)
+		m.reportProgress(partitionProgress(watermarks))
+		pending++
+		log.Trace("Account updated in MPT", "account", result.address.Hex(), "index", common.BytesToHash(result.key).Hex())
+
+		if pending >= m.checkpointAccounts {
+			if err := save(); err != nil {
+				failure = err
+				cancel()
+			}
+		}
+	}
+	if err := group.Wait(); failure == nil {
+		failure = err
+	}
+	if failure != nil {
+		return common.Hash{}, failure
+	}
+	if err := save(); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Account migration finished", "accounts", accounts.Load(), "slots", slots.Load(), "elapsed", time.Since(startAt))
+
+	return checkpoint.Root, nil
+}
+
+// migrateStorageConcurrently migrates the storage trie of the given account into an MPT,
+// reading the partitions of the zk trie concurrently. The dirty nodes of the resulting
+// trie are merged into the given buffer.
+func (m *StateMigrator) migrateStorageConcurrently(
+	ctx context.Context,
+	address common.Address,
+	zkStorageRoot common.Hash,
+	buffer *nodeBuffer,
+	counter *atomic.Uint64,
+) (common.Hash, error) {
+	if zkStorageRoot == (common.Hash{}) {
+		return types.EmptyRootHash, nil
+	}
+	owner := crypto.Keccak256Hash(address.Bytes())
+	mpt, err := trie.NewStateTrie(trie.StorageTrieID(types.EmptyRootHash, owner, types.EmptyRootHash), m.mptdb)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	type storageSlot struct {
+		key   []byte
+		value []byte
+	}
+	slots := make(chan storageSlot, 1024)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	group, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < partitions; i++ {
+		partition := i
+		group.Go(func() error {
+			select {
+			case m.readers <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() { <-m.readers }()
+
+			return m.iteratePartition(ctx, zkStorageRoot, partition, nil, func(key, value []byte) error {
+				hk := trie.IteratorKeyToHash(key, true)
+				preimage, err := m.readZkPreimage(*hk)
+				if err != nil {
+					return err
+				}
+				slot := storageSlot{
+					key:   common.BytesToHash(preimage).Bytes(),
+					value: common.TrimLeftZeroes(common.BytesToHash(value).Bytes()),
+				}
+				select {
+				case slots <- slot:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		})
+	}
+	go func() {
+		group.Wait()
+		close(slots)
+	}()
+
+	var (
+		count   uint64
+		failure error
+	)
+	for slot := range slots {
+		if failure != nil {
+			continue
+		}
+		if err := mpt.UpdateStorage(address, slot.key, slot.value); err != nil {
+			failure = err
+			cancel()
+			continue
+		}
+		count++
+		counter.Add(1)
+	}
+	if err := group.Wait(); failure == nil {
+		failure = err
+	}
+	if failure != nil {
+		return common.Hash{}, failure
+	}
+
+	root, set, err := mpt.Commit(true)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := buffer.add(root, set); err != nil {
+		return common.Hash{}, err
+	}
+	log.Debug("Storage migration finished", "account", address, "slots", count)
+	return root, nil
+}
@@ -0,0 +1,489 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/pruner"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+type testBackend struct {
+	db    ethdb.Database
+	chain *core.BlockChain
+}
+
+func (b *testBackend) ChainDb() ethdb.Database      { return b.db }
+func (b *testBackend) BlockChain() *core.BlockChain { return b.chain }
+
+// killingDatabase cancels the migrator once the given number of checkpoints
+// have been written, simulating a node shutdown in the middle of the migration.
+type killingDatabase struct {
+	ethdb.Database
+	remaining int
+	kill      func()
+}
+
+func (db *killingDatabase) Put(key []byte, value []byte) error {
+	if err := db.Database.Put(key, value); err != nil {
+		return err
+	}
+	if bytes.Equal(key, []byte("MigrationCheckpoint")) {
+		if db.remaining--; db.remaining == 0 {
+			db.kill()
+		}
+	}
+	return nil
+}
+
+func testAlloc(accounts int, contracts int, slots int) core.GenesisAlloc {
+	alloc := make(core.GenesisAlloc)
+	for i := 0; i < accounts; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+		alloc[addr] = core.GenesisAccount{Balance: big.NewInt(int64(i + 1)), Nonce: uint64(i)}
+	}
+	for i := 0; i < contracts; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(0x10000 + i)))
+		storage := make(map[common.Hash]common.Hash)
+		for j := 0; j < slots; j++ {
+			storage[common.BigToHash(big.NewInt(int64(j)))] = common.BigToHash(big.NewInt(int64(i*slots + j + 1)))
+		}
+		alloc[addr] = core.GenesisAccount{
+			Balance: big.NewInt(1),
+			Code:    []byte{byte(vm.PUSH1), byte(i), byte(vm.STOP)},
+			Storage: storage,
+		}
+	}
+	return alloc
+}
+
+func newTestBackend(t *testing.T, alloc core.GenesisAlloc) *testBackend {
+	config := *params.TestChainConfig
+	config.Zktrie = true
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &core.Genesis{Config: &config, Alloc: alloc}
+	)
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	t.Cleanup(chain.Stop)
+	return &testBackend{db: db, chain: chain}
+}
+
+func newTestMigrator(t *testing.T, backend ethBackend, workers int) *StateMigrator {
+	m, err := NewStateMigrator(backend, &Config{Workers: workers, MemoryCap: 1})
+	if err != nil {
+		t.Fatalf("failed to create state migrator: %v", err)
+	}
+	m.checkpointAccounts = 16
+	m.checkpointSlots = 32
+	return m
+}
+
+// migrateUninterrupted migrates the genesis state of a fresh chain in one pass
+// and returns the resulting MPT root.
+func migrateUninterrupted(t *testing.T, alloc core.GenesisAlloc) common.Hash {
+	backend := newTestBackend(t, alloc)
+	m := newTestMigrator(t, backend, 1)
+	if err := m.MigrateState(backend.chain.Genesis().Header()); err != nil {
+		t.Fatalf("failed to migrate state: %v", err)
+	}
+	return m.migratedRef.Root()
+}
+
+func TestMigrateResumeFromCheckpoint(t *testing.T) {
+	tests := []struct {
+		name      string
+		alloc     core.GenesisAlloc
+		killAfter int
+		inStorage bool // Whether the migration is killed during a storage migration
+	}{
+		{"accounts", testAlloc(100, 0, 0), 3, false},
+		{"storage", testAlloc(20, 3, 100), 2, true},
+		{"storage-late", testAlloc(20, 3, 100), 7, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := migrateUninterrupted(t, tt.alloc)
+
+			backend := newTestBackend(t, tt.alloc)
+			header := backend.chain.Genesis().Header()
+
+			// Kill the migrator after a few checkpoints have been written.
+			killing := &killingDatabase{Database: backend.db, remaining: tt.killAfter}
+			m := newTestMigrator(t, &testBackend{db: killing, chain: backend.chain}, 1)
+			killing.kill = m.Stop
+			if err := m.MigrateState(header); !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected migration to be interrupted, got %v", err)
+			}
+			checkpoint := core.ReadMigrationCheckpoint(backend.db)
+			if checkpoint == nil {
+				t.Fatal("checkpoint not stored")
+			}
+			if checkpoint.BlockNumber != header.Number.Uint64() || checkpoint.ZkRoot != header.Root {
+				t.Fatalf("checkpoint block mismatch: have %d/%x, want %d/%x", checkpoint.BlockNumber, checkpoint.ZkRoot, header.Number, header.Root)
+			}
+			if inStorage := len(checkpoint.StorageAccountKey) > 0; inStorage != tt.inStorage {
+				t.Fatalf("checkpoint storage progress mismatch: have %v, want %v", inStorage, tt.inStorage)
+			}
+			if m.migratedRef.Root() != (common.Hash{}) {
+				t.Fatal("interrupted migration must not update the migrated reference")
+			}
+
+			// Restart the migrator, it must resume from the checkpoint.
+			m = newTestMigrator(t, backend, 1)
+			if err := m.MigrateState(header); err != nil {
+				t.Fatalf("failed to resume migration: %v", err)
+			}
+			if root := m.migratedRef.Root(); root != expected {
+				t.Fatalf("migrated root mismatch: have %x, want %x", root, expected)
+			}
+			if core.ReadMigrationCheckpoint(backend.db) != nil {
+				t.Fatal("checkpoint not deleted after migration")
+			}
+		})
+	}
+}
+
+func TestMigrateDiscardStaleCheckpoint(t *testing.T) {
+	alloc := testAlloc(50, 1, 50)
+	expected := migrateUninterrupted(t, alloc)
+
+	backend := newTestBackend(t, alloc)
+	header := backend.chain.Genesis().Header()
+	stale := &core.MigrationCheckpoint{
+		BlockNumber: header.Number.Uint64() + 1,
+		ZkRoot:      common.HexToHash("0xdeadbeef"),
+		Root:        common.HexToHash("0xdeadbeef"),
+		AccountKey:  common.Hex2Bytes("ff"),
+		Accounts:    10,
+	}
+	if err := core.WriteMigrationCheckpoint(backend.db, stale); err != nil {
+		t.Fatalf("failed to write checkpoint: %v", err)
+	}
+	m := newTestMigrator(t, backend, 1)
+	if err := m.MigrateState(header); err != nil {
+		t.Fatalf("failed to migrate state: %v", err)
+	}
+	if root := m.migratedRef.Root(); root != expected {
+		t.Fatalf("migrated root mismatch: have %x, want %x", root, expected)
+	}
+	if root := m.migratedRef.Root(); root == types.EmptyRootHash {
+		t.Fatal("migrated root must not be empty")
+	}
+}
+
+func TestMigrateConcurrently(t *testing.T) {
+	alloc := testAlloc(100, 3, 150)
+	expected := migrateUninterrupted(t, alloc)
+
+	for _, workers := range []int{2, 4, 8} {
+		backend := newTestBackend(t, alloc)
+		header := backend.chain.Genesis().Header()
+		m := newTestMigrator(t, backend, workers)
+		if err := m.MigrateState(header); err != nil {
+			t.Fatalf("workers %d: failed to migrate state: %v", workers, err)
+		}
+		if root := m.migratedRef.Root(); root != expected {
+			t.Fatalf("workers %d: migrated root mismatch: have %x, want %x", workers, root, expected)
+		}
+	}
+}
+
+func TestMigrateConcurrentlyResumeFromCheckpoint(t *testing.T) {
+	tests := []struct {
+		name            string
+		killWorkers     int // Number of workers of the killed migration
+		resumeWorkers   int // Number of workers of the resumed migration
+		killAfter       int
+		expectPartition bool // Whether the checkpoint holds the partition progress
+	}{
+		{"concurrent", 4, 4, 3, true},
+		{"concurrent-to-sequential", 4, 1, 2, true},
+		{"sequential-to-concurrent", 1, 4, 4, false},
+	}
+	alloc := testAlloc(100, 3, 100)
+	expected := migrateUninterrupted(t, alloc)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := newTestBackend(t, alloc)
+			header := backend.chain.Genesis().Header()
+
+			killing := &killingDatabase{Database: backend.db, remaining: tt.killAfter}
+			m := newTestMigrator(t, &testBackend{db: killing, chain: backend.chain}, tt.killWorkers)
+			killing.kill = m.Stop
+			if err := m.MigrateState(header); !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected migration to be interrupted, got %v", err)
+			}
+			checkpoint := core.ReadMigrationCheckpoint(backend.db)
+			if checkpoint == nil {
+				t.Fatal("checkpoint not stored")
+			}
+			if hasPartitions := len(checkpoint.Partitions) > 0; hasPartitions != tt.expectPartition {
+				t.Fatalf("checkpoint partition progress mismatch: have %v, want %v", hasPartitions, tt.expectPartition)
+			}
+
+			m = newTestMigrator(t, backend, tt.resumeWorkers)
+			if err := m.MigrateState(header); err != nil {
+				t.Fatalf("failed to resume migration: %v", err)
+			}
+			if root := m.migratedRef.Root(); root != expected {
+				t.Fatalf("migrated root mismatch: have %x, want %x", root, expected)
+			}
+			if core.ReadMigrationCheckpoint(backend.db) != nil {
+				t.Fatal("checkpoint not deleted after migration")
+			}
+		})
+	}
+}
+
+// newTestChain creates a zktrie chain with the given number of blocks on top of
+// the genesis, keeping the states and the preimages of all blocks in the database.
+// The chain is left running and is to be stopped by the caller.
+func newTestChain(t *testing.T, alloc core.GenesisAlloc, blocks int) (*testBackend, []*types.Block) {
+	config := *params.TestChainConfig
+	config.Zktrie = true
+	var (
+		db          = rawdb.NewMemoryDatabase()
+		gspec       = &core.Genesis{Config: &config, Alloc: alloc}
+		engine      = ethash.NewFaker()
+		cacheConfig = core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	)
+	cacheConfig.TrieDirtyDisabled = true
+	cacheConfig.Preimages = true
+
+	_, chain, _ := core.GenerateChainWithGenesis(gspec, engine, blocks, nil)
+	bc, err := core.NewBlockChain(db, cacheConfig, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	if _, err := bc.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return &testBackend{db: db, chain: bc}, chain
+}
+
+func TestMigrateResumeAfterPruning(t *testing.T) {
+	alloc := testAlloc(20, 3, 100)
+
+	// Migrate the state of the first block, which is neither the genesis nor
+	// the pruning target, in one pass.
+	backend, blocks := newTestChain(t, alloc, 4)
+	m := newTestMigrator(t, backend, 1)
+	if err := m.MigrateState(blocks[0].Header()); err != nil {
+		t.Fatalf("failed to migrate state: %v", err)
+	}
+	expected := m.migratedRef.Root()
+	backend.chain.Stop()
+
+	backend, blocks = newTestChain(t, alloc, 4)
+	header := blocks[0].Header()
+	killing := &killingDatabase{Database: backend.db, remaining: 2}
+	m = newTestMigrator(t, &testBackend{db: killing, chain: backend.chain}, 1)
+	killing.kill = m.Stop
+	if err := m.MigrateState(header); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected migration to be interrupted, got %v", err)
+	}
+	if checkpoint := core.ReadMigrationCheckpoint(backend.db); checkpoint == nil || len(checkpoint.StorageAccountKey) == 0 {
+		t.Fatal("checkpoint of storage migration not stored")
+	}
+	// Prune the state of the interrupted node.
+	backend.chain.Stop()
+	p, err := pruner.NewPruner(backend.db, pruner.Config{Datadir: t.TempDir(), BloomSize: 256})
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if err := p.Prune(blocks[3].Root()); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	stale := common.BytesToHash(common.ReverseBytes(blocks[1].Root().Bytes()))
+	if rawdb.HasLegacyTrieNode(backend.db, stale) {
+		t.Fatal("stale state is not pruned")
+	}
+
+	// Restart the migrator, it must resume from the checkpoint on the pruned database.
+	m = newTestMigrator(t, backend, 1)
+	if err := m.MigrateState(header); err != nil {
+		t.Fatalf("failed to resume migration: %v", err)
+	}
+	if root := m.migratedRef.Root(); root != expected {
+		t.Fatalf("migrated root mismatch: have %x, want %x", root, expected)
+	}
+}
+
+// corruptMigratedState changes the balance of the given account in the migrated
+// state and returns the root of the corrupted state.
+func corruptMigratedState(t *testing.T, m *StateMigrator, root common.Hash, addr common.Address) common.Hash {
+	mpt, err := trie.NewStateTrie(trie.StateTrieID(root), m.mptdb)
+	if err != nil {
+		t.Fatalf("failed to open migrated state: %v", err)
+	}
+	account, err := mpt.GetAccount(addr)
+	if err != nil || account == nil {
+		t.Fatalf("failed to read account %s: %v", addr, err)
+	}
+	account.Balance = new(big.Int).Add(account.Balance, common.Big1)
+	if err := mpt.UpdateAccount(addr, account); err != nil {
+		t.Fatalf("failed to update account %s: %v", addr, err)
+	}
+	corrupted, err := m.commit(mpt, root)
+	if err != nil {
+		t.Fatalf("failed to commit corrupted state: %v", err)
+	}
+	return corrupted
+}
+
+func TestValidateStateWithSamples(t *testing.T) {
+	alloc := testAlloc(50, 3, 50)
+	backend := newTestBackend(t, alloc)
+	header := backend.chain.Genesis().Header()
+	m := newTestMigrator(t, backend, 1)
+	if err := m.MigrateState(header); err != nil {
+		t.Fatalf("failed to migrate state: %v", err)
+	}
+	root := m.migratedRef.Root()
+
+	if err := m.ValidateStateWithSamples(root, header.Root, 20, 10, 1); err != nil {
+		t.Fatalf("failed to validate migrated state: %v", err)
+	}
+	status := m.ValidationStatus()
+	if status.Mode != SampleValidation || status.Running || status.Accounts != 20 || status.Mismatches != 0 {
+		t.Fatalf("unexpected validation status: %+v", status)
+	}
+
+	// Every account is sampled with a high probability, the corrupted one must be found.
+	corrupted := corruptMigratedState(t, m, root, common.BigToAddress(big.NewInt(7)))
+	if err := m.ValidateStateWithSamples(corrupted, header.Root, 1000, 1, 1); !errors.Is(err, errStateMismatch) {
+		t.Fatalf("expected state mismatch, got %v", err)
+	}
+	if status := m.ValidationStatus(); status.Mismatches != 1 || status.Error == "" {
+		t.Fatalf("unexpected validation status: %+v", status)
+	}
+}
+
+func TestSampleLeafReproducible(t *testing.T) {
+	backend := newTestBackend(t, testAlloc(50, 0, 0))
+	m := newTestMigrator(t, backend, 1)
+	zkt, err := trie.NewZkMerkleStateTrie(backend.chain.Genesis().Root(), m.zktdb)
+	if err != nil {
+		t.Fatalf("failed to open zk state: %v", err)
+	}
+	samples := func(seed int64) [][]byte {
+		rng := rand.New(rand.NewSource(seed))
+		var keys [][]byte
+		for i := 0; i < 10; i++ {
+			key, _, err := sampleLeaf(zkt, rng)
+			if err != nil || key == nil {
+				t.Fatalf("failed to sample leaf: %v", err)
+			}
+			keys = append(keys, key)
+		}
+		return keys
+	}
+	if !reflect.DeepEqual(samples(1), samples(1)) {
+		t.Fatal("samples with the same seed differ")
+	}
+	if reflect.DeepEqual(samples(1), samples(2)) {
+		t.Fatal("samples with different seeds are equal")
+	}
+}
+
+func TestValidateStateMismatch(t *testing.T) {
+	alloc := testAlloc(50, 1, 20)
+	backend := newTestBackend(t, alloc)
+	header := backend.chain.Genesis().Header()
+	m := newTestMigrator(t, backend, 1)
+	if err := m.MigrateState(header); err != nil {
+		t.Fatalf("failed to migrate state: %v", err)
+	}
+	corrupted := corruptMigratedState(t, m, m.migratedRef.Root(), common.BigToAddress(big.NewInt(3)))
+	corrupted = corruptMigratedState(t, m, corrupted, common.BigToAddress(big.NewInt(30)))
+
+	// The full validation stops at the first mismatch.
+	if err := m.ValidateStateWithIterator(corrupted, header.Root); !errors.Is(err, errStateMismatch) {
+		t.Fatalf("expected state mismatch, got %v", err)
+	}
+	if status := m.ValidationStatus(); status.Mismatches != 1 {
+		t.Fatalf("mismatch count mismatch: have %d, want 1", status.Mismatches)
+	}
+
+	// The background validation reports every mismatch and goes on.
+	m.validateInBackground(corrupted, header.Root)
+	status := m.ValidationStatus()
+	if status.Mode != BackgroundValidation || status.Running || status.Error != "" {
+		t.Fatalf("unexpected validation status: %+v", status)
+	}
+	if status.Mismatches != 2 {
+		t.Fatalf("mismatch count mismatch: have %d, want 2", status.Mismatches)
+	}
+	if status.Accounts != hexutil.Uint64(len(alloc)) || status.Slots != 20 {
+		t.Fatalf("validated state mismatch: have %d/%d, want %d/%d", status.Accounts, status.Slots, len(alloc), 20)
+	}
+}
+
+func TestMigrateStateInBackgroundValidation(t *testing.T) {
+	backend := newTestBackend(t, testAlloc(20, 1, 20))
+	m, err := NewStateMigrator(backend, &Config{Validation: BackgroundValidation})
+	if err != nil {
+		t.Fatalf("failed to create state migrator: %v", err)
+	}
+	if err := m.MigrateState(backend.chain.Genesis().Header()); err != nil {
+		t.Fatalf("failed to migrate state: %v", err)
+	}
+	if m.migratedRef.Root() == (common.Hash{}) {
+		t.Fatal("migrated reference not updated before the background validation")
+	}
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		status := m.ValidationStatus()
+		if status.Mode == BackgroundValidation && !status.Running {
+			if status.Mismatches != 0 || status.Error != "" {
+				t.Fatalf("unexpected validation status: %+v", status)
+			}
+			break
+		}
+		if time.Since(start) > 10*time.Second {
+			t.Fatal("background validation not finished")
+		}
+	}
+}
+
+func TestNewStateMigratorUnknownValidation(t *testing.T) {
+	backend := newTestBackend(t, nil)
+	if _, err := NewStateMigrator(backend, &Config{Validation: "partial"}); err == nil {
+		t.Fatal("expected error for unknown validation mode")
+	}
+}
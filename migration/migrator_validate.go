@@ -2,13 +2,17 @@ package migration
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"sync/atomic"
 	"time"
 
 	zktrie "github.com/kroma-network/zktrie/types"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -16,7 +20,243 @@ import (
 	"github.com/ethereum/go-ethereum/trie"
 )
 
+const (
+	// backgroundValidationBatch is the number of accounts validated in the background
+	// before the validation yields to the rest of the node.
+	backgroundValidationBatch = 1000
+
+	// backgroundValidationPause is the time the background validation sleeps after
+	// every batch of validated accounts.
+	backgroundValidationPause = 100 * time.Millisecond
+)
+
+// errStateMismatch is returned if the migrated state differs from the zk state.
+var errStateMismatch = errors.New("migrated state mismatch")
+
+// ValidationStatus describes the progress of the latest validation of the migrated state.
+type ValidationStatus struct {
+	Mode         string         `json:"mode"`
+	Running      bool           `json:"running"`
+	Root         common.Hash    `json:"root"`
+	ZkRoot       common.Hash    `json:"zkRoot"`
+	Accounts     hexutil.Uint64 `json:"accounts"`
+	Slots        hexutil.Uint64 `json:"slots"`
+	Mismatches   hexutil.Uint64 `json:"mismatches"`
+	LastMismatch string         `json:"lastMismatch,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// ValidationStatus returns the progress of the latest validation of the migrated state.
+func (m *StateMigrator) ValidationStatus() ValidationStatus {
+	m.validationMu.Lock()
+	defer m.validationMu.Unlock()
+	return m.validation
+}
+
+func (m *StateMigrator) startValidation(mode string, mptRoot common.Hash, zkRoot common.Hash) {
+	m.validationMu.Lock()
+	defer m.validationMu.Unlock()
+	m.validation = ValidationStatus{Mode: mode, Running: true, Root: mptRoot, ZkRoot: zkRoot}
+	validationRunningGauge.Update(1)
+	validationAccountsGauge.Update(0)
+	validationSlotsGauge.Update(0)
+}
+
+func (m *StateMigrator) updateValidation(accounts, slots uint64) {
+	m.validationMu.Lock()
+	defer m.validationMu.Unlock()
+	m.validation.Accounts = hexutil.Uint64(accounts)
+	m.validation.Slots = hexutil.Uint64(slots)
+	validationAccountsGauge.Update(int64(accounts))
+	validationSlotsGauge.Update(int64(slots))
+}
+
+func (m *StateMigrator) recordMismatch(err error) {
+	m.validationMu.Lock()
+	defer m.validationMu.Unlock()
+	m.validation.Mismatches++
+	m.validation.LastMismatch = err.Error()
+	validationMismatchCounter.Inc(1)
+}
+
+func (m *StateMigrator) finishValidation(err error) {
+	m.validationMu.Lock()
+	defer m.validationMu.Unlock()
+	m.validation.Running = false
+	if err != nil {
+		m.validation.Error = err.Error()
+	}
+	validationRunningGauge.Update(0)
+}
+
+// ValidateStateWithIterator cross-checks every account and storage slot of the zk state
+// against the migrated MPT state, and returns an error on the first mismatch.
 func (m *StateMigrator) ValidateStateWithIterator(mptRoot common.Hash, zkRoot common.Hash) error {
+	m.startValidation(FullValidation, mptRoot, zkRoot)
+	err := m.validateState(mptRoot, zkRoot, func(err error) error {
+		m.recordMismatch(err)
+		return err
+	}, m.updateValidation)
+	m.finishValidation(err)
+	return err
+}
+
+// ValidateStateWithSamples cross-checks the given number of randomly picked accounts,
+// and the given number of randomly picked storage slots of each of them, against the
+// migrated MPT state. The samples are picked with replacement and are reproducible
+// with the same seed. A random seed is used if the given seed is zero.
+func (m *StateMigrator) ValidateStateWithSamples(mptRoot common.Hash, zkRoot common.Hash, accounts int, slots int, seed int64) error {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	log.Info("Validating migrated state with samples", "accounts", accounts, "slots", slots, "seed", seed)
+
+	m.startValidation(SampleValidation, mptRoot, zkRoot)
+	err := m.validateSamples(mptRoot, zkRoot, accounts, slots, rand.New(rand.NewSource(seed)))
+	if errors.Is(err, errStateMismatch) {
+		m.recordMismatch(err)
+	}
+	m.finishValidation(err)
+	return err
+}
+
+func (m *StateMigrator) validateSamples(mptRoot common.Hash, zkRoot common.Hash, accounts int, slots int, rng *rand.Rand) error {
+	mpt, err := trie.NewStateTrie(trie.StateTrieID(mptRoot), m.mptdb)
+	if err != nil {
+		log.Error("Failed to create state trie", "root", mptRoot, "err", err)
+		return err
+	}
+	zkt, err := trie.NewZkMerkleStateTrie(zkRoot, m.zktdb)
+	if err != nil {
+		log.Error("Failed to create zk state trie", "root", zkRoot, "err", err)
+		return err
+	}
+
+	var validatedSlots uint64
+	for i := 0; i < accounts; i++ {
+		key, value, err := sampleLeaf(zkt, rng)
+		if err != nil {
+			return err
+		}
+		if key == nil {
+			// The zk state is empty, the migrated state must be empty as well.
+			if mptRoot != types.EmptyRootHash {
+				return fmt.Errorf("%w: state root should be empty root hash. got %s", errStateMismatch, mptRoot)
+			}
+			return nil
+		}
+		zktAcc, err := types.NewStateAccount(value, true)
+		if err != nil {
+			log.Error("Invalid account encountered during sampling", "err", err)
+			return err
+		}
+		preimage, err := m.readZkPreimage(*trie.IteratorKeyToHash(key, true))
+		if err != nil {
+			return err
+		}
+		addr := common.BytesToAddress(preimage)
+		mptAcc, err := m.validateAccount(mpt, addr, zktAcc)
+		if err != nil {
+			return err
+		}
+
+		if zktAcc.Root != (common.Hash{}) {
+			id := trie.StorageTrieID(mptRoot, crypto.Keccak256Hash(addr.Bytes()), mptAcc.Root)
+			mptStorage, err := trie.NewStateTrie(id, m.mptdb)
+			if err != nil {
+				log.Error("Failed to create state trie", "root", mptAcc.Root, "err", err)
+				return err
+			}
+			zktStorage, err := trie.NewZkMerkleStateTrie(zktAcc.Root, m.zktdb)
+			if err != nil {
+				log.Error("Failed to create zk state trie", "root", zktAcc.Root, "err", err)
+				return err
+			}
+			for j := 0; j < slots; j++ {
+				key, value, err := sampleLeaf(zktStorage, rng)
+				if err != nil {
+					return err
+				}
+				if key == nil {
+					break
+				}
+				if err := m.validateSlot(mptStorage, addr, key, value); err != nil {
+					return err
+				}
+				validatedSlots++
+			}
+		}
+
+		m.updateValidation(uint64(i+1), validatedSlots)
+		select {
+		case <-m.ctx.Done():
+			return m.ctx.Err()
+		default:
+		}
+	}
+	return nil
+}
+
+// sampleLeaf returns the iterator key and value of a random leaf of the given zk trie.
+// The first leaf following a random key is picked, wrapping around to the first leaf
+// of the trie if there is none. Nil is returned if the trie is empty.
+func sampleLeaf(zkt *trie.ZkMerkleStateTrie, rng *rand.Rand) ([]byte, []byte, error) {
+	start := make([]byte, common.HashLength)
+	rng.Read(start)
+	for _, from := range [][]byte{start, nil} {
+		nodeIt, err := zkt.NodeIterator(from)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open node iterator (root: %s): %w", zkt.Hash(), err)
+		}
+		iter := trie.NewIterator(nodeIt)
+		if iter.Next() {
+			return iter.Key, iter.Value, nil
+		}
+		if iter.Err != nil {
+			return nil, nil, fmt.Errorf("failed to traverse state trie (root: %s): %w", zkt.Hash(), iter.Err)
+		}
+	}
+	return nil, nil, nil
+}
+
+// validateInBackground cross-checks every account and storage slot of the zk state
+// against the migrated MPT state at low priority. Unlike the full validation, mismatches
+// do not abort the validation, they are reported through the validation status instead.
+func (m *StateMigrator) validateInBackground(mptRoot common.Hash, zkRoot common.Hash) {
+	log.Info("Start background validation of migrated state", "root", mptRoot, "zkRoot", zkRoot)
+	m.startValidation(BackgroundValidation, mptRoot, zkRoot)
+	err := m.validateState(mptRoot, zkRoot, func(err error) error {
+		log.Error("Migrated state mismatch", "err", err)
+		m.recordMismatch(err)
+		return nil
+	}, func(accounts, slots uint64) {
+		m.updateValidation(accounts, slots)
+		if accounts%backgroundValidationBatch == 0 {
+			select {
+			case <-m.ctx.Done():
+			case <-time.After(backgroundValidationPause):
+			}
+		}
+	})
+	m.finishValidation(err)
+
+	status := m.ValidationStatus()
+	switch {
+	case errors.Is(err, context.Canceled):
+		log.Warn("Background validation of migrated state interrupted", "accounts", status.Accounts, "slots", status.Slots)
+	case err != nil:
+		log.Error("Background validation of migrated state failed", "err", err)
+	case status.Mismatches > 0:
+		log.Error("Background validation of migrated state found mismatches", "mismatches", status.Mismatches, "last", status.LastMismatch)
+	default:
+		log.Info("Migrated past state have been validated in background", "root", mptRoot, "accounts", status.Accounts, "slots", status.Slots)
+	}
+}
+
+// validateState walks the whole zk state and cross-checks it against the migrated MPT state.
+// Every mismatch is passed to onMismatch, the walk is aborted if it returns an error.
+// The optional progress callback is invoked after every validated account.
+func (m *StateMigrator) validateState(mptRoot common.Hash, zkRoot common.Hash, onMismatch func(error) error, progress func(accounts, slots uint64)) error {
 	var accounts atomic.Uint64
 	var slots atomic.Uint64
 
@@ -63,22 +303,17 @@ func (m *StateMigrator) ValidateStateWithIterator(mptRoot common.Hash, zkRoot co
 			return err
 		}
 		addr := common.BytesToAddress(preimage)
-		mptAcc, err := mpt.GetAccount(addr)
-		if err != nil || mptAcc == nil {
-			log.Error("Failed to get account in MPT", "address", addr, "err", err)
-			return err
-		}
-		if mptAcc.Balance.Cmp(zktAcc.Balance) != 0 {
-			return fmt.Errorf("account %s balance mismatch. expected %s, got %s", addr, zktAcc.Balance, mptAcc.Balance)
-		}
-		if mptAcc.Nonce != zktAcc.Nonce {
-			return fmt.Errorf("account %s nonce mismatch. expected %d, got %d", addr, zktAcc.Nonce, mptAcc.Nonce)
-		}
-		if !bytes.Equal(mptAcc.CodeHash, zktAcc.CodeHash) {
-			return fmt.Errorf("account %s codehash mismatch. expected %s, got %s", addr, common.BytesToHash(zktAcc.CodeHash), common.BytesToHash(mptAcc.CodeHash))
+		mptAcc, err := m.validateAccount(mpt, addr, zktAcc)
+		if err != nil {
+			if !errors.Is(err, errStateMismatch) {
+				return err
+			}
+			if err := onMismatch(err); err != nil {
+				return err
+			}
 		}
 
-		if zktAcc.Root != (common.Hash{}) {
+		if mptAcc != nil && zktAcc.Root != (common.Hash{}) {
 			id := trie.StorageTrieID(mptRoot, crypto.Keccak256Hash(addr.Bytes()), mptAcc.Root)
 			mptStorage, err := trie.NewStateTrie(id, m.mptdb)
 			if err != nil {
@@ -98,33 +333,30 @@ func (m *StateMigrator) ValidateStateWithIterator(mptRoot common.Hash, zkRoot co
 			}
 			iter := trie.NewIterator(nodeIt)
 			for iter.Next() {
-				hk := trie.IteratorKeyToHash(iter.Key, true)
-				preimage, err := m.readZkPreimage(*hk)
-				if err != nil {
-					return err
-				}
-				slot := common.BytesToHash(preimage).Bytes()
-				zktVal := common.TrimLeftZeroes(common.BytesToHash(iter.Value).Bytes())
-
-				mptVal, err := mptStorage.GetStorage(addr, slot)
-				if err != nil {
-					log.Error("Failed to get storage value in MPT", "err", err)
-					return err
-				}
-				if !bytes.Equal(mptVal, zktVal) {
-					return fmt.Errorf("account %s storage (slot: %s) mismatch. expected %s, got %s", addr, common.BytesToHash(slot), common.BytesToHash(zktVal), common.BytesToHash(mptVal))
+				if err := m.validateSlot(mptStorage, addr, iter.Key, iter.Value); err != nil {
+					if !errors.Is(err, errStateMismatch) {
+						return err
+					}
+					if err := onMismatch(err); err != nil {
+						return err
+					}
 				}
-
 				slots.Add(1)
 			}
 			if iter.Err != nil {
 				return fmt.Errorf("failed to traverse state trie (root: %s): %w", zktStorage.Hash(), iter.Err)
 			}
-		} else if mptAcc.Root != types.EmptyRootHash {
-			return fmt.Errorf("account %s root should be empty root hash. got %s", addr, mptAcc.Root)
 		}
 
 		accounts.Add(1)
+		if progress != nil {
+			progress(accounts.Load(), slots.Load())
+		}
+		select {
+		case <-m.ctx.Done():
+			return m.ctx.Err()
+		default:
+		}
 	}
 	if iter.Err != nil {
 		return fmt.Errorf("failed to traverse state trie (root: %s): %w", zkt.Hash(), iter.Err)
@@ -133,6 +365,54 @@ func (m *StateMigrator) ValidateStateWithIterator(mptRoot common.Hash, zkRoot co
 	return nil
 }
 
+// validateAccount compares the given zk account against the account stored in the MPT
+// and returns the MPT account. Any difference is reported as an errStateMismatch.
+func (m *StateMigrator) validateAccount(mpt *trie.StateTrie, addr common.Address, zktAcc *types.StateAccount) (*types.StateAccount, error) {
+	mptAcc, err := mpt.GetAccount(addr)
+	if err != nil {
+		log.Error("Failed to get account in MPT", "address", addr, "err", err)
+		return nil, err
+	}
+	if mptAcc == nil {
+		return nil, fmt.Errorf("%w: account %s is missing", errStateMismatch, addr)
+	}
+	if mptAcc.Balance.Cmp(zktAcc.Balance) != 0 {
+		return mptAcc, fmt.Errorf("%w: account %s balance mismatch. expected %s, got %s", errStateMismatch, addr, zktAcc.Balance, mptAcc.Balance)
+	}
+	if mptAcc.Nonce != zktAcc.Nonce {
+		return mptAcc, fmt.Errorf("%w: account %s nonce mismatch. expected %d, got %d", errStateMismatch, addr, zktAcc.Nonce, mptAcc.Nonce)
+	}
+	if !bytes.Equal(mptAcc.CodeHash, zktAcc.CodeHash) {
+		return mptAcc, fmt.Errorf("%w: account %s codehash mismatch. expected %s, got %s", errStateMismatch, addr, common.BytesToHash(zktAcc.CodeHash), common.BytesToHash(mptAcc.CodeHash))
+	}
+	if zktAcc.Root == (common.Hash{}) && mptAcc.Root != types.EmptyRootHash {
+		return mptAcc, fmt.Errorf("%w: account %s root should be empty root hash. got %s", errStateMismatch, addr, mptAcc.Root)
+	}
+	return mptAcc, nil
+}
+
+// validateSlot compares the zk storage leaf with the given iterator key and value against
+// the slot stored in the MPT. Any difference is reported as an errStateMismatch.
+func (m *StateMigrator) validateSlot(mptStorage *trie.StateTrie, addr common.Address, key []byte, value []byte) error {
+	hk := trie.IteratorKeyToHash(key, true)
+	preimage, err := m.readZkPreimage(*hk)
+	if err != nil {
+		return err
+	}
+	slot := common.BytesToHash(preimage).Bytes()
+	zktVal := common.TrimLeftZeroes(common.BytesToHash(value).Bytes())
+
+	mptVal, err := mptStorage.GetStorage(addr, slot)
+	if err != nil {
+		log.Error("Failed to get storage value in MPT", "err", err)
+		return err
+	}
+	if !bytes.Equal(mptVal, zktVal) {
+		return fmt.Errorf("%w: account %s storage (slot: %s) mismatch. expected %s, got %s", errStateMismatch, addr, common.BytesToHash(slot), common.BytesToHash(zktVal), common.BytesToHash(mptVal))
+	}
+	return nil
+}
+
 func (m *StateMigrator) ValidateNewState(num uint64, mptRoot common.Hash, stateChanges *core.StateChanges) error {
 	if num == 0 {
 		return fmt.Errorf("block number must bigger than 0")
@@ -0,0 +1,163 @@
+package migration
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Phases of the state migration.
+const (
+	PhaseWaiting    = "waiting"    // Waiting until the target block of the historical migration is safe
+	PhaseHistorical = "historical" // Migrating the state of the target block
+	PhaseValidating = "validating" // Validating the migrated state of the target block
+	PhaseFollowing  = "following"  // Applying the state changes of new safe blocks
+	PhaseFinished   = "finished"   // The KromaMPT fork has been reached
+)
+
+// phases lists the phases of the state migration. The phase gauge reports the
+// position of the current phase in this list, starting from one.
+var phases = []string{PhaseWaiting, PhaseHistorical, PhaseValidating, PhaseFollowing, PhaseFinished}
+
+// statusMetricsInterval is the interval the migration status metrics are updated at.
+const statusMetricsInterval = 10 * time.Second
+
+// MigrationStatus describes the progress of the state migration.
+type MigrationStatus struct {
+	Phase          string           `json:"phase"`
+	TargetNumber   hexutil.Uint64   `json:"targetNumber"`   // Block of the historical migration
+	MigratedNumber hexutil.Uint64   `json:"migratedNumber"` // Block of the latest migrated state
+	MigratedRoot   common.Hash      `json:"migratedRoot"`   // Root of the latest migrated state
+	Accounts       hexutil.Uint64   `json:"accounts"`       // Accounts migrated by the historical migration
+	Slots          hexutil.Uint64   `json:"slots"`          // Storage slots migrated since the historical migration started
+	Progress       float64          `json:"progress"`       // Completed fraction of the current phase
+	ETA            hexutil.Uint64   `json:"eta"`            // Estimated remaining seconds of the current phase
+	LastError      string           `json:"lastError,omitempty"`
+	Validation     ValidationStatus `json:"validation"`
+}
+
+// MigrationStatus returns the progress of the state migration.
+func (m *StateMigrator) MigrationStatus() MigrationStatus {
+	validation := m.ValidationStatus()
+
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	status := MigrationStatus{
+		Phase:          m.phase,
+		TargetNumber:   hexutil.Uint64(m.target),
+		MigratedNumber: hexutil.Uint64(m.migratedRef.BlockNumber()),
+		MigratedRoot:   m.migratedRef.Root(),
+		Accounts:       hexutil.Uint64(m.migratedAccounts.Load()),
+		Slots:          hexutil.Uint64(m.migratedSlots.Load()),
+		Validation:     validation,
+	}
+	if m.lastErr != nil {
+		status.LastError = m.lastErr.Error()
+	}
+
+	var start float64
+	switch m.phase {
+	case PhaseHistorical:
+		status.Progress, start = math.Float64frombits(m.progress.Load()), m.startProgress
+	case PhaseValidating:
+		if status.Accounts > 0 {
+			status.Progress = math.Min(float64(validation.Accounts)/float64(status.Accounts), 1)
+		}
+	case PhaseFollowing, PhaseFinished:
+		status.Progress = 1
+	}
+	// Extrapolate the remaining time from the progress made since the phase started.
+	if status.Progress > start && status.Progress < 1 {
+		elapsed := time.Since(m.phaseStart).Seconds()
+		status.ETA = hexutil.Uint64(elapsed * (1 - status.Progress) / (status.Progress - start))
+	}
+	return status
+}
+
+// setPhase switches the migration into the given phase.
+func (m *StateMigrator) setPhase(phase string) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	m.phase = phase
+	m.phaseStart = time.Now()
+	m.startProgress = 0
+	for i, p := range phases {
+		if p == phase {
+			phaseGauge.Update(int64(i + 1))
+		}
+	}
+}
+
+// setTarget records the target block of the historical migration.
+func (m *StateMigrator) setTarget(number uint64) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	m.target = number
+	targetGauge.Update(int64(number))
+}
+
+// setError records the latest error of the migration.
+func (m *StateMigrator) setError(err error) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	m.lastErr = err
+	errorCounter.Inc(1)
+}
+
+// resetProgress initializes the progress of the historical migration, possibly
+// resumed with the given number of migrated accounts and the given progress.
+func (m *StateMigrator) resetProgress(accounts uint64, progress float64) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	m.migratedAccounts.Store(accounts)
+	m.migratedSlots.Store(0)
+	m.progress.Store(math.Float64bits(progress))
+	m.phaseStart = time.Now()
+	m.startProgress = progress
+}
+
+// reportProgress records the progress of the historical migration.
+func (m *StateMigrator) reportProgress(progress float64) {
+	m.progress.Store(math.Float64bits(progress))
+}
+
+// updateStatusMetrics reports the migration status into the metrics registry.
+func (m *StateMigrator) updateStatusMetrics() {
+	status := m.MigrationStatus()
+	migratedGauge.Update(int64(status.MigratedNumber))
+	accountsGauge.Update(int64(status.Accounts))
+	slotsGauge.Update(int64(status.Slots))
+	etaGauge.Update(int64(status.ETA))
+}
+
+// keyProgress returns the fraction of the zk trie key space preceding the given
+// iterator key. As the keys are hashes, it approximates the fraction of leaves
+// preceding the key.
+func keyProgress(key []byte) float64 {
+	if len(key) == 0 {
+		return 0
+	}
+	var prefix [8]byte
+	copy(prefix[:], key)
+	return float64(binary.BigEndian.Uint64(prefix[:])) / math.Pow(2, 64)
+}
+
+// partitionProgress returns the fraction of the zk trie key space preceding the
+// given partition watermarks.
+func partitionProgress(watermarks [][]byte) float64 {
+	var progress float64
+	for i, key := range watermarks {
+		if len(key) > 0 {
+			progress += keyProgress(key) - float64(i)/partitions
+		}
+	}
+	return progress
+}
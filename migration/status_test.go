@@ -0,0 +1,91 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestKeyProgress(t *testing.T) {
+	tests := []struct {
+		key  []byte
+		want float64
+	}{
+		{nil, 0},
+		{make([]byte, common.HashLength), 0},
+		{common.Hex2Bytes("80"), 0.5},
+		{common.Hex2Bytes("c0"), 0.75},
+		{bytes.Repeat([]byte{0xff}, common.HashLength), 1},
+	}
+	for _, tt := range tests {
+		if have := keyProgress(tt.key); math.Abs(have-tt.want) > 1e-9 {
+			t.Errorf("key %x: progress mismatch: have %f, want %f", tt.key, have, tt.want)
+		}
+	}
+}
+
+func TestPartitionProgress(t *testing.T) {
+	watermarks := make([][]byte, partitions)
+	if have := partitionProgress(watermarks); have != 0 {
+		t.Fatalf("progress mismatch: have %f, want 0", have)
+	}
+	// Half of the first partition, all of the second one.
+	watermarks[0] = common.Hex2Bytes("08")
+	watermarks[1] = partitionEnd(1)
+	want := 1.5 / partitions
+	if have := partitionProgress(watermarks); math.Abs(have-want) > 1e-9 {
+		t.Fatalf("progress mismatch: have %f, want %f", have, want)
+	}
+}
+
+func TestMigrationStatus(t *testing.T) {
+	alloc := testAlloc(50, 2, 30)
+	backend := newTestBackend(t, alloc)
+	header := backend.chain.Genesis().Header()
+	m := newTestMigrator(t, backend, 1)
+	if status := m.MigrationStatus(); status.Phase != PhaseWaiting {
+		t.Fatalf("phase mismatch: have %s, want %s", status.Phase, PhaseWaiting)
+	}
+	if err := m.MigrateState(header); err != nil {
+		t.Fatalf("failed to migrate state: %v", err)
+	}
+	status := m.MigrationStatus()
+	if status.Phase != PhaseValidating || status.Progress != 1 || status.ETA != 0 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if status.Accounts != hexutil.Uint64(len(alloc)) || status.Slots != 60 {
+		t.Fatalf("migrated state mismatch: have %d/%d, want %d/%d", status.Accounts, status.Slots, len(alloc), 60)
+	}
+	if status.MigratedRoot != m.migratedRef.Root() || status.MigratedNumber != 0 {
+		t.Fatalf("migrated reference mismatch: have %d/%x", status.MigratedNumber, status.MigratedRoot)
+	}
+
+	// The remaining time is extrapolated from the progress since the phase started.
+	m.setPhase(PhaseHistorical)
+	m.resetProgress(10, 0.2)
+	m.phaseStart = time.Now().Add(-10 * time.Second)
+	m.reportProgress(0.4)
+	if status := m.MigrationStatus(); status.ETA != 30 {
+		t.Fatalf("eta mismatch: have %d, want 30", status.ETA)
+	}
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/migration"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
@@ -82,6 +83,11 @@ var (
 	errBlockInterruptedByRecommit = errors.New("recommit interrupt while building block")
 	errBlockInterruptedByTimeout  = errors.New("timeout while building block")
 	errBlockInterruptedByResolve  = errors.New("payload resolution while building block")
+
+	// [Kroma: START]
+	txConditionalRejectedCounter = metrics.NewRegisteredCounter("miner/transactionConditional/rejected", nil)
+	txConditionalMinedTimer      = metrics.NewRegisteredTimer("miner/transactionConditional/elapsedtime", nil)
+	// [Kroma: END]
 )
 
 // environment is the worker's current environment and holds all
@@ -909,6 +915,19 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 			continue
 		}
 		// [Scroll: END]
+		// [Kroma: START]
+		// Drop the transaction and the ones following it if its conditional does not
+		// hold, marking it to be removed from the transaction pool.
+		if cond := tx.Conditional(); cond != nil {
+			if err := checkTransactionConditional(env, cond); err != nil {
+				log.Debug("Skipping transaction with rejected conditional", "hash", ltx.Hash, "err", err)
+				txConditionalRejectedCounter.Inc(1)
+				tx.SetRejected()
+				txs.Pop()
+				continue
+			}
+		}
+		// [Kroma: END]
 		// Error may be ignored here. The error has already been checked
 		// during transaction acceptance is the transaction pool.
 		from, _ := types.Sender(env.signer, tx)
@@ -936,6 +955,11 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 			env.tcount++
 			env.blockSize += int(tx.Size())
 			txs.Shift()
+			// [Kroma: START]
+			if tx.Conditional() != nil {
+				txConditionalMinedTimer.UpdateSince(tx.Time())
+			}
+			// [Kroma: END]
 
 		default:
 			// Transaction is regarded as invalid, drop all consecutive transactions from
@@ -962,6 +986,22 @@ func (w *worker) commitTransactions(env *environment, txs *transactionsByPriceAn
 	return nil
 }
 
+// [Kroma: START]
+
+// checkTransactionConditional checks the conditional of a transaction against the block
+// being built, and the state after the transactions committed so far.
+func checkTransactionConditional(env *environment, cond *types.TransactionConditional) error {
+	if err := cond.CheckBlockNumber(env.header.Number); err != nil {
+		return err
+	}
+	if err := cond.CheckTimestamp(env.header.Time); err != nil {
+		return err
+	}
+	return env.state.CheckTransactionConditional(cond)
+}
+
+// [Kroma: END]
+
 // generateParams wraps various of settings for generating sealing task.
 type generateParams struct {
 	timestamp   uint64            // The timstamp for sealing task
@@ -508,3 +508,66 @@ func testGetSealingWork(t *testing.T, chainConfig *params.ChainConfig, engine co
 		}
 	}
 }
+
+func TestTransactionConditional(t *testing.T) {
+	t.Parallel()
+
+	signer := types.LatestSigner(ethashChainConfig)
+	newTx := func(cond *types.TransactionConditional) *types.Transaction {
+		tx := types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    1,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: big.NewInt(10 * params.InitialBaseFee),
+		})
+		tx.SetConditional(cond)
+		return tx
+	}
+	var (
+		slot  = common.Hash{0x01}
+		one   = big.NewInt(1)
+		cases = []struct {
+			cond     *types.TransactionConditional
+			included bool
+		}{
+			{&types.TransactionConditional{BlockNumberMin: one, BlockNumberMax: one}, true},
+			{&types.TransactionConditional{BlockNumberMax: common.Big0}, false},
+			{&types.TransactionConditional{KnownAccounts: types.KnownAccounts{
+				testUserAddress: {StorageSlots: map[common.Hash]common.Hash{slot: {}}},
+			}}, true},
+			{&types.TransactionConditional{KnownAccounts: types.KnownAccounts{
+				testUserAddress: {StorageSlots: map[common.Hash]common.Hash{slot: {0x01}}},
+			}}, false},
+			{&types.TransactionConditional{KnownAccounts: types.KnownAccounts{
+				testUserAddress: {StorageRoot: &types.EmptyRootHash},
+			}}, true},
+			{&types.TransactionConditional{KnownAccounts: types.KnownAccounts{
+				testUserAddress: {StorageRoot: &common.Hash{0x01}},
+			}}, false},
+		}
+	)
+	for i, c := range cases {
+		w, b := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+		tx := newTx(c.cond)
+		if err := b.txPool.Add([]*types.Transaction{tx}, true, true)[0]; err != nil {
+			t.Fatalf("case %d: failed to add transaction: %v", i, err)
+		}
+		r := w.getSealingBlock(&generateParams{
+			parentHash: b.chain.CurrentBlock().Hash(),
+			timestamp:  uint64(time.Now().Unix()),
+			coinbase:   testBankAddress,
+			forceTime:  true,
+		})
+		w.close()
+		if r.err != nil {
+			t.Fatalf("case %d: failed to build block: %v", i, r.err)
+		}
+		if included := len(r.block.Transactions()) == len(pendingTxs)+1; included != c.included {
+			t.Errorf("case %d: inclusion mismatch: have %v, want %v", i, included, c.included)
+		}
+		if tx.Rejected() == c.included {
+			t.Errorf("case %d: rejection mismatch: have %v, want %v", i, tx.Rejected(), !c.included)
+		}
+	}
+}
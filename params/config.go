@@ -340,6 +340,7 @@ type ChainConfig struct {
 	CanyonTime   *uint64  `json:"canyonTime,omitempty"`   // Canyon switch time (nil = no fork, 0 = already on optimism canyon)
 	// Delta: the Delta upgrade does not affect the execution-layer, and is thus not configurable in the chain config.
 	EcotoneTime *uint64 `json:"ecotoneTime,omitempty"` // Ecotone switch time (nil = no fork, 0 = already on optimism ecotone)
+	FjordTime   *uint64 `json:"fjordTime,omitempty"`   // Fjord switch time (nil = no fork, 0 = already on optimism fjord)
 
 	InteropTime *uint64 `json:"interopTime,omitempty"` // Interop switch time (nil = no fork, 0 = already on optimism interop)
 
@@ -519,6 +520,9 @@ func (c *ChainConfig) Description() string {
 	if c.EcotoneTime != nil {
 		banner += fmt.Sprintf(" - Ecotone:                     @%-10v\n", *c.EcotoneTime)
 	}
+	if c.FjordTime != nil {
+		banner += fmt.Sprintf(" - Fjord:                       @%-10v\n", *c.FjordTime)
+	}
 	if c.InteropTime != nil {
 		banner += fmt.Sprintf(" - Interop:                     @%-10v\n", *c.InteropTime)
 	}
@@ -645,6 +649,10 @@ func (c *ChainConfig) IsEcotone(time uint64) bool {
 	return isTimestampForked(c.EcotoneTime, time)
 }
 
+func (c *ChainConfig) IsFjord(time uint64) bool {
+	return isTimestampForked(c.FjordTime, time)
+}
+
 func (c *ChainConfig) IsInterop(time uint64) bool {
 	return isTimestampForked(c.InteropTime, time)
 }
@@ -675,6 +683,10 @@ func (c *ChainConfig) IsOptimismEcotone(time uint64) bool {
 	return c.IsKroma() && c.IsEcotone(time)
 }
 
+func (c *ChainConfig) IsOptimismFjord(time uint64) bool {
+	return c.IsKroma() && c.IsFjord(time)
+}
+
 // IsOptimismPreBedrock returns true iff this is an optimism node & bedrock is not yet active
 func (c *ChainConfig) IsOptimismPreBedrock(num *big.Int) bool {
 	return c.IsKroma() && !c.IsBedrock(num)
@@ -690,6 +702,16 @@ func (c *ChainConfig) IsKromaMPTActivationBlock(time uint64) bool {
 	return c.KromaMPTTime != nil && *c.KromaMPTTime == time
 }
 
+// IsZkState returns true if the state of the block with the given timestamp is
+// held in the zk trie instead of the MPT. The zktrie flag is cleared once the
+// KromaMPT fork is activated, so a scheduled fork implies the zk trie before it.
+func (c *ChainConfig) IsZkState(time uint64) bool {
+	if c.IsKromaMPT(time) {
+		return false
+	}
+	return c.Zktrie || c.KromaMPTTime != nil
+}
+
 // [Scroll: START]
 
 // IsValidTxCount returns whether the given block's transaction count is below the limit.
@@ -2,8 +2,12 @@ package params
 
 import (
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -12,27 +16,34 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// KromaChainConfig is the set of hardfork times and chain parameters of a Kroma
+// chain, which are either bundled or loaded from an external chain registry.
 type KromaChainConfig struct {
-	CanyonTime   *uint64
-	EcotoneTime  *uint64
-	KromaMPTTime *uint64
+	GenesisHash       *common.Hash `json:"genesisHash,omitempty"` // Expected genesis hash (nil = not checked)
+	CanyonTime        *uint64      `json:"canyonTime,omitempty"`
+	EcotoneTime       *uint64      `json:"ecotoneTime,omitempty"`
+	FjordTime         *uint64      `json:"fjordTime,omitempty"`
+	KromaMPTTime      *uint64      `json:"kromaMptTime,omitempty"`
+	EIP1559Elasticity uint64       `json:"eip1559Elasticity,omitempty"` // 0 = default elasticity
 }
 
-var KromaChainConfigs = map[uint64]*KromaChainConfig{
+var KromaChainConfigs = KromaChainRegistry{
 	KromaMainnetChainID: {
 		CanyonTime:   uint64ptr(1708502400),
 		EcotoneTime:  uint64ptr(1714032001),
 		KromaMPTTime: uint64ptr(1739250001),
 	},
 	KromaSepoliaChainID: {
-		CanyonTime:   uint64ptr(1707897600),
-		EcotoneTime:  uint64ptr(1713340800),
-		KromaMPTTime: uint64ptr(1737090000),
+		CanyonTime:        uint64ptr(1707897600),
+		EcotoneTime:       uint64ptr(1713340800),
+		KromaMPTTime:      uint64ptr(1737090000),
+		EIP1559Elasticity: 10,
 	},
 	KromaDevnetChainID: {
-		CanyonTime:   uint64ptr(1707292800),
-		EcotoneTime:  uint64ptr(1712908800),
-		KromaMPTTime: uint64ptr(1735794000),
+		CanyonTime:        uint64ptr(1707292800),
+		EcotoneTime:       uint64ptr(1712908800),
+		KromaMPTTime:      uint64ptr(1735794000),
+		EIP1559Elasticity: 10,
 	},
 }
 
@@ -61,8 +72,67 @@ func OPStackChainNames() (out []string) {
 	return
 }
 
+// LoadKromaChainConfig returns the chain config of the bundled Kroma chain with
+// the given chain id.
 func LoadKromaChainConfig(chainID uint64) (*ChainConfig, error) {
-	kromaChainConfig, ok := KromaChainConfigs[chainID]
+	return KromaChainRegistry(nil).LoadChainConfig(chainID)
+}
+
+// KromaChainRegistry is a set of Kroma chain configs, keyed by chain id.
+type KromaChainRegistry map[uint64]*KromaChainConfig
+
+// LoadKromaChainRegistry reads the Kroma chain configs from the given JSON file,
+// which maps chain ids to chain configs. If the path is a directory, all the
+// JSON files in it are read and merged. The loaded chain configs are validated.
+func LoadKromaChainRegistry(path string) (KromaChainRegistry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+	registry := make(KromaChainRegistry)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var chains KromaChainRegistry
+		if err := json.Unmarshal(data, &chains); err != nil {
+			return nil, fmt.Errorf("invalid kroma chain registry %s: %w", file, err)
+		}
+		for chainID, config := range chains {
+			if _, ok := registry[chainID]; ok {
+				return nil, fmt.Errorf("duplicate kroma chain %d in %s", chainID, file)
+			}
+			if err := config.validate(chainID); err != nil {
+				return nil, fmt.Errorf("invalid kroma chain %d in %s: %w", chainID, file, err)
+			}
+			registry[chainID] = config
+		}
+	}
+	return registry, nil
+}
+
+// Lookup returns the config of the Kroma chain with the given chain id. The chains
+// in the registry take precedence over the bundled ones.
+func (r KromaChainRegistry) Lookup(chainID uint64) (*KromaChainConfig, bool) {
+	if config, ok := r[chainID]; ok {
+		return config, true
+	}
+	config, ok := KromaChainConfigs[chainID]
+	return config, ok
+}
+
+// LoadChainConfig returns the chain config of the Kroma chain with the given chain
+// id, looked up in the registry first and in the bundled chains next.
+func (r KromaChainRegistry) LoadChainConfig(chainID uint64) (*ChainConfig, error) {
+	kromaChainConfig, ok := r.Lookup(chainID)
 	if !ok {
 		return nil, fmt.Errorf("unknown chain id %d", chainID)
 	}
@@ -93,6 +163,7 @@ func LoadKromaChainConfig(chainID uint64) (*ChainConfig, error) {
 		RegolithTime:                  &genesisActivation,
 		CanyonTime:                    kromaChainConfig.CanyonTime,
 		EcotoneTime:                   kromaChainConfig.EcotoneTime,
+		FjordTime:                     kromaChainConfig.FjordTime,
 		KromaMPTTime:                  kromaChainConfig.KromaMPTTime,
 		TerminalTotalDifficulty:       common.Big0,
 		TerminalTotalDifficultyPassed: true,
@@ -108,18 +179,47 @@ func LoadKromaChainConfig(chainID uint64) (*ChainConfig, error) {
 
 	// [Kroma: START]
 	// special overrides for Kroma chains
-	switch chainID {
-	case KromaSepoliaChainID:
-		out.Kroma.EIP1559Elasticity = 10
-	case KromaMainnetChainID:
-	case KromaDevnetChainID:
-		out.Kroma.EIP1559Elasticity = 10
+	if kromaChainConfig.EIP1559Elasticity != 0 {
+		out.Kroma.EIP1559Elasticity = kromaChainConfig.EIP1559Elasticity
 	}
 	// [Kroma: END]
 
 	return out, nil
 }
 
+// validate checks that the hardforks of the chain config are scheduled in order.
+// Fjord only depends on Ecotone, so it can be scheduled regardless of Kroma MPT.
+func (c *KromaChainConfig) validate(chainID uint64) error {
+	if chainID == 0 {
+		return errors.New("zero chain id")
+	}
+	if c == nil {
+		return errors.New("missing chain config")
+	}
+	type fork struct {
+		name string
+		time *uint64
+	}
+	for _, forks := range [][]fork{
+		{{"canyonTime", c.CanyonTime}, {"ecotoneTime", c.EcotoneTime}, {"kromaMptTime", c.KromaMPTTime}},
+		{{"canyonTime", c.CanyonTime}, {"ecotoneTime", c.EcotoneTime}, {"fjordTime", c.FjordTime}},
+	} {
+		for i := 1; i < len(forks); i++ {
+			last, cur := forks[i-1], forks[i]
+			if cur.time == nil {
+				continue
+			}
+			if last.time == nil {
+				return fmt.Errorf("unsupported fork ordering: %v not enabled, but %v enabled at %v", last.name, cur.name, *cur.time)
+			}
+			if *last.time > *cur.time {
+				return fmt.Errorf("unsupported fork ordering: %v enabled at %v, but %v enabled at %v", last.name, *last.time, cur.name, *cur.time)
+			}
+		}
+	}
+	return nil
+}
+
 // ProtocolVersion encodes the OP-Stack protocol version. See OP-Stack superchain-upgrade specification.
 type ProtocolVersion [32]byte
 
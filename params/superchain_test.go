@@ -2,6 +2,8 @@ package params
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -170,3 +172,57 @@ func TestProtocolVersion_String(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadKromaChainRegistry(t *testing.T) {
+	dir := t.TempDir()
+	write := func(dir, name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	path := write(dir, "a.json", `{"12345": {"canyonTime": 10, "ecotoneTime": 20, "kromaMptTime": 30}}`)
+	write(dir, "b.json", `{"`+fmt.Sprint(KromaDevnetChainID)+`": {"canyonTime": 0, "ecotoneTime": 0, "eip1559Elasticity": 4}}`)
+
+	registry, err := LoadKromaChainRegistry(path)
+	if err != nil {
+		t.Fatalf("failed to load registry: %v", err)
+	}
+	if len(registry) != 1 || *registry[12345].KromaMPTTime != 30 {
+		t.Fatalf("unexpected registry: %v", registry)
+	}
+	// Load the directory, the registry overrides the bundled devnet config
+	if registry, err = LoadKromaChainRegistry(dir); err != nil {
+		t.Fatalf("failed to load registry directory: %v", err)
+	}
+	config, err := registry.LoadChainConfig(KromaDevnetChainID)
+	if err != nil {
+		t.Fatalf("failed to load chain config: %v", err)
+	}
+	if *config.CanyonTime != 0 || config.KromaMPTTime != nil || config.Kroma.EIP1559Elasticity != 4 {
+		t.Fatalf("registry not applied: %v", config)
+	}
+	if config, err = registry.LoadChainConfig(KromaMainnetChainID); err != nil || *config.KromaMPTTime != *KromaChainConfigs[KromaMainnetChainID].KromaMPTTime {
+		t.Fatalf("bundled chain config not loaded: %v", err)
+	}
+	if _, err := registry.LoadChainConfig(1); err == nil {
+		t.Fatal("expected unknown chain error")
+	}
+	// Invalid registries are rejected
+	invalid := t.TempDir()
+	for name, data := range map[string]string{
+		"order.json":     `{"1": {"canyonTime": 20, "ecotoneTime": 10}}`,
+		"missing.json":   `{"1": {"ecotoneTime": 10}}`,
+		"zero.json":      `{"0": {"canyonTime": 10}}`,
+		"malformed.json": `{"1": {"canyonTime": "soon"}}`,
+	} {
+		if _, err := LoadKromaChainRegistry(write(invalid, name, data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	write(dir, "c.json", `{"12345": {"canyonTime": 10}}`)
+	if _, err := LoadKromaChainRegistry(dir); err == nil {
+		t.Fatal("expected duplicate chain error")
+	}
+}
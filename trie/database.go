@@ -128,11 +128,7 @@ func NewDatabase(diskdb ethdb.Database, config *Config) *Database {
 		log.Crit("Both 'hash' and 'path' mode are configured")
 	}
 	if config.PathDB != nil {
-		if config.Zktrie {
-			log.Crit("pbss does not support in zktrie")
-		} else {
-			db.backend = pathdb.New(diskdb, config.PathDB)
-		}
+		db.backend = newPathBackend(diskdb, config.PathDB, config.Zktrie)
 	} else {
 		if config.Zktrie {
 			db.backend = hashdb.NewZk(diskdb, config.HashDB)
@@ -143,6 +139,17 @@ func NewDatabase(diskdb ethdb.Database, config *Config) *Database {
 	return db
 }
 
+// newPathBackend initializes the path-based database, holding zk tries if isZk
+// is set.
+func newPathBackend(diskdb ethdb.Database, config *pathdb.Config, isZk bool) *pathdb.Database {
+	if config.Zktrie != isZk {
+		conf := *config
+		conf.Zktrie = isZk
+		config = &conf
+	}
+	return pathdb.New(diskdb, config)
+}
+
 // Reader returns a reader for accessing all trie nodes with provided state root.
 // An error will be returned if the requested state is not available.
 func (db *Database) Reader(blockRoot common.Hash) (Reader, error) {
@@ -347,7 +354,7 @@ func (db *Database) IsVerkle() bool {
 }
 
 func (db *Database) UpdatePreimage(preimage []byte, hashField *big.Int) {
-	if _, ok := db.backend.(*hashdb.ZktrieDatabase); !ok {
+	if !db.IsZk() {
 		log.Error("non zkTrie database UpdatePreimage does not support ")
 		return
 	}
@@ -375,8 +382,13 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 	return zdb.Get(key)
 }
 
-func (db *Database) IsZk() bool      { return db.config.Zktrie }
-func (db *Database) IsKromaZK() bool { return db.config.Zktrie && db.config.KromaZKTrie }
+func (db *Database) IsZk() bool { return db.config.Zktrie }
+
+// IsKromaZK returns whether the zk tries are ZkMerkleStateTrie. It is always the
+// case with the path-based scheme, which isn't supported by the legacy ZkTrie.
+func (db *Database) IsKromaZK() bool {
+	return db.config.Zktrie && (db.config.KromaZKTrie || db.config.PathDB != nil)
+}
 
 func (db *Database) SetBackend(isZk bool) {
 	if db.config.Zktrie == isZk {
@@ -390,11 +402,12 @@ func (db *Database) SetBackend(isZk bool) {
 		KromaZKTrie: db.config.KromaZKTrie,
 	}
 	if db.config.PathDB != nil {
-		if isZk {
-			log.Crit("pbss does not support in zktrie")
-		} else {
-			db.backend = pathdb.New(db.diskdb, db.config.PathDB)
+		// Only one writable path-based database can be opened at the same time,
+		// release the state history freezer held by the current one first.
+		if err := db.backend.Close(); err != nil {
+			log.Error("Failed to close trie database", "err", err)
 		}
+		db.backend = newPathBackend(db.diskdb, db.config.PathDB, isZk)
 	} else {
 		if isZk {
 			db.backend = hashdb.NewZk(db.diskdb, db.config.HashDB)
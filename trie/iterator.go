@@ -809,8 +809,8 @@ type merkleTreeIterator struct {
 	err  error
 	// nodeBlob browse for the BLOB in a non-storage layer. This is not a required component.
 	nodeBlob NodeResolver
-	// findNodeBlobByHash finds a tree blob in the real persistent layer. This is a required component.
-	findNodeBlobByHash     func(hash common.Hash) ([]byte, error)
+	// findNodeBlob finds a tree blob by its path and hash in the real persistent layer. This is a required component.
+	findNodeBlob           func(path []byte, hash common.Hash) ([]byte, error)
 	nodeBlobToIteratorNode func(hash common.Hash, blob []byte) (merkleTreeIteratorNode, error)
 }
 
@@ -848,18 +848,19 @@ func (n *merkleTreeIteratorParentNode) Blob() []byte      { return n.blob }
 func (n *merkleTreeIteratorLeafNode) Hash() common.Hash { return n.hash }
 func (n *merkleTreeIteratorLeafNode) Blob() []byte      { return n.blob }
 
-// zkMerkleTreeNodeBlobFunctions generates the merkleTreeIterator.findNodeBlobByHash
+// zkMerkleTreeNodeBlobFunctions generates the merkleTreeIterator.findNodeBlob
 // and merkleTreeIterator.nodeBlobToIteratorNode functions of a merkleTreeIterator to traverse the [zk.MerkleTree].
+// findBlob reads a node by its path and the hash in the byte order of the tree.
 // To create a merkleTreeIterator, see newMerkleTreeIterator.
-func zkMerkleTreeNodeBlobFunctions(findBlobByHash func(key []byte) ([]byte, error)) (
-	func(hash common.Hash) ([]byte, error),
+func zkMerkleTreeNodeBlobFunctions(findBlob func(path []byte, key []byte) ([]byte, error)) (
+	func(path []byte, hash common.Hash) ([]byte, error),
 	func(hash common.Hash, blob []byte) (merkleTreeIteratorNode, error),
 ) {
-	return func(hash common.Hash) ([]byte, error) {
+	return func(path []byte, hash common.Hash) ([]byte, error) {
 			if bytes.Equal(hash.Bytes(), zkt.HashZero[:]) {
 				return zk.EmptyNodeValue.CanonicalValue(), nil
 			}
-			return findBlobByHash(zkt.ReverseByteOrder(hash.Bytes()))
+			return findBlob(path, zkt.ReverseByteOrder(hash.Bytes()))
 		},
 		func(hash common.Hash, blob []byte) (merkleTreeIteratorNode, error) {
 			node, err := zk.NewTreeNodeFromBlob(blob)
@@ -888,14 +889,14 @@ func zkMerkleTreeNodeBlobFunctions(findBlobByHash func(key []byte) ([]byte, erro
 		}
 }
 
-// zktrieNodeBlobFunctions generates the merkleTreeIterator.findNodeBlobByHash
+// zktrieNodeBlobFunctions generates the merkleTreeIterator.findNodeBlob
 // and merkleTreeIterator.nodeBlobToIteratorNode functions of a merkleTreeIterator to traverse the [zktrie.ZkTrie].
 // To create a merkleTreeIterator, see newMerkleTreeIterator.
 func zktrieNodeBlobFunctions(t *zktrie.ZkTrie) (
-	func(hash common.Hash) ([]byte, error),
+	func(path []byte, hash common.Hash) ([]byte, error),
 	func(hash common.Hash, blob []byte) (merkleTreeIteratorNode, error),
 ) {
-	return func(hash common.Hash) ([]byte, error) {
+	return func(_ []byte, hash common.Hash) ([]byte, error) {
 			node, err := t.Tree().GetNode(zkt.NewHashFromBytes(hash.Bytes()))
 			if err != nil {
 				return nil, err
@@ -934,14 +935,14 @@ func zktrieNodeBlobFunctions(t *zktrie.ZkTrie) (
 // bytes in the start parameter can only be 0 or 1.
 func newMerkleTreeIterator(
 	root common.Hash,
-	findNodeBlobByHash func(hash common.Hash) ([]byte, error),
+	findNodeBlob func(path []byte, hash common.Hash) ([]byte, error),
 	nodeBlobToIteratorNode func(hash common.Hash, blob []byte) (merkleTreeIteratorNode, error),
 	start []byte,
 ) *merkleTreeIterator {
-	it := &merkleTreeIterator{findNodeBlobByHash: findNodeBlobByHash, nodeBlobToIteratorNode: nodeBlobToIteratorNode}
+	it := &merkleTreeIterator{findNodeBlob: findNodeBlob, nodeBlobToIteratorNode: nodeBlobToIteratorNode}
 	var rootNode merkleTreeIteratorNode
 	var blob []byte
-	blob, it.err = findNodeBlobByHash(root)
+	blob, it.err = findNodeBlob(nil, root)
 	if it.err == nil {
 		rootNode, it.err = nodeBlobToIteratorNode(root, blob)
 	}
@@ -957,7 +958,7 @@ func newMerkleTreeIterator(
 func (it *merkleTreeIterator) seek(path zk.TreePath) {
 	for _, p := range path {
 		if parent, ok := it.stack[len(it.stack)-1].(*merkleTreeIteratorParentNode); ok {
-			if child := it.resolveNode(parent.children[p]); child != nil {
+			if child := it.resolveNode(it.childPath(p), parent.children[p]); child != nil {
 				it.stack = append(it.stack, child)
 				it.path = append(it.path, p)
 				continue
@@ -1017,7 +1018,7 @@ func (it *merkleTreeIterator) seek(path zk.TreePath) {
 	lastIdx := len(it.path) - 1
 	if it.path[lastIdx] == right {
 		it.path[lastIdx] = left
-		it.stack[len(it.stack)-1] = it.resolveNode(it.parentOfLastNode().children[left])
+		it.stack[len(it.stack)-1] = it.resolveNode(common.CopyBytes(it.path), it.parentOfLastNode().children[left])
 		it.visitBeforeLeafNode()
 	} else {
 		it.path = it.path[:lastIdx]
@@ -1029,7 +1030,7 @@ func (it *merkleTreeIterator) visitBeforeLeafNode() {
 	for {
 		if parent, ok := it.stack[len(it.stack)-1].(*merkleTreeIteratorParentNode); ok {
 			for _, path := range []byte{right, left} {
-				if child := it.resolveNode(parent.children[path]); child != nil {
+				if child := it.resolveNode(it.childPath(path), parent.children[path]); child != nil {
 					it.stack = append(it.stack, child)
 					it.path = append(it.path, path)
 					break
@@ -1052,7 +1053,7 @@ func (it *merkleTreeIterator) prevLeaf() bool {
 				it.path = it.path[:len(it.path)-1]
 				it.stack = it.stack[:len(it.stack)-1]
 			case right: // right visited. go left
-				if leftNode := it.resolveNode(it.parentOfLastNode().children[left]); leftNode != nil {
+				if leftNode := it.resolveNode(it.siblingPath(left), it.parentOfLastNode().children[left]); leftNode != nil {
 					it.path[lastPathIndex] = left
 					it.stack[len(it.stack)-1] = leftNode
 					it.visitBeforeLeafNode()
@@ -1100,7 +1101,7 @@ func (it *merkleTreeIterator) Next(bool) bool {
 	case *merkleTreeIteratorParentNode:
 		// find the next node by preorder traversal. The children must have at least one not null.
 		for p, hash := range last.children {
-			if child := it.resolveNode(hash); child != nil {
+			if child := it.resolveNode(it.childPath(byte(p)), hash); child != nil {
 				it.path = append(it.path, byte(p))
 				it.stack = append(it.stack, child)
 				return true
@@ -1115,7 +1116,7 @@ func (it *merkleTreeIterator) Next(bool) bool {
 		for len(it.path) != 0 { // Infinite loop if there are still paths left to visit
 			switch lastPathIndex := len(it.path) - 1; it.path[lastPathIndex] {
 			case left: // left visited. go right
-				if rightNode := it.resolveNode(it.parentOfLastNode().children[right]); rightNode != nil {
+				if rightNode := it.resolveNode(it.siblingPath(right), it.parentOfLastNode().children[right]); rightNode != nil {
 					it.path[lastPathIndex] = right
 					it.stack[len(it.stack)-1] = rightNode
 					return true
@@ -1168,16 +1169,17 @@ func (it *merkleTreeIterator) LeafProof() [][]byte {
 
 func (it *merkleTreeIterator) AddResolver(resolver NodeResolver) { it.nodeBlob = resolver }
 
-func (it *merkleTreeIterator) resolveNode(hash common.Hash) (node merkleTreeIteratorNode) {
+// resolveNode resolves the node with the given path and hash.
+func (it *merkleTreeIterator) resolveNode(path []byte, hash common.Hash) (node merkleTreeIteratorNode) {
 	var blob []byte
 	if it.nodeBlob != nil {
-		blob = it.nodeBlob(common.Hash{}, it.path, hash)
+		blob = it.nodeBlob(common.Hash{}, path, hash)
 		if len(blob) > 0 {
 			node, it.err = it.nodeBlobToIteratorNode(hash, blob)
 			return node
 		}
 	}
-	blob, it.err = it.findNodeBlobByHash(hash)
+	blob, it.err = it.findNodeBlob(path, hash)
 	if len(blob) == 0 || it.err != nil {
 		return nil
 	}
@@ -1185,6 +1187,21 @@ func (it *merkleTreeIterator) resolveNode(hash common.Hash) (node merkleTreeIter
 	return node
 }
 
+// childPath returns the path of the given child of the last visited node.
+func (it *merkleTreeIterator) childPath(child byte) []byte {
+	path := make([]byte, len(it.path)+1)
+	copy(path, it.path)
+	path[len(it.path)] = child
+	return path
+}
+
+// siblingPath returns the path of the given child of the parent of the last visited node.
+func (it *merkleTreeIterator) siblingPath(child byte) []byte {
+	path := common.CopyBytes(it.path)
+	path[len(path)-1] = child
+	return path
+}
+
 func (it *merkleTreeIterator) parentOfLastNode() *merkleTreeIteratorParentNode {
 	return it.stack[len(it.stack)-2].(*merkleTreeIteratorParentNode)
 }
@@ -1,13 +1,10 @@
 package trie
 
 import (
-	zkt "github.com/kroma-network/zktrie/types"
-
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/trie/trienode"
-	"github.com/ethereum/go-ethereum/trie/zk"
 )
 
 /*
@@ -40,18 +37,15 @@ type MerkleTrie interface {
 
 func NewMerkleTrie(id *ID, db *Database) (MerkleTrie, error) {
 	if db.IsZk() {
-		tree, err := zk.NewMerkleTreeFromHash(zkt.NewHashFromBytes(id.Root[:]), db.Get)
-		if err != nil {
-			return nil, err
-		}
-		return NewZkMerkleTrie(tree, db), nil
+		return NewZkMerkleTrieWithID(id, db)
 	}
 	return New(id, db)
 }
 
 func NewEmptyMerkleTrie(db *Database) MerkleTrie {
 	if db.IsZk() {
-		return NewZkMerkleTrie(zk.NewEmptyMerkleTree(), db)
+		trie, _ := NewZkMerkleTrieWithID(TrieID(common.Hash{}), db)
+		return trie
 	}
 	return NewEmpty(db)
 }
@@ -89,7 +83,7 @@ type MerkleStateTrie interface {
 
 func NewMerkleStateTrie(id *ID, db *Database) (MerkleStateTrie, error) {
 	if db.IsKromaZK() {
-		return NewZkMerkleStateTrie(id.Root, db)
+		return NewZkMerkleStateTrieWithID(id, db)
 	}
 	if db.IsZk() {
 		return NewZkTrie(id.Root, db)
@@ -90,12 +90,19 @@ type trieLoader struct {
 	db *Database
 }
 
-// OpenTrie opens the main account trie.
+// OpenTrie opens the main account trie. The zk trie is opened as a ZkMerkleTrie,
+// which is keyed by the hashed keys.
 func (l *trieLoader) OpenTrie(root common.Hash) (triestate.Trie, error) {
+	if l.db.IsZk() {
+		return NewZkMerkleTrieWithID(TrieID(root), l.db)
+	}
 	return New(TrieID(root), l.db)
 }
 
 // OpenStorageTrie opens the storage trie of an account.
 func (l *trieLoader) OpenStorageTrie(stateRoot common.Hash, addrHash, root common.Hash) (triestate.Trie, error) {
+	if l.db.IsZk() {
+		return NewZkMerkleTrieWithID(StorageTrieID(stateRoot, addrHash, root), l.db)
+	}
 	return New(StorageTrieID(stateRoot, addrHash, root), l.db)
 }
@@ -1,17 +1,12 @@
 package hashdb
 
 import (
-	"bytes"
-	"crypto/sha256"
-	"errors"
+	"encoding/binary"
 	"fmt"
-	"sync"
 	"time"
 
-	"github.com/VictoriaMetrics/fastcache"
 	zktrie "github.com/kroma-network/zktrie/trie"
 	zkt "github.com/kroma-network/zktrie/types"
-	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -21,204 +16,424 @@ import (
 	"github.com/ethereum/go-ethereum/trie/triestate"
 )
 
+// zkAccountFields is the number of value fields of an account leaf in the zk state trie.
+const zkAccountFields = 4
+
+// zkResolver resolves the children of an encoded zk trie node: the children of a parent
+// node, and the storage trie root of an account leaf. The children are identified by the
+// database key of the node, which is the byte reversed node hash.
+type zkResolver struct{}
+
+// ForEach implements ChildResolver, decoding the provided node and traversing the
+// children inside.
+func (zkResolver) ForEach(node []byte, onChild func(common.Hash)) {
+	if len(node) == 0 {
+		return
+	}
+	resolve := func(hash []byte) {
+		if key := common.BytesToHash(zkt.ReverseByteOrder(hash)); key != (common.Hash{}) {
+			onChild(key)
+		}
+	}
+	switch zktrie.NodeType(node[0]) {
+	case zktrie.NodeTypeParent:
+		if len(node) != 1+2*zkt.HashByteLen {
+			return
+		}
+		resolve(node[1 : 1+zkt.HashByteLen])
+		resolve(node[1+zkt.HashByteLen:])
+	case zktrie.NodeTypeLeaf:
+		// {Type || NodeKey || Flags || Values...}, the storage root is the last account field.
+		offset := 1 + zkt.HashByteLen
+		if len(node) < offset+4 {
+			return
+		}
+		mark := binary.LittleEndian.Uint32(node[offset : offset+4])
+		if mark&0xff != zkAccountFields || len(node) < offset+4+zkAccountFields*32 {
+			return
+		}
+		resolve(node[offset+4+(zkAccountFields-1)*32 : offset+4+zkAccountFields*32])
+	}
+}
+
+// pendingNode is a dirty node which was not part of any referenced state when inserted.
+type pendingNode struct {
+	key  common.Hash
+	node *cachedNode
+}
+
+// ZktrieDatabase is the hash-based node database of the zk trie. Nodes are written into
+// it while the tries are hashed, and tracked with reference counting by the underlying
+// Database, so that the nodes of stale states are garbage collected instead of being
+// flushed to disk.
+//
+// Unlike the MPT, the intermediate nodes of every state transition end up in the dirty
+// cache, most of them never becoming part of a state. Therefore an inserted node is
+// pending and holds no reference to its children, until it is reached from a referenced
+// state root. Only then its children, derived from the node blob, are referenced. The
+// pending nodes which are not reached are garbage collected when a state referenced
+// after their insertion is dereferenced or committed, as no trie operation can still
+// be using them by then.
+//
+// Nodes are keyed by their database key, which is the byte reversed node hash. The
+// state roots passed to the public methods are node hashes.
 type ZktrieDatabase struct {
-	diskdb  ethdb.Database   // Persistent storage for matured trie nodes
-	cleans  *fastcache.Cache // GC friendly memory cache of clean node RLPs
-	prefix  []byte
-	dirties map[[sha256.Size]byte]*dirty
+	db    *Database
+	metas map[string][]byte // Non-node entries (e.g. the current root of the legacy zk trie), flushed on commit
 
-	lock        sync.RWMutex
-	dirtiesSize common.StorageSize // Storage size of the dirty node cache (exc. metadata)
+	pending     []pendingNode          // Nodes pending since insertion, in insertion order
+	pendingBase uint64                 // Number of nodes removed from the head of the pending queue
+	roots       map[common.Hash]uint64 // Referenced state roots, mapped to the pending queue length at their first reference
 }
 
 func NewZk(diskdb ethdb.Database, config *Config) *ZktrieDatabase {
-	if config == nil {
-		config = Defaults
-	}
-	var cleans *fastcache.Cache
-	if config.CleanCacheSize > 0 {
-		cleans = fastcache.New(config.CleanCacheSize)
-	}
 	return &ZktrieDatabase{
-		diskdb:  diskdb,
-		cleans:  cleans,
-		dirties: make(map[[sha256.Size]byte]*dirty),
+		db:    New(diskdb, config, zkResolver{}),
+		metas: make(map[string][]byte),
+		roots: make(map[common.Hash]uint64),
 	}
 }
 
+// nodeKey returns the database key of the node with the given hash.
+func nodeKey(hash common.Hash) common.Hash {
+	return common.BytesToHash(zkt.ReverseByteOrder(hash[:]))
+}
+
 func (db *ZktrieDatabase) Scheme() string { return rawdb.ZkHashScheme }
 
 func (db *ZktrieDatabase) Initialized(genesisRoot common.Hash) bool {
-	return rawdb.HasLegacyTrieNode(db.diskdb, common.BytesToHash(zkt.ReverseByteOrder(genesisRoot[:])))
+	return rawdb.HasLegacyTrieNode(db.db.diskdb, nodeKey(genesisRoot))
 }
 
+// Size returns the current storage size of the memory cache in front of the
+// persistent database layer.
 func (db *ZktrieDatabase) Size() (common.StorageSize, common.StorageSize) {
-	db.lock.RLock()
-	defer db.lock.RUnlock()
+	db.db.lock.RLock()
+	defer db.db.lock.RUnlock()
 
-	// db.dirtiesSize only contains the useful data in the cache, but when reporting
-	// the total memory consumption, the maintenance metadata is also needed to be
-	// counted.
-	var metadataSize = common.StorageSize(len(db.dirties))
-	return 0, db.dirtiesSize + metadataSize
+	metadataSize := common.StorageSize(len(db.db.dirties)*cachedNodeSize + len(db.pending)*common.HashLength)
+	return 0, db.db.dirtiesSize + db.db.childrenSize + metadataSize
 }
 
+// Update is a no-op, the nodes of zk tries are inserted with Put while being hashed.
 func (db *ZktrieDatabase) Update(_ common.Hash, _ common.Hash, _ uint64, _ *trienode.MergedNodeSet, _ *triestate.Set) error {
 	return nil
 }
 
-func (db *ZktrieDatabase) Commit(_ common.Hash, report bool) error {
-	beforeDirtyCount, beforeDirtySize := len(db.dirties), db.dirtiesSize
-
-	start := time.Now()
-	if err := db.commitAllDirties(); err != nil {
+// Commit writes the trie of the given state root, including the storage tries
+// referenced by it, out to disk. The pending nodes inserted before the state was
+// referenced, or all of them if it wasn't, are garbage collected afterwards.
+func (db *ZktrieDatabase) Commit(root common.Hash, report bool) error {
+	key := nodeKey(root)
+	if err := db.db.Commit(key, report); err != nil {
 		return err
 	}
-	memcacheCommitTimeTimer.Update(time.Since(start))
-	memcacheCommitBytesMeter.Mark(int64(beforeDirtySize - db.dirtiesSize))
-	memcacheCommitNodesMeter.Mark(int64(beforeDirtyCount - len(db.dirties)))
-
-	logger := log.Debug
-	if report {
-		logger = log.Info
-	}
-	logger(
-		"Persisted trie from memory database",
-		"nodes", beforeDirtyCount-len(db.dirties),
-		"size", beforeDirtySize-db.dirtiesSize,
-		"time", time.Since(start),
-		"livenodes", len(db.dirties),
-		"livesize", db.dirtiesSize,
-	)
+	db.db.lock.Lock()
+	defer db.db.lock.Unlock()
+
+	if len(db.metas) > 0 {
+		batch := db.db.diskdb.NewBatch()
+		for key, value := range db.metas {
+			batch.Put([]byte(key), value)
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		db.metas = make(map[string][]byte)
+	}
+	threshold, ok := db.roots[key]
+	if !ok {
+		threshold = db.pendingBase + uint64(len(db.pending))
+	}
+	delete(db.roots, key)
+
+	nodes, storage, start := len(db.db.dirties), db.db.dirtiesSize, time.Now()
+	db.releaseLocked(threshold)
+	db.recordGC(nodes, storage, start)
 	return nil
 }
 
-func (db *ZktrieDatabase) commitAllDirties() error {
-	batch := db.diskdb.NewBatch()
+func (db *ZktrieDatabase) Close() error { return db.db.Close() }
+
+// Cap iteratively flushes old but still referenced trie nodes until the total
+// memory usage goes below the given threshold. The pending nodes are never
+// flushed, as they aren't part of any referenced state.
+func (db *ZktrieDatabase) Cap(limit common.StorageSize) error {
+	db.db.lock.Lock()
+	defer db.db.lock.Unlock()
+
+	// Only uncache the flushed nodes once the database write finalizes, so that
+	// outside code doesn't see an inconsistent state.
+	var (
+		batch                 = db.db.diskdb.NewBatch()
+		uncacher              = &cleaner{db.db}
+		nodes, storage, start = len(db.db.dirties), db.db.dirtiesSize, time.Now()
+	)
+	size := db.db.dirtiesSize + common.StorageSize(len(db.db.dirties)*cachedNodeSize)
+	size += db.db.childrenSize
+
+	// Keep committing referenced nodes from the flush-list until we're below allowance.
+	// The children of a referenced node are referenced too, and precede it in the list.
+	for hash := db.db.oldest; size > limit && hash != (common.Hash{}); {
+		node := db.db.dirties[hash]
+		if node.parents > 0 {
+			rawdb.WriteLegacyTrieNode(batch, hash, node.node)
+
+			size -= common.StorageSize(common.HashLength + len(node.node) + cachedNodeSize)
+			if node.external != nil {
+				size -= common.StorageSize(len(node.external) * common.HashLength)
+			}
+		}
+		// Move on before uncaching, the next node is never part of the batch.
+		hash = node.flushNext
 
-	db.lock.Lock()
-	for hashKey, dirty := range db.dirties {
-		batch.Put(dirty.key, dirty.val)
-		db.removeDirtyByHashKey(hashKey)
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := db.flushLocked(batch, uncacher); err != nil {
+				return err
+			}
+		}
+	}
+	if err := db.flushLocked(batch, uncacher); err != nil {
+		return err
 	}
-	db.lock.Unlock()
+	db.db.flushnodes += uint64(nodes - len(db.db.dirties))
+	db.db.flushsize += storage - db.db.dirtiesSize
+	db.db.flushtime += time.Since(start)
 
+	memcacheFlushTimeTimer.Update(time.Since(start))
+	memcacheFlushBytesMeter.Mark(int64(storage - db.db.dirtiesSize))
+	memcacheFlushNodesMeter.Mark(int64(nodes - len(db.db.dirties)))
+
+	log.Debug("Persisted zk trie nodes from memory database", "nodes", nodes-len(db.db.dirties), "size", storage-db.db.dirtiesSize, "time", time.Since(start),
+		"flushnodes", db.db.flushnodes, "flushsize", db.db.flushsize, "flushtime", db.db.flushtime, "livenodes", len(db.db.dirties), "livesize", db.db.dirtiesSize)
+	return nil
+}
+
+// flushLocked writes the batch out to disk and removes the written nodes from the
+// dirty cache. This function assumes the lock is already held.
+func (db *ZktrieDatabase) flushLocked(batch ethdb.Batch, uncacher *cleaner) error {
 	if err := batch.Write(); err != nil {
+		log.Error("Failed to write flush list to disk", "err", err)
+		return err
+	}
+	if err := batch.Replay(uncacher); err != nil {
 		return err
 	}
-
 	batch.Reset()
 	return nil
 }
 
-func (db *ZktrieDatabase) Close() error { return nil }
-
-func (db *ZktrieDatabase) Cap(_ common.StorageSize) error         { return nil }
-func (db *ZktrieDatabase) Reference(_ common.Hash, _ common.Hash) {}
-func (db *ZktrieDatabase) Dereference(_ common.Hash)              {}
-func (db *ZktrieDatabase) Node(hash common.Hash) ([]byte, error) {
-	hashBytes := common.ReverseBytes(hash[:])
-	if db.cleans != nil {
-		if enc := db.cleans.Get(nil, hashBytes); enc != nil {
-			memcacheCleanHitMeter.Mark(1)
-			memcacheCleanReadMeter.Mark(int64(len(enc)))
-			return enc, nil
+// Reference adds a new reference from a parent node to a child node. The state
+// roots are referenced with an empty parent, the storage tries are linked by the
+// account leaves already.
+func (db *ZktrieDatabase) Reference(root common.Hash, parent common.Hash) {
+	db.db.lock.Lock()
+	defer db.db.lock.Unlock()
+
+	// If the node does not exist, it's a node pulled from disk, skip
+	key := nodeKey(root)
+	node, ok := db.db.dirties[key]
+	if !ok {
+		return
+	}
+	if parent == (common.Hash{}) {
+		if _, ok := db.roots[key]; !ok {
+			db.roots[key] = db.pendingBase + uint64(len(db.pending))
 		}
+		db.retainLocked(node)
+		return
+	}
+	// The reference is for external storage trie, don't duplicate if the reference
+	// is already existent. A pending parent references it once it is retained.
+	owner, ok := db.db.dirties[nodeKey(parent)]
+	if !ok {
+		return
+	}
+	if owner.external == nil {
+		owner.external = make(map[common.Hash]struct{})
 	}
+	if _, ok := owner.external[key]; ok {
+		return
+	}
+	owner.external[key] = struct{}{}
+	db.db.childrenSize += common.HashLength
+	if owner.parents > 0 {
+		db.retainLocked(node)
+	}
+}
 
-	if dirty, ok := db.mutexGetDirtyByKey(hashBytes); ok {
-		memcacheDirtyHitMeter.Mark(1)
-		memcacheDirtyReadMeter.Mark(int64(len(dirty.val)))
-		return dirty.val, nil
+// retainLocked adds a reference to the given node. A pending node becomes part of
+// a state with its first reference, and references all of its cached children in
+// turn. This function assumes the lock is already held.
+func (db *ZktrieDatabase) retainLocked(node *cachedNode) {
+	if node.parents++; node.parents > 1 {
+		return
 	}
+	node.forChildren(db.db.resolver, func(child common.Hash) {
+		if c := db.db.dirties[child]; c != nil {
+			db.retainLocked(c)
+		}
+	})
+}
+
+// Dereference removes an existing reference from a state root, and garbage collects
+// the pending nodes inserted before the state was referenced.
+func (db *ZktrieDatabase) Dereference(root common.Hash) {
+	db.db.lock.Lock()
+	defer db.db.lock.Unlock()
 
-	// Content unavailable in memory, attempt to retrieve from disk
-	if enc := rawdb.ReadLegacyTrieNode(db.diskdb, common.BytesToHash(hashBytes)); len(enc) != 0 {
-		if db.cleans != nil {
-			db.cleans.Set(hashBytes, enc)
-			memcacheCleanMissMeter.Mark(1)
-			memcacheCleanWriteMeter.Mark(int64(len(enc)))
+	key := nodeKey(root)
+	nodes, storage, start := len(db.db.dirties), db.db.dirtiesSize, time.Now()
+
+	db.dereferenceLocked(key)
+	if threshold, ok := db.roots[key]; ok {
+		if _, ok := db.db.dirties[key]; !ok {
+			delete(db.roots, key)
 		}
-		return enc, nil
+		db.releaseLocked(threshold)
 	}
-	return nil, errors.New("not found")
-}
+	db.recordGC(nodes, storage, start)
 
-// Put saves a key:value into the Storage
-func (db *ZktrieDatabase) Put(k, v []byte) error {
-	db.mutexPutDirty(k, v)
-	return nil
+	log.Debug("Dereferenced zk trie from memory database", "nodes", nodes-len(db.db.dirties), "size", storage-db.db.dirtiesSize, "time", time.Since(start),
+		"gcnodes", db.db.gcnodes, "gcsize", db.db.gcsize, "gctime", db.db.gctime, "livenodes", len(db.db.dirties), "livesize", db.db.dirtiesSize)
 }
 
-// Get retrieves a value from a key in the Storage
-func (db *ZktrieDatabase) Get(key []byte) ([]byte, error) {
-	if dirty, ok := db.mutexGetDirtyByKey(key); ok {
-		return dirty.val, nil
+// dereferenceLocked removes a reference from the given node, deleting it and
+// dereferencing its children once it's no longer referenced. Pending nodes hold
+// no references, so they are left to be released. This function assumes the
+// lock is already held.
+func (db *ZktrieDatabase) dereferenceLocked(hash common.Hash) {
+	// If the node does not exist, it's a previously committed node.
+	node, ok := db.db.dirties[hash]
+	if !ok || node.parents == 0 {
+		return
+	}
+	if node.parents--; node.parents > 0 {
+		return
 	}
-	key = db.computeKey(key)
-	if db.cleans != nil {
-		if enc := db.cleans.Get(nil, key); enc != nil {
-			memcacheCleanHitMeter.Mark(1)
-			memcacheCleanReadMeter.Mark(int64(len(enc)))
-			return enc, nil
+	db.removeLocked(hash, node)
+	node.forChildren(db.db.resolver, func(child common.Hash) {
+		db.dereferenceLocked(child)
+	})
+}
+
+// releaseLocked garbage collects the nodes still pending among the ones inserted
+// before the pending queue reached the given length. The nodes which have been
+// referenced, flushed or reinserted since are skipped. This function assumes the
+// lock is already held.
+func (db *ZktrieDatabase) releaseLocked(threshold uint64) {
+	for db.pendingBase < threshold && len(db.pending) > 0 {
+		entry := db.pending[0]
+		db.pending[0] = pendingNode{}
+		db.pending = db.pending[1:]
+		db.pendingBase++
+
+		if node := db.db.dirties[entry.key]; node == entry.node && node.parents == 0 {
+			db.removeLocked(entry.key, node)
 		}
 	}
-	v, err := db.diskdb.Get(key)
-	if errors.Is(err, leveldb.ErrNotFound) {
-		return nil, zktrie.ErrKeyNotFound
+}
+
+// removeLocked deletes a node from the dirty cache, leaving its children untouched.
+// This function assumes the lock is already held.
+func (db *ZktrieDatabase) removeLocked(hash common.Hash, node *cachedNode) {
+	switch hash {
+	case db.db.oldest:
+		db.db.oldest = node.flushNext
+		if node.flushNext != (common.Hash{}) {
+			db.db.dirties[node.flushNext].flushPrev = common.Hash{}
+		}
+	case db.db.newest:
+		db.db.newest = node.flushPrev
+		if node.flushPrev != (common.Hash{}) {
+			db.db.dirties[node.flushPrev].flushNext = common.Hash{}
+		}
+	default:
+		db.db.dirties[node.flushPrev].flushNext = node.flushNext
+		db.db.dirties[node.flushNext].flushPrev = node.flushPrev
 	}
-	if db.cleans != nil {
-		db.cleans.Set(key[:], v)
-		memcacheCleanMissMeter.Mark(1)
-		memcacheCleanWriteMeter.Mark(int64(len(v)))
+	delete(db.db.dirties, hash)
+	db.db.dirtiesSize -= common.StorageSize(common.HashLength + len(node.node))
+	if node.external != nil {
+		db.db.childrenSize -= common.StorageSize(len(node.external) * common.HashLength)
 	}
-	return v, err
 }
 
-func (db *ZktrieDatabase) mutexGetDirtyByKey(key []byte) (*dirty, bool) {
-	db.lock.RLock()
-	defer db.lock.RUnlock()
+// recordGC accounts the nodes removed from the dirty cache since the given snapshot
+// as garbage collected. This function assumes the lock is already held.
+func (db *ZktrieDatabase) recordGC(nodes int, storage common.StorageSize, start time.Time) {
+	db.db.gcnodes += uint64(nodes - len(db.db.dirties))
+	db.db.gcsize += storage - db.db.dirtiesSize
+	db.db.gctime += time.Since(start)
 
-	dirty, ok := db.dirties[sha256.Sum256(db.computeKey(key[:]))]
-	return dirty, ok
+	memcacheGCTimeTimer.Update(time.Since(start))
+	memcacheGCBytesMeter.Mark(int64(storage - db.db.dirtiesSize))
+	memcacheGCNodesMeter.Mark(int64(nodes - len(db.db.dirties)))
 }
 
-func (db *ZktrieDatabase) mutexPutDirty(key, val []byte) {
-	db.lock.Lock()
-	defer db.lock.Unlock()
-
-	dirty := newDirty(key, val)
-	db.dirties[sha256.Sum256(db.computeKey(key[:]))] = dirty
-	db.dirtiesSize += common.StorageSize(common.HashLength + dirty.size())
+// Node retrieves the encoded zk trie node with the given node hash.
+func (db *ZktrieDatabase) Node(hash common.Hash) ([]byte, error) {
+	return db.db.node(nodeKey(hash))
 }
 
-func (db *ZktrieDatabase) removeDirtyByHashKey(key [32]byte) {
-	dirty := db.dirties[key]
-	delete(db.dirties, key)
-	db.dirtiesSize -= common.StorageSize(common.HashLength + dirty.size())
-}
+// Put saves a key:value into the Storage
+func (db *ZktrieDatabase) Put(k, v []byte) error {
+	db.db.lock.Lock()
+	defer db.db.lock.Unlock()
 
-func (db *ZktrieDatabase) computeKey(vs ...[]byte) []byte {
-	var b bytes.Buffer
-	b.Write(db.prefix)
-	for _, v := range vs {
-		b.Write(v)
+	if len(k) != common.HashLength {
+		db.metas[string(k)] = common.CopyBytes(v)
+		return nil
 	}
-	return b.Bytes()
+	key := common.BytesToHash(k)
+
+	if _, ok := db.db.dirties[key]; ok {
+		return nil
+	}
+	db.insertLocked(key, common.CopyBytes(v))
+	return nil
 }
 
-// Removed the bottom two functions because they are not used anywhere
-// Iterate implements the method Iterate of the interface Storage
-// List implements the method List of the interface Storage
+// insertLocked inserts a pending node into the dirty cache. Unlike the MPT nodes,
+// it doesn't reference its children until it is retained. This function assumes
+// the lock is already held.
+func (db *ZktrieDatabase) insertLocked(hash common.Hash, blob []byte) {
+	memcacheDirtyWriteMeter.Mark(int64(len(blob)))
 
-type dirty struct {
-	key []byte
-	val []byte
+	node := &cachedNode{
+		node:      blob,
+		flushPrev: db.db.newest,
+	}
+	db.db.dirties[hash] = node
+	if db.db.oldest == (common.Hash{}) {
+		db.db.oldest, db.db.newest = hash, hash
+	} else {
+		db.db.dirties[db.db.newest].flushNext, db.db.newest = hash, hash
+	}
+	db.db.dirtiesSize += common.StorageSize(common.HashLength + len(blob))
+	db.pending = append(db.pending, pendingNode{key: hash, node: node})
 }
 
-func newDirty(k []byte, v []byte) *dirty { return &dirty{key: k, val: v} }
-
-func (d dirty) size() int { return len(d.key) + len(d.val) }
+// Get retrieves a value from a key in the Storage
+func (db *ZktrieDatabase) Get(key []byte) ([]byte, error) {
+	if len(key) != common.HashLength {
+		db.db.lock.RLock()
+		value, ok := db.metas[string(key)]
+		db.db.lock.RUnlock()
+		if ok {
+			return value, nil
+		}
+		if value, err := db.db.diskdb.Get(key); err == nil {
+			return value, nil
+		}
+		return nil, zktrie.ErrKeyNotFound
+	}
+	blob, err := db.db.node(common.BytesToHash(key))
+	if err != nil {
+		return nil, zktrie.ErrKeyNotFound
+	}
+	return blob, nil
+}
 
 func (db *ZktrieDatabase) Reader(root common.Hash) (*zkReader, error) {
 	if _, err := db.Node(root); err != nil {
@@ -26,11 +26,13 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/triestate"
+	"github.com/ethereum/go-ethereum/trie/zk"
 )
 
 const (
@@ -90,6 +92,7 @@ type Config struct {
 	CleanCacheSize int    // Maximum memory allowance (in bytes) for caching clean nodes
 	DirtyCacheSize int    // Maximum memory allowance (in bytes) for caching dirty nodes
 	ReadOnly       bool   // Flag whether the database is opened in read only mode.
+	Zktrie         bool   // Flag whether the database is holding zk tries
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -155,7 +158,7 @@ func New(diskdb ethdb.Database, config *Config) *Database {
 	}
 	// Construct the layer tree by resolving the in-disk singleton state
 	// and in-memory layer journal.
-	db.tree = newLayerTree(db.loadLayers())
+	db.tree = newLayerTree(db.loadLayers(), config.Zktrie)
 
 	// Open the freezer for state history if the passed database contains an
 	// ancient store. Otherwise, all the relevant functionalities are disabled.
@@ -296,8 +299,8 @@ func (db *Database) Enable(root common.Hash) error {
 		return errDatabaseReadOnly
 	}
 	// Ensure the provided state root matches the stored one.
-	root = types.TrieRootHash(root)
-	_, stored := rawdb.ReadAccountTrieNode(db.diskdb, nil)
+	root = db.trieRootHash(root)
+	_, stored := db.readNode(common.Hash{}, nil)
 	if stored != root {
 		return fmt.Errorf("state root mismatch: stored %x, synced %x", stored, root)
 	}
@@ -344,7 +347,7 @@ func (db *Database) Recover(root common.Hash, loader triestate.TrieLoader) error
 		return errors.New("state rollback is non-supported")
 	}
 	// Short circuit if the target state is not recoverable.
-	root = types.TrieRootHash(root)
+	root = db.trieRootHash(root)
 	if !db.Recoverable(root) {
 		return errStateUnrecoverable
 	}
@@ -379,7 +382,7 @@ func (db *Database) Recover(root common.Hash, loader triestate.TrieLoader) error
 // Recoverable returns the indicator if the specified state is recoverable.
 func (db *Database) Recoverable(root common.Hash) bool {
 	// Ensure the requested state is a known state.
-	root = types.TrieRootHash(root)
+	root = db.trieRootHash(root)
 	id := rawdb.ReadStateID(db.diskdb, root)
 	if id == nil {
 		return false
@@ -444,7 +447,7 @@ func (db *Database) Size() (diffs common.StorageSize, nodes common.StorageSize)
 func (db *Database) Initialized(genesisRoot common.Hash) bool {
 	var inited bool
 	db.tree.forEach(func(layer layer) {
-		if layer.rootHash() != types.EmptyRootHash {
+		if layer.rootHash() != db.emptyRoot() {
 			inited = true
 		}
 	})
@@ -472,6 +475,57 @@ func (db *Database) Scheme() string {
 	return rawdb.PathScheme
 }
 
+// emptyRoot returns the root hash of the empty trie.
+func (db *Database) emptyRoot() common.Hash {
+	return types.GetEmptyRootHash(db.config.Zktrie)
+}
+
+// trieRootHash returns the given state root. The zero hash is converted to the
+// empty root hash of the MPT, while it is the empty root of the zk trie itself.
+func (db *Database) trieRootHash(root common.Hash) common.Hash {
+	return trieRootHash(root, db.config.Zktrie)
+}
+
+// trieRootHash is the standalone version of Database.trieRootHash.
+func trieRootHash(root common.Hash, zktrie bool) common.Hash {
+	if zktrie {
+		return root
+	}
+	return types.TrieRootHash(root)
+}
+
+// nodeHash returns the hash of the given trie node blob, which is the keccak
+// hash for the MPT and the poseidon hash for the zk trie.
+func (db *Database) nodeHash(blob []byte) common.Hash {
+	if !db.config.Zktrie {
+		return crypto.Keccak256Hash(blob)
+	}
+	hash, err := zk.ComputeProofHash(zk.NewHasher(), blob)
+	if err != nil {
+		log.Error("Failed to hash zk trie node", "err", err)
+		return common.Hash{}
+	}
+	return common.BytesToHash(hash.Bytes())
+}
+
+// readNode retrieves the trie node with the given owner and path from the disk,
+// along with its hash. The zero hash is returned if the node is not found.
+func (db *Database) readNode(owner common.Hash, path []byte) ([]byte, common.Hash) {
+	var (
+		blob []byte
+		hash common.Hash
+	)
+	if owner == (common.Hash{}) {
+		blob, hash = rawdb.ReadAccountTrieNode(db.diskdb, path)
+	} else {
+		blob, hash = rawdb.ReadStorageTrieNode(db.diskdb, owner, path)
+	}
+	if !db.config.Zktrie || len(blob) == 0 {
+		return blob, hash
+	}
+	return blob, db.nodeHash(blob)
+}
+
 // modifyAllowed returns the indicator if mutation is allowed. This function
 // assumes the db.lock is already held.
 func (db *Database) modifyAllowed() error {
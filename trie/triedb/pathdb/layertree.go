@@ -22,7 +22,6 @@ import (
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/trie/trienode"
 	"github.com/ethereum/go-ethereum/trie/triestate"
 )
@@ -35,11 +34,12 @@ import (
 type layerTree struct {
 	lock   sync.RWMutex
 	layers map[common.Hash]layer
+	zktrie bool // Flag whether the layers are holding zk tries
 }
 
 // newLayerTree constructs the layerTree with the given head layer.
-func newLayerTree(head layer) *layerTree {
-	tree := new(layerTree)
+func newLayerTree(head layer, zktrie bool) *layerTree {
+	tree := &layerTree{zktrie: zktrie}
 	tree.reset(head)
 	return tree
 }
@@ -63,7 +63,7 @@ func (tree *layerTree) get(root common.Hash) layer {
 	tree.lock.RLock()
 	defer tree.lock.RUnlock()
 
-	return tree.layers[types.TrieRootHash(root)]
+	return tree.layers[trieRootHash(root, tree.zktrie)]
 }
 
 // forEach iterates the stored layers inside and applies the
@@ -93,7 +93,7 @@ func (tree *layerTree) add(root common.Hash, parentRoot common.Hash, block uint6
 	//
 	// Although we could silently ignore this internally, it should be the caller's
 	// responsibility to avoid even attempting to insert such a layer.
-	root, parentRoot = types.TrieRootHash(root), types.TrieRootHash(parentRoot)
+	root, parentRoot = trieRootHash(root, tree.zktrie), trieRootHash(parentRoot, tree.zktrie)
 	if root == parentRoot {
 		return errors.New("layer cycle")
 	}
@@ -113,7 +113,7 @@ func (tree *layerTree) add(root common.Hash, parentRoot common.Hash, block uint6
 // are crossed. All diffs beyond the permitted number are flattened downwards.
 func (tree *layerTree) cap(root common.Hash, layers int) error {
 	// Retrieve the head layer to cap from
-	root = types.TrieRootHash(root)
+	root = trieRootHash(root, tree.zktrie)
 	l := tree.get(root)
 	if l == nil {
 		return fmt.Errorf("triedb layer [%#x] missing", root)
@@ -100,12 +100,24 @@ type context struct {
 	storages    map[common.Address]map[common.Hash][]byte
 	accountTrie Trie
 	nodes       *trienode.MergedNodeSet
+	zktrie      bool // Flag whether the states are stored in zk tries
 }
 
 // Apply traverses the provided state diffs, apply them in the associated
 // post-state and return the generated dirty trie nodes. The state can be
 // loaded via the provided trie loader.
 func Apply(prevRoot common.Hash, postRoot common.Hash, accounts map[common.Address][]byte, storages map[common.Address]map[common.Hash][]byte, loader TrieLoader) (map[common.Hash]map[string]*trienode.Node, error) {
+	return apply(prevRoot, postRoot, accounts, storages, loader, false)
+}
+
+// ApplyZk is the zk trie version of Apply. The tries opened by the loader are keyed
+// by the poseidon hash of the account addresses and storage slots, while the storage
+// tries are still owned by the keccak hash of the account address.
+func ApplyZk(prevRoot common.Hash, postRoot common.Hash, accounts map[common.Address][]byte, storages map[common.Address]map[common.Hash][]byte, loader TrieLoader) (map[common.Hash]map[string]*trienode.Node, error) {
+	return apply(prevRoot, postRoot, accounts, storages, loader, true)
+}
+
+func apply(prevRoot common.Hash, postRoot common.Hash, accounts map[common.Address][]byte, storages map[common.Address]map[common.Hash][]byte, loader TrieLoader, zktrie bool) (map[common.Hash]map[string]*trienode.Node, error) {
 	tr, err := loader.OpenTrie(postRoot)
 	if err != nil {
 		return nil, err
@@ -117,6 +129,7 @@ func Apply(prevRoot common.Hash, postRoot common.Hash, accounts map[common.Addre
 		storages:    storages,
 		accountTrie: tr,
 		nodes:       trienode.NewMergedNodeSet(),
+		zktrie:      zktrie,
 	}
 	for addr, account := range accounts {
 		var err error
@@ -152,19 +165,19 @@ func updateAccount(ctx *context, loader TrieLoader, addr common.Address) error {
 	defer h.release()
 
 	addrHash := h.hash(addr.Bytes())
-	prev, err := types.FullAccount(ctx.accounts[addr])
+	prev, err := types.NewFullAccount(ctx.accounts[addr], ctx.zktrie)
 	if err != nil {
 		return err
 	}
 	// The account may or may not existent in post-state, try to
 	// load it and decode if it's found.
-	blob, err := ctx.accountTrie.Get(addrHash.Bytes())
+	blob, err := ctx.accountTrie.Get(ctx.accountKey(addr, addrHash))
 	if err != nil {
 		return err
 	}
-	post := types.NewEmptyStateAccount(false)
+	post := types.NewEmptyStateAccount(ctx.zktrie)
 	if len(blob) != 0 {
-		if err := rlp.DecodeBytes(blob, &post); err != nil {
+		if post, err = ctx.decodeAccount(blob); err != nil {
 			return err
 		}
 	}
@@ -178,6 +191,12 @@ func updateAccount(ctx *context, loader TrieLoader, addr common.Address) error {
 		if len(val) == 0 {
 			err = st.Delete(key.Bytes())
 		} else {
+			if ctx.zktrie {
+				// The zk trie holds the raw slot value instead of the rlp-encoded one.
+				if _, val, _, err = rlp.Split(val); err != nil {
+					return err
+				}
+			}
 			err = st.Update(key.Bytes(), val)
 		}
 		if err != nil {
@@ -199,11 +218,11 @@ func updateAccount(ctx *context, loader TrieLoader, addr common.Address) error {
 		}
 	}
 	// Write the prev-state account into the main trie
-	full, err := rlp.EncodeToBytes(prev)
+	full, err := prev.Encode(ctx.zktrie)
 	if err != nil {
 		return err
 	}
-	return ctx.accountTrie.Update(addrHash.Bytes(), full)
+	return ctx.accountTrie.Update(ctx.accountKey(addr, addrHash), full)
 }
 
 // deleteAccount the account was not present in prev-state, and is expected
@@ -215,15 +234,15 @@ func deleteAccount(ctx *context, loader TrieLoader, addr common.Address) error {
 	defer h.release()
 
 	addrHash := h.hash(addr.Bytes())
-	blob, err := ctx.accountTrie.Get(addrHash.Bytes())
+	blob, err := ctx.accountTrie.Get(ctx.accountKey(addr, addrHash))
 	if err != nil {
 		return err
 	}
 	if len(blob) == 0 {
 		return fmt.Errorf("account is non-existent %#x", addrHash)
 	}
-	var post types.StateAccount
-	if err := rlp.DecodeBytes(blob, &post); err != nil {
+	post, err := ctx.decodeAccount(blob)
+	if err != nil {
 		return err
 	}
 	st, err := loader.OpenStorageTrie(ctx.postRoot, addrHash, post.Root)
@@ -242,7 +261,7 @@ func deleteAccount(ctx *context, loader TrieLoader, addr common.Address) error {
 	if err != nil {
 		return err
 	}
-	if root != types.EmptyRootHash {
+	if root != types.GetEmptyRootHash(ctx.zktrie) {
 		return errors.New("failed to clear storage trie")
 	}
 	// The returned set can be nil if storage trie is not changed
@@ -253,7 +272,28 @@ func deleteAccount(ctx *context, loader TrieLoader, addr common.Address) error {
 		}
 	}
 	// Delete the post-state account from the main trie.
-	return ctx.accountTrie.Delete(addrHash.Bytes())
+	return ctx.accountTrie.Delete(ctx.accountKey(addr, addrHash))
+}
+
+// accountKey returns the key of the given account in the account trie, which is
+// the keccak hash of the address, or the poseidon hash of it for the zk trie.
+func (ctx *context) accountKey(addr common.Address, addrHash common.Hash) []byte {
+	if ctx.zktrie {
+		return crypto.MustHashing(nil, addr.Bytes(), true).Bytes()
+	}
+	return addrHash.Bytes()
+}
+
+// decodeAccount decodes the account stored in the account trie.
+func (ctx *context) decodeAccount(blob []byte) (*types.StateAccount, error) {
+	if ctx.zktrie {
+		return types.UnmarshalStateAccount(blob)
+	}
+	account := new(types.StateAccount)
+	if err := rlp.DecodeBytes(blob, account); err != nil {
+		return nil, err
+	}
+	return account, nil
 }
 
 // hasher is used to compute the sha256 hash of the provided data.
@@ -258,6 +258,11 @@ func (t *VerkleTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
 	panic("not implemented")
 }
 
+// Witness returns a set containing all trie nodes that have been accessed.
+func (t *VerkleTrie) Witness() map[string]struct{} {
+	panic("not implemented")
+}
+
 // Copy returns a deep-copied verkle tree.
 func (t *VerkleTrie) Copy() *VerkleTrie {
 	return &VerkleTrie{
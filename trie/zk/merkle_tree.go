@@ -22,6 +22,9 @@ type MerkleTree struct {
 	// findBlobByHash is a function that reads tree blob data by [TreeNode.Hash].
 	// It exists to decode the HashNode into the original TreeNode, so it is not needed if the HashNode cannot be created (e.g, rootNode is an EmptyNode).
 	findBlobByHash func(hash []byte) ([]byte, error)
+	// findBlobByPath is a function that reads tree blob data by the path of the node and [TreeNode.Hash].
+	// It takes precedence over findBlobByHash, and is used when the nodes are stored by path instead of hash.
+	findBlobByPath func(path TreePath, hash []byte) ([]byte, error)
 
 	hasher Hasher
 }
@@ -37,6 +40,18 @@ func NewMerkleTreeFromHash(rootHash *zkt.Hash, findBlobByHash func(hash []byte)
 	return NewMerkleTree(rootNode).WithNodeBlobFinder(findBlobByHash), nil
 }
 
+// NewMerkleTreeFromPath creates a MerkleTree whose nodes are resolved by their path from the root.
+func NewMerkleTreeFromPath(rootHash *zkt.Hash, findBlobByPath func(path TreePath, hash []byte) ([]byte, error)) (*MerkleTree, error) {
+	if bytes.Equal(rootHash.Bytes(), zkt.HashZero.Bytes()) {
+		return NewEmptyMerkleTree().WithNodeBlobPathFinder(findBlobByPath), nil
+	}
+	rootNode, err := NewTreeNodeFromHash(rootHash, func(hash []byte) ([]byte, error) { return findBlobByPath(nil, hash) })
+	if err != nil {
+		return nil, err
+	}
+	return NewMerkleTree(rootNode).WithNodeBlobPathFinder(findBlobByPath), nil
+}
+
 func NewEmptyMerkleTree() *MerkleTree { return NewMerkleTree(EmptyNodeValue) }
 func NewMerkleTree(rootNode TreeNode) *MerkleTree {
 	return (&MerkleTree{rootNode: rootNode, maxLevels: trie.NodeKeyValidBytes * 8}).WithHasher(NewHasher())
@@ -55,6 +70,11 @@ func (t *MerkleTree) WithNodeBlobFinder(findBlobByHash func(hash []byte) ([]byte
 	return t
 }
 
+func (t *MerkleTree) WithNodeBlobPathFinder(findBlobByPath func(path TreePath, hash []byte) ([]byte, error)) *MerkleTree {
+	t.findBlobByPath = findBlobByPath
+	return t
+}
+
 func (t *MerkleTree) WithHasher(hasher Hasher) *MerkleTree {
 	t.hasher = hasher
 	return t
@@ -101,10 +121,10 @@ func (t *MerkleTree) Get(key []byte) ([]byte, error) {
 // GetLeafNode is more underlying method than Get, which obtain a leaf node or nil if not exist.
 func (t *MerkleTree) GetLeafNode(key []byte) (*LeafNode, error) {
 	node, path := t.rootNode, t.newTreePath(key)
-	for lvl, p := range path {
+	for lvl := range path {
 		switch n := node.(type) {
 		case *ParentNode:
-			node = t.getChild(n, p)
+			node = t.getChild(n, path[:lvl+1])
 		case *LeafNode:
 			if !bytes.Equal(key[:], n.Key[:]) {
 				return nil, trie.ErrKeyNotFound
@@ -124,9 +144,9 @@ func (t *MerkleTree) GetLeafNode(key []byte) (*LeafNode, error) {
 // GetNodeByPath returns the tree node at the end of the path
 func (t *MerkleTree) GetNodeByPath(path TreePath) TreeNode {
 	node := t.rootNode
-	for _, p := range path {
+	for lvl := range path {
 		if parent, ok := node.(*ParentNode); ok {
-			node = t.getChild(parent, p)
+			node = t.getChild(parent, path[:lvl+1])
 		} else {
 			break
 		}
@@ -179,7 +199,7 @@ func (t *MerkleTree) addLeaf(
 	}
 	switch n := currNode.(type) {
 	case *ParentNode:
-		newNode, err := t.addLeaf(newLeaf, t.getChild(n, path.Get(lvl)), lvl+1, path, forceUpdate)
+		newNode, err := t.addLeaf(newLeaf, t.getChild(n, path[:lvl+1]), lvl+1, path, forceUpdate)
 		if err != nil {
 			log.Error("fail to addLeaf", "err", err, "level", lvl)
 			return nil, err
@@ -249,11 +269,11 @@ func (t *MerkleTree) MustDelete(key []byte) {
 // mt.ImportDumpedLeafs), but this will lose all the Root history of the MerkleTree
 func (t *MerkleTree) Delete(key []byte) error {
 	node, path, pathNodes := t.rootNode, t.newTreePath(key), *new([]*ParentNode)
-	for lvl, p := range path {
+	for lvl := range path {
 		switch n := node.(type) {
 		case *ParentNode:
 			pathNodes = append(pathNodes, n)
-			node = t.getChild(n, p)
+			node = t.getChild(n, path[:lvl+1])
 		case *LeafNode:
 			if bytes.Equal(key, n.Key) {
 				t.rmAndUpload(path, pathNodes)
@@ -279,7 +299,7 @@ func (t *MerkleTree) rmAndUpload(path TreePath, pathNodes []*ParentNode) {
 		t.rootNode = EmptyNodeValue
 	//case 1: incorrect tree update caused a hard fork. see https://github.com/kroma-network/kroma/pull/272
 	default:
-		lastSibling := t.getChild(pathNodes[len(pathNodes)-1], path.GetOther(len(pathNodes)-1))
+		lastSibling := t.getChild(pathNodes[len(pathNodes)-1], path.Sibling(len(pathNodes)-1))
 		defer func() {
 			if t.rootNode != lastSibling {
 				for _, node := range pathNodes {
@@ -300,7 +320,7 @@ func (t *MerkleTree) rmAndUpload(path TreePath, pathNodes []*ParentNode) {
 		//                  |- LeafNode (sibling)
 		for i := len(pathNodes) - 2; i >= 0; i-- { // start parent of last ParentNode
 			clearNodeHash(pathNodes[i])
-			sibling := t.getChild(pathNodes[i], path.GetOther(i))
+			sibling := t.getChild(pathNodes[i], path.Sibling(i))
 			if _, ok := sibling.(*EmptyNode); ok {
 				// To shorten the path as much as possible, if the sibling node is an empty node, it will be moved up.
 				// (with only one LeafNode among Parent's children).
@@ -336,7 +356,7 @@ func (t *MerkleTree) Prove(key []byte, writeNode func(TreeNode) error) error {
 	}
 	node := t.rootNode
 	path := t.newTreePath(key)
-	for lvl, p := range path {
+	for lvl := range path {
 		// TODO: notice here we may have broken some implicit on the proofDb:
 		// the key is not keccak(value) and it even can not be derived from the value by any means without an actual decoding
 		if err := writeNode(node); err != nil {
@@ -344,7 +364,7 @@ func (t *MerkleTree) Prove(key []byte, writeNode func(TreeNode) error) error {
 		}
 		switch n := node.(type) {
 		case *ParentNode:
-			node = t.getChild(n, p)
+			node = t.getChild(n, path[:lvl+1])
 		case *LeafNode:
 			return nil
 		case *EmptyNode:
@@ -363,20 +383,30 @@ func (t *MerkleTree) Copy() *MerkleTree {
 		rootNode:       copyNode(t.rootNode),
 		maxLevels:      t.maxLevels,
 		findBlobByHash: t.findBlobByHash,
+		findBlobByPath: t.findBlobByPath,
 		hasher:         t.hasher,
 	}
 }
 
 // getChild If the child node is a hash node, decode it and update the parent node.
-func (t *MerkleTree) getChild(node *ParentNode, path byte) TreeNode {
-	if hashNode, ok := node.Child(path).(*HashNode); ok && t.findBlobByHash != nil {
-		if child, err := NewTreeNodeFromHash(hashNode.Hash(), t.findBlobByHash); err != nil {
-			log.Error("fail to resolve hash node", "hash", hashNode.Hash(), "err", err)
+// The path is the full path of the child node from the root.
+func (t *MerkleTree) getChild(node *ParentNode, path TreePath) TreeNode {
+	p := path[len(path)-1]
+	if hashNode, ok := node.Child(p).(*HashNode); ok {
+		findBlobByHash := t.findBlobByHash
+		if t.findBlobByPath != nil {
+			findBlobByHash = func(hash []byte) ([]byte, error) { return t.findBlobByPath(path, hash) }
+		}
+		if findBlobByHash == nil {
+			return hashNode
+		}
+		if child, err := NewTreeNodeFromHash(hashNode.Hash(), findBlobByHash); err != nil {
+			log.Error("fail to resolve hash node", "hash", hashNode.Hash(), "path", path, "err", err)
 		} else {
-			node.SetChild(path, child)
+			node.SetChild(p, child)
 		}
 	}
-	return node.Child(path)
+	return node.Child(p)
 }
 
 func (t *MerkleTree) newTreePath(key []byte) TreePath {
@@ -62,6 +62,13 @@ func NewTreePath(path []byte) TreePath { return path }
 func (p TreePath) Get(depth int) byte      { return p[depth] }
 func (p TreePath) GetOther(depth int) byte { return p[depth] ^ right }
 
+// Sibling returns the path of the sibling of the node at the given depth of the path.
+func (p TreePath) Sibling(depth int) TreePath {
+	sibling := common.CopyBytes(p[:depth+1])
+	sibling[depth] ^= right
+	return sibling
+}
+
 func (p TreePath) ToHash() *common.Hash {
 	hash := common.BytesToHash(p.ToZkHash().Bytes())
 	return &hash
@@ -0,0 +1,268 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"math/big"
+	"testing"
+
+	zktrie "github.com/kroma-network/zktrie/trie"
+	zkt "github.com/kroma-network/zktrie/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// zkNodeKey returns the database key of the zk trie node with the given hash.
+func zkNodeKey(hash common.Hash) []byte {
+	return zkt.ReverseByteOrder(hash.Bytes())
+}
+
+// makeZkState creates a zk state with the given number of accounts, the first one
+// holding a storage trie, and returns its root.
+func makeZkState(t *testing.T, db *Database, accounts int, nonce uint64) common.Hash {
+	addr := common.BigToAddress(big.NewInt(1))
+	storage := NewEmptyZkMerkleStateTrie(db)
+	for i := 0; i < 10; i++ {
+		slot := common.BigToHash(big.NewInt(int64(i)))
+		if err := storage.UpdateStorage(addr, slot.Bytes(), common.BigToHash(big.NewInt(int64(i+1))).Bytes()); err != nil {
+			t.Fatalf("failed to update storage: %v", err)
+		}
+	}
+	state := NewEmptyZkMerkleStateTrie(db)
+	for i := 0; i < accounts; i++ {
+		account := &types.StateAccount{Nonce: nonce, Balance: big.NewInt(int64(i)), CodeHash: types.EmptyCodeHash.Bytes()}
+		if i == 0 {
+			account.Root = storage.Hash()
+		}
+		if err := state.UpdateAccount(common.BigToAddress(big.NewInt(int64(i+1))), account); err != nil {
+			t.Fatalf("failed to update account: %v", err)
+		}
+	}
+	return state.Hash()
+}
+
+// checkZkState checks that the state with the given root is complete.
+func checkZkState(t *testing.T, db *Database, root common.Hash, accounts int) {
+	state, err := NewZkMerkleStateTrie(root, db)
+	if err != nil {
+		t.Fatalf("failed to open state %x: %v", root, err)
+	}
+	for i := 0; i < accounts; i++ {
+		account, err := state.GetAccount(common.BigToAddress(big.NewInt(int64(i + 1))))
+		if err != nil || account == nil {
+			t.Fatalf("account %d missing: %v", i, err)
+		}
+		if i != 0 {
+			continue
+		}
+		storage, err := NewZkMerkleStateTrie(account.Root, db)
+		if err != nil {
+			t.Fatalf("failed to open storage %x: %v", account.Root, err)
+		}
+		it := NewIterator(storage.MustNodeIterator(nil))
+		slots := 0
+		for it.Next() {
+			slots++
+		}
+		if it.Err != nil || slots != 10 {
+			t.Fatalf("storage incomplete: %d slots, err %v", slots, it.Err)
+		}
+	}
+}
+
+func hasZkNode(db *Database, root common.Hash) bool {
+	_, err := db.Get(zkNodeKey(root))
+	return err == nil
+}
+
+func hasZkNodeOnDisk(diskdb ethdb.Database, root common.Hash) bool {
+	ok, _ := diskdb.Has(zkNodeKey(root))
+	return ok
+}
+
+func TestZkDatabaseDereference(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	db := NewZkDatabase(diskdb)
+
+	root1 := makeZkState(t, db, 20, 1)
+	db.Reference(root1, common.Hash{})
+	root2 := makeZkState(t, db, 20, 2)
+	db.Reference(root2, common.Hash{})
+	_, before, _ := db.Size()
+
+	// Dereferencing the first state must drop its nodes, but keep the shared ones.
+	db.Dereference(root1)
+	if hasZkNode(db, root1) {
+		t.Fatal("dereferenced state root still available")
+	}
+	checkZkState(t, db, root2, 20)
+	if _, after, _ := db.Size(); after >= before {
+		t.Fatalf("dirty cache not shrunk: before %v, after %v", before, after)
+	}
+
+	// Committing the second state must persist it completely, but nothing else.
+	if err := db.Commit(root2, false); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if hasZkNodeOnDisk(diskdb, root1) {
+		t.Fatal("dereferenced state root persisted")
+	}
+	if !hasZkNodeOnDisk(diskdb, root2) {
+		t.Fatal("committed state root not persisted")
+	}
+	checkZkState(t, NewZkDatabase(diskdb), root2, 20)
+}
+
+func TestZkDatabaseCap(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	db := NewZkDatabase(diskdb)
+
+	root := makeZkState(t, db, 50, 1)
+	db.Reference(root, common.Hash{})
+	if hasZkNodeOnDisk(diskdb, root) {
+		t.Fatal("state root persisted before cap")
+	}
+	if err := db.Cap(0); err != nil {
+		t.Fatalf("failed to cap: %v", err)
+	}
+	if !hasZkNodeOnDisk(diskdb, root) {
+		t.Fatal("state root not persisted by cap")
+	}
+	checkZkState(t, NewZkDatabase(diskdb), root, 50)
+}
+
+// makeZkStateWithIntermediate creates a zk state with the given number of accounts,
+// hashing it halfway through, and returns the intermediate and the final roots.
+func makeZkStateWithIntermediate(t *testing.T, db *Database, accounts int) (common.Hash, common.Hash) {
+	state := NewEmptyZkMerkleStateTrie(db)
+	var intermediate common.Hash
+	for i := 0; i < accounts; i++ {
+		account := &types.StateAccount{Nonce: uint64(i), Balance: big.NewInt(1), CodeHash: types.EmptyCodeHash.Bytes()}
+		if err := state.UpdateAccount(common.BigToAddress(big.NewInt(int64(i+1))), account); err != nil {
+			t.Fatalf("failed to update account: %v", err)
+		}
+		if i == accounts/2 {
+			intermediate = state.Hash()
+		}
+	}
+	return intermediate, state.Hash()
+}
+
+// countZkNodesOnDisk returns the number of trie nodes persisted in the database.
+func countZkNodesOnDisk(diskdb ethdb.Database) int {
+	it := diskdb.NewIterator(nil, nil)
+	defer it.Release()
+
+	nodes := 0
+	for it.Next() {
+		if len(it.Key()) == common.HashLength {
+			nodes++
+		}
+	}
+	return nodes
+}
+
+func TestZkDatabaseDereferenceAll(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	db := NewZkDatabase(diskdb)
+
+	intermediate, root := makeZkStateWithIntermediate(t, db, 10)
+	if !hasZkNode(db, intermediate) {
+		t.Fatal("intermediate root not cached")
+	}
+	db.Reference(root, common.Hash{})
+	if _, nodes, _ := db.Size(); nodes == 0 {
+		t.Fatal("state not cached")
+	}
+	// Dereferencing the only state must release all the nodes, including the
+	// intermediate ones, without persisting anything.
+	db.Dereference(root)
+	if _, nodes, _ := db.Size(); nodes != 0 {
+		t.Fatalf("dirty cache not empty: %v", nodes)
+	}
+	if hasZkNode(db, root) || hasZkNode(db, intermediate) {
+		t.Fatal("dereferenced nodes still available")
+	}
+	if nodes := countZkNodesOnDisk(diskdb); nodes != 0 {
+		t.Fatalf("%d nodes persisted", nodes)
+	}
+}
+
+func TestZkDatabasePendingCollection(t *testing.T) {
+	diskdb := rawdb.NewMemoryDatabase()
+	db := NewZkDatabase(diskdb)
+
+	// Hashing the trie between updates leaves unreferenced intermediate roots behind.
+	intermediate, root := makeZkStateWithIntermediate(t, db, 10)
+	db.Reference(root, common.Hash{})
+
+	// The intermediate nodes are kept until the state is released, but are never
+	// flushed, as they aren't referenced.
+	if err := db.Cap(0); err != nil {
+		t.Fatalf("failed to cap: %v", err)
+	}
+	if !hasZkNodeOnDisk(diskdb, root) {
+		t.Fatal("state root not persisted by cap")
+	}
+	if hasZkNodeOnDisk(diskdb, intermediate) {
+		t.Fatal("intermediate root persisted")
+	}
+	if !hasZkNode(db, intermediate) {
+		t.Fatal("intermediate root collected too early")
+	}
+	// A newer state must not release the intermediate nodes of the following ones.
+	next, nextRoot := makeZkStateWithIntermediate(t, db, 12)
+	db.Reference(nextRoot, common.Hash{})
+
+	db.Dereference(root)
+	if hasZkNode(db, intermediate) {
+		t.Fatal("intermediate root not collected")
+	}
+	if !hasZkNode(db, next) {
+		t.Fatal("intermediate root of the newer state collected")
+	}
+	if err := db.Commit(nextRoot, false); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if hasZkNodeOnDisk(diskdb, next) {
+		t.Fatal("intermediate root of the newer state persisted")
+	}
+	if _, nodes, _ := db.Size(); nodes != 0 {
+		t.Fatalf("dirty cache not empty after commit: %v", nodes)
+	}
+	ndb := NewZkDatabase(diskdb)
+	for _, state := range []struct {
+		root     common.Hash
+		accounts int
+	}{{root, 10}, {nextRoot, 12}} {
+		tr, err := NewZkMerkleStateTrie(state.root, ndb)
+		if err != nil {
+			t.Fatalf("failed to open state: %v", err)
+		}
+		for i := 0; i < state.accounts; i++ {
+			if account, err := tr.GetAccount(common.BigToAddress(big.NewInt(int64(i + 1)))); err != nil || account == nil {
+				t.Fatalf("account %d missing: %v", i, err)
+			}
+		}
+	}
+	if _, err := ndb.Get(zkNodeKey(intermediate)); err != zktrie.ErrKeyNotFound {
+		t.Fatalf("unexpected error for collected node: %v", err)
+	}
+}
@@ -19,19 +19,37 @@ type ZkMerkleStateTrie struct {
 }
 
 func NewZkMerkleStateTrie(rootHash common.Hash, db *Database) (*ZkMerkleStateTrie, error) {
-	tree, err := zk.NewMerkleTreeFromHash(zkt.NewHashFromBytes(rootHash.Bytes()), db.Get)
+	return NewZkMerkleStateTrieWithID(TrieID(rootHash), db)
+}
+
+// NewZkMerkleStateTrieWithID opens the zk merkle state trie with the given identifier.
+// With the path-based scheme, the owner of a storage trie is the keccak hash of the
+// account address, the same as the one of the MPT.
+func NewZkMerkleStateTrieWithID(id *ID, db *Database) (*ZkMerkleStateTrie, error) {
+	trie, err := NewZkMerkleTrieWithID(id, db)
+	if err != nil {
+		return nil, err
+	}
+	return newZkMerkleStateTrie(trie, db), nil
+}
+
+// NewZkMerkleStateTrieWithWitness opens the zk merkle state trie with the given
+// identifier, recording the nodes loaded from the database.
+func NewZkMerkleStateTrieWithWitness(id *ID, db *Database) (*ZkMerkleStateTrie, error) {
+	trie, err := NewZkMerkleTrieWithWitness(id, db)
 	if err != nil {
 		return nil, err
 	}
-	return newZkMerkleStateTrie(tree, db), nil
+	return newZkMerkleStateTrie(trie, db), nil
 }
 
 func NewEmptyZkMerkleStateTrie(db *Database) *ZkMerkleStateTrie {
-	return newZkMerkleStateTrie(zk.NewEmptyMerkleTree(), db)
+	trie, _ := NewZkMerkleStateTrieWithID(TrieID(common.Hash{}), db) // opening the empty trie never fails
+	return trie
 }
 
-func newZkMerkleStateTrie(tree *zk.MerkleTree, db *Database) *ZkMerkleStateTrie {
-	trie := &ZkMerkleStateTrie{ZkMerkleTrie: NewZkMerkleTrie(tree, db), preimage: db.preimages}
+func newZkMerkleStateTrie(merkleTrie *ZkMerkleTrie, db *Database) *ZkMerkleStateTrie {
+	trie := &ZkMerkleStateTrie{ZkMerkleTrie: merkleTrie, preimage: db.preimages}
 	trie.logger = log.New("trie", "ZkMerkleStateTrie")
 	trie.transformKey = func(key []byte) ([]byte, error) {
 		sanityCheckByte32Key(key)
@@ -120,5 +138,5 @@ func (z *ZkMerkleStateTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) erro
 }
 
 func (z *ZkMerkleStateTrie) Copy() *ZkMerkleStateTrie {
-	return newZkMerkleStateTrie(z.MerkleTree.Copy(), z.db)
+	return newZkMerkleStateTrie(z.ZkMerkleTrie.Copy(), z.db)
 }
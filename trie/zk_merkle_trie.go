@@ -1,9 +1,13 @@
 package trie
 
 import (
+	"bytes"
+
 	zktrie "github.com/kroma-network/zktrie/trie"
+	zkt "github.com/kroma-network/zktrie/types"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/trie/trienode"
@@ -16,6 +20,16 @@ type ZkMerkleTrie struct {
 	logger            log.Logger
 	transformKey      func(key []byte) ([]byte, error)
 	transformProveKey func(key []byte) []byte
+
+	// witness is the database recording the loaded nodes with the hash-based
+	// scheme, if opted in. It's shared by the copies.
+	witness *zkWitnessDatabase
+
+	// The fields below are only used with the path-based scheme, where the nodes
+	// are stored by owner and path instead of the node hash. They are nil in the
+	// hash-based scheme.
+	reader *trieReader
+	tracer *zkTracer
 }
 
 func (z *ZkMerkleTrie) WithTransformKey(transformKey func(key []byte) ([]byte, error)) *ZkMerkleTrie {
@@ -38,6 +52,90 @@ func NewZkMerkleTrie(merkleTree *zk.MerkleTree, db *Database) *ZkMerkleTrie {
 	}
 }
 
+// NewZkMerkleTrieWithID opens the zk merkle trie with the given identifier. With
+// the path-based scheme, the nodes are resolved by the owner and path, and the
+// dirty nodes are collected into a node set on commit.
+func NewZkMerkleTrieWithID(id *ID, db *Database) (*ZkMerkleTrie, error) {
+	return newZkMerkleTrieWithID(id, db, false)
+}
+
+// NewZkMerkleTrieWithWitness opens the zk merkle trie with the given identifier,
+// recording the nodes loaded from the database, which are returned by Witness.
+func NewZkMerkleTrieWithWitness(id *ID, db *Database) (*ZkMerkleTrie, error) {
+	return newZkMerkleTrieWithID(id, db, true)
+}
+
+// newZkMerkleTrieWithID opens the zk merkle trie with the given identifier. The
+// nodes loaded with the hash-based scheme are recorded if witness is set, while
+// the ones loaded with the path-based scheme are always tracked for commit.
+func newZkMerkleTrieWithID(id *ID, db *Database, witness bool) (*ZkMerkleTrie, error) {
+	root := zkt.NewHashFromBytes(id.Root[:])
+	if db.Scheme() != rawdb.PathScheme {
+		if !witness {
+			tree, err := zk.NewMerkleTreeFromHash(root, db.Get)
+			if err != nil {
+				return nil, err
+			}
+			return NewZkMerkleTrie(tree, db), nil
+		}
+		recorder := newZkWitnessDatabase(db)
+		tree, err := zk.NewMerkleTreeFromHash(root, recorder.Get)
+		if err != nil {
+			return nil, err
+		}
+		trie := NewZkMerkleTrie(tree, db)
+		trie.witness = recorder
+		return trie, nil
+	}
+	// The zero hash is the root of the empty zk trie, there is nothing to read.
+	reader := &trieReader{owner: id.Owner}
+	if id.StateRoot != (common.Hash{}) {
+		var err error
+		if reader, err = newTrieReader(id.StateRoot, id.Owner, db); err != nil {
+			return nil, err
+		}
+	}
+	trie := NewZkMerkleTrie(nil, db)
+	trie.reader, trie.tracer = reader, newZkTracer()
+
+	tree, err := zk.NewMerkleTreeFromPath(root, trie.resolveBlob)
+	if err != nil {
+		return nil, err
+	}
+	trie.MerkleTree = tree
+	return trie, nil
+}
+
+// resolveBlob reads the node blob with the given path and hash from the trie
+// database, tracking it as the origin of the path.
+func (z *ZkMerkleTrie) resolveBlob(path zk.TreePath, hash []byte) ([]byte, error) {
+	blob, err := z.reader.node(path, common.BytesToHash(zkt.ReverseByteOrder(hash)))
+	if err != nil {
+		return nil, err
+	}
+	z.tracer.onRead(path, blob)
+	return blob, nil
+}
+
+// Witness returns a set containing all trie nodes that have been loaded from
+// the database.
+func (z *ZkMerkleTrie) Witness() map[string]struct{} {
+	if z.tracer == nil {
+		if z.witness == nil {
+			return nil
+		}
+		return z.witness.witness()
+	}
+	if len(z.tracer.origin) == 0 {
+		return nil
+	}
+	witness := make(map[string]struct{}, len(z.tracer.origin))
+	for _, blob := range z.tracer.origin {
+		witness[string(blob)] = struct{}{}
+	}
+	return witness
+}
+
 func (z *ZkMerkleTrie) GetNode(compactPath []byte) ([]byte, int, error) {
 	node := z.MerkleTree.GetNodeByPath(compactToHex(compactPath))
 	return node.CanonicalValue(), 0, nil
@@ -97,10 +195,24 @@ func (z *ZkMerkleTrie) Delete(key []byte) error {
 }
 
 func (z *ZkMerkleTrie) Hash() common.Hash {
-	hash, _, _ := z.Commit(false)
+	hash, _ := z.hash()
 	return hash
 }
 
+// hash computes the hashes of all nodes. With the hash-based scheme, the dirty
+// nodes are written into the database at the same time.
+func (z *ZkMerkleTrie) hash() (common.Hash, error) {
+	if z.tracer == nil {
+		hash, _, err := z.Commit(false)
+		return hash, err
+	}
+	if err := z.ComputeAllNodeHash(nil); err != nil {
+		z.logger.Error("failed to compute hash", "error", err)
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(z.RootNode().Hash().Bytes()), nil
+}
+
 func (z *ZkMerkleTrie) MustNodeIterator(start []byte) NodeIterator {
 	if it, err := z.NodeIterator(start); err != nil {
 		z.logger.Error("failed to MustNodeIterator", "error", err, "start", start)
@@ -111,11 +223,39 @@ func (z *ZkMerkleTrie) MustNodeIterator(start []byte) NodeIterator {
 }
 
 func (z *ZkMerkleTrie) NodeIterator(startKey []byte) (NodeIterator, error) {
-	nodeBlobFromTree, nodeBlobToIteratorNode := zkMerkleTreeNodeBlobFunctions(z.db.Get)
+	findBlob := func(_ []byte, key []byte) ([]byte, error) { return z.db.Get(key) }
+	if z.tracer != nil {
+		findBlob = z.nodeBlob
+	}
+	nodeBlobFromTree, nodeBlobToIteratorNode := zkMerkleTreeNodeBlobFunctions(findBlob)
 	return newMerkleTreeIterator(z.Hash(), nodeBlobFromTree, nodeBlobToIteratorNode, startKey), nil
 }
 
+// nodeBlob returns the blob of the node with the given path and hash. The nodes
+// in memory are preferred, since the dirty ones are not in the database until
+// they are committed. The tree is not modified.
+func (z *ZkMerkleTrie) nodeBlob(path []byte, hash []byte) ([]byte, error) {
+	node := z.RootNode()
+	for _, p := range path {
+		parent, ok := node.(*zk.ParentNode)
+		if !ok {
+			break
+		}
+		node = parent.Child(p)
+	}
+	switch node.(type) {
+	case *zk.ParentNode, *zk.LeafNode:
+		if nodeHash := node.Hash(); nodeHash != nil && bytes.Equal(nodeHash[:], hash) {
+			return node.CanonicalValue(), nil
+		}
+	}
+	return z.reader.node(path, common.BytesToHash(zkt.ReverseByteOrder(hash)))
+}
+
 func (z *ZkMerkleTrie) Commit(_ bool) (common.Hash, *trienode.NodeSet, error) {
+	if z.tracer != nil {
+		return z.commitNodes()
+	}
 	if root := z.RootNode().Hash(); root != nil {
 		return common.BytesToHash(root.Bytes()), nil, nil
 	}
@@ -132,6 +272,20 @@ func (z *ZkMerkleTrie) Commit(_ bool) (common.Hash, *trienode.NodeSet, error) {
 	return common.BytesToHash(z.RootNode().Hash().Bytes()), nil, nil
 }
 
+// commitNodes computes the hashes of all nodes and collects the nodes changed
+// since they were loaded, or since the last commit, into a node set.
+func (z *ZkMerkleTrie) commitNodes() (common.Hash, *trienode.NodeSet, error) {
+	root, err := z.hash()
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+	nodes := trienode.NewNodeSet(z.reader.owner)
+	if err := z.tracer.commit(z.RootNode(), nodes); err != nil {
+		return common.Hash{}, nil, err
+	}
+	return root, nodes, nil
+}
+
 func (z *ZkMerkleTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
 	return z.prove(z.transformProveKey(key), proofDb, func(node zk.TreeNode) error {
 		return proofDb.Put(node.Hash()[:], node.CanonicalValue())
@@ -139,7 +293,7 @@ func (z *ZkMerkleTrie) Prove(key []byte, proofDb ethdb.KeyValueWriter) error {
 }
 
 func (z *ZkMerkleTrie) prove(key []byte, proofDb ethdb.KeyValueWriter, writeNode func(zk.TreeNode) error) error {
-	if _, _, err := z.Commit(false); err != nil {
+	if _, err := z.hash(); err != nil {
 		return err
 	} else if err := z.MerkleTree.Prove(key, writeNode); err != nil {
 		return err
@@ -149,5 +303,19 @@ func (z *ZkMerkleTrie) prove(key []byte, proofDb ethdb.KeyValueWriter, writeNode
 }
 
 func (z *ZkMerkleTrie) Copy() *ZkMerkleTrie {
-	return &ZkMerkleTrie{z.MerkleTree.Copy(), z.db, z.logger, z.transformKey, z.transformProveKey}
+	cpy := &ZkMerkleTrie{
+		MerkleTree:        z.MerkleTree.Copy(),
+		db:                z.db,
+		logger:            z.logger,
+		transformKey:      z.transformKey,
+		transformProveKey: z.transformProveKey,
+		witness:           z.witness,
+		reader:            z.reader,
+	}
+	if z.tracer != nil {
+		// The resolver must track the loaded nodes into the tracer of the copy.
+		cpy.tracer = z.tracer.copy()
+		cpy.MerkleTree.WithNodeBlobPathFinder(cpy.resolveBlob)
+	}
+	return cpy
 }
@@ -0,0 +1,74 @@
+package trie
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/trie/zk"
+)
+
+// zkTracer tracks the nodes of a zk merkle tree stored with the path-based scheme.
+//
+// Unlike the MPT, the zk tree doesn't notify node insertions and deletions, and
+// leaves can be moved to another path when a sibling is inserted or deleted. So
+// the tracer records the blob of every node loaded from the database by path, and
+// the changed nodes are determined by comparing the tree with the recorded blobs
+// on commit.
+//
+// Note zkTracer is not thread-safe, callers should be responsible for handling
+// the concurrency issues by themselves.
+type zkTracer struct {
+	origin map[string][]byte // The blobs of the nodes in the database, by path
+}
+
+// newZkTracer initializes the tracer for capturing zk tree changes.
+func newZkTracer() *zkTracer {
+	return &zkTracer{origin: make(map[string][]byte)}
+}
+
+// onRead tracks the newly loaded tree node. Don't change the value outside of
+// function since it's not deep-copied.
+func (t *zkTracer) onRead(path []byte, blob []byte) {
+	t.origin[string(path)] = blob
+}
+
+// commit collects the nodes of the given tree which differ from the recorded
+// ones into the node set, along with the deletions of the recorded paths which
+// are no longer occupied. The node hashes must have been computed. Afterwards,
+// the tree is recorded as the new origin.
+//
+// Unresolved subtrees are skipped, as they can't be changed without being loaded.
+func (t *zkTracer) commit(root zk.TreeNode, set *trienode.NodeSet) error {
+	live := make(map[string][]byte)
+	err := zk.VisitNode(root, func(node zk.TreeNode, path zk.TreePath) error {
+		switch node.(type) {
+		case *zk.ParentNode, *zk.LeafNode:
+			blob := node.CanonicalValue()
+			live[string(path)] = blob
+			if !bytes.Equal(t.origin[string(path)], blob) {
+				set.AddNode(path, trienode.New(common.BytesToHash(node.Hash().Bytes()), blob))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for path := range t.origin {
+		if _, ok := live[path]; !ok {
+			set.AddNode([]byte(path), trienode.NewDeleted())
+		}
+	}
+	t.origin = live
+	return nil
+}
+
+// copy returns a deep copied tracer instance.
+func (t *zkTracer) copy() *zkTracer {
+	origin := make(map[string][]byte, len(t.origin))
+	for path, blob := range t.origin {
+		origin[path] = blob
+	}
+	return &zkTracer{origin: origin}
+}
@@ -38,7 +38,8 @@ var magicHash []byte = []byte("THIS IS THE MAGIC INDEX FOR ZKTRIE")
 // wrap zktrie for trie interface
 type ZkTrie struct {
 	*zktrie.ZkTrie
-	db *Database
+	db      *Database
+	witness *zkWitnessDatabase // The database recording the nodes read, shared by the copies, nil if not recording
 }
 
 func init() {
@@ -64,7 +65,18 @@ func NewZkTrie(root common.Hash, db *Database) (*ZkTrie, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ZkTrie{tr, db}, nil
+	return &ZkTrie{tr, db, nil}, nil
+}
+
+// NewZkTrieWithWitness creates a trie recording the nodes read from the database,
+// which are returned by Witness.
+func NewZkTrieWithWitness(root common.Hash, db *Database) (*ZkTrie, error) {
+	witness := newZkWitnessDatabase(db)
+	tr, err := zktrie.NewZkTrie(*zkt.NewByte32FromBytes(root.Bytes()), witness)
+	if err != nil {
+		return nil, err
+	}
+	return &ZkTrie{tr, db, witness}, nil
 }
 
 func (t *ZkTrie) TryGetNode(compactPath []byte) ([]byte, int, error) { return t.GetNode(compactPath) }
@@ -204,9 +216,19 @@ func (t *ZkTrie) Hash() common.Hash {
 	return hash
 }
 
+// Witness returns a set containing all trie nodes that have been read from
+// the database by the trie and its copies, or nil if the trie was not opened
+// with NewZkTrieWithWitness.
+func (t *ZkTrie) Witness() map[string]struct{} {
+	if t.witness == nil {
+		return nil
+	}
+	return t.witness.witness()
+}
+
 // Copy returns a copy of ZkTrie.
 func (t *ZkTrie) Copy() *ZkTrie {
-	return &ZkTrie{t.ZkTrie.Copy(), t.db}
+	return &ZkTrie{t.ZkTrie.Copy(), t.db, t.witness}
 }
 
 // NodeIterator returns an iterator that returns nodes of the underlying trie. Iteration
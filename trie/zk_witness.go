@@ -0,0 +1,68 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// zkWitnessDatabase wraps the trie database of the zk tries stored with the
+// hash-based scheme, recording the blob of every node read from it. It's only
+// used by the tries opened for a witness, since the recording isn't free.
+//
+// The nodes are resolved by the zk tree lazily, and the copies of a tree share
+// the database with it, so the recorded nodes are protected by a lock.
+type zkWitnessDatabase struct {
+	*Database
+	nodes map[string]struct{}
+	lock  sync.Mutex
+}
+
+// newZkWitnessDatabase wraps the given trie database for recording the nodes.
+func newZkWitnessDatabase(db *Database) *zkWitnessDatabase {
+	return &zkWitnessDatabase{Database: db, nodes: make(map[string]struct{})}
+}
+
+// Get retrieves the node with the given hash, tracking the blob of it. The
+// metadata stored with the keys other than node hashes aren't tracked.
+func (db *zkWitnessDatabase) Get(key []byte) ([]byte, error) {
+	blob, err := db.Database.Get(key)
+	if err != nil || len(key) != common.HashLength {
+		return blob, err
+	}
+	db.lock.Lock()
+	db.nodes[string(blob)] = struct{}{}
+	db.lock.Unlock()
+	return blob, nil
+}
+
+// witness returns a set containing all the nodes read so far.
+func (db *zkWitnessDatabase) witness() map[string]struct{} {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if len(db.nodes) == 0 {
+		return nil
+	}
+	witness := make(map[string]struct{}, len(db.nodes))
+	for node := range db.nodes {
+		witness[node] = struct{}{}
+	}
+	return witness
+}
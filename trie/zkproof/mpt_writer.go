@@ -0,0 +1,354 @@
+package zkproof
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// MPTPath is the proof of a key in the MPT, all the nodes from the root down to
+// the leaf of the key, or to the node proving its absence.
+type MPTPath struct {
+	Root  hexutil.Bytes   `json:"root"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// MPTStorageTrace records the updating on the account trie and (if changed) the
+// storage trie of the MPT, represented by the [before, after] updating of the
+// MPTPath among the tries and the account. It's the MPT counterpart of the
+// StorageTrace.
+type MPTStorageTrace struct {
+	Address         hexutil.Bytes    `json:"address"`
+	AccountKey      hexutil.Bytes    `json:"accountKey"`
+	AccountPath     [2]*MPTPath      `json:"accountPath"`
+	AccountUpdate   [2]*StateAccount `json:"accountUpdate"`
+	StateKey        hexutil.Bytes    `json:"stateKey,omitempty"`
+	CommonStateRoot hexutil.Bytes    `json:"commonStateRoot,omitempty"` //CommonStateRoot is used if there is no update on state storage
+	StatePath       [2]*MPTPath      `json:"statePath,omitempty"`
+	StateUpdate     [2]*StateStorage `json:"stateUpdate,omitempty"`
+}
+
+type mptProofWriter struct {
+	tracingTrie         *trie.StateTrie
+	tracingStorageTries map[common.Address]*trie.StateTrie
+	tracingAccounts     map[common.Address]*types.StateAccount
+}
+
+func (w *mptProofWriter) TracingAccounts() map[common.Address]*types.StateAccount {
+	return w.tracingAccounts
+}
+
+// NewMPTProofWriter resumes the account trie and the storage tries of the MPT
+// before the block from the proofs of the storage trace, including the deletion
+// proofs needed to collapse the tries on deletions.
+func NewMPTProofWriter(storage *types.StorageTrace) (*mptProofWriter, error) {
+	underlayerDb := rawdb.NewMemoryDatabase()
+	resume := func(proof []hexutil.Bytes) {
+		for _, node := range proof {
+			rawdb.WriteLegacyTrieNode(underlayerDb, crypto.Keccak256Hash(node), node)
+		}
+	}
+	for _, proof := range storage.Proofs {
+		resume(proof)
+	}
+	for _, stgLists := range storage.StorageProofs {
+		for _, proof := range stgLists {
+			resume(proof)
+		}
+	}
+	resume(storage.DeletionProofs)
+
+	db := trie.NewDatabase(underlayerDb, trie.HashDefaults)
+	tr, err := trie.NewStateTrie(trie.StateTrieID(storage.RootBefore), db)
+	if err != nil {
+		return nil, fmt.Errorf("mpt create failure: %s", err)
+	}
+	accounts := make(map[common.Address]*types.StateAccount)
+	for addrs := range storage.Proofs {
+		addr := common.HexToAddress(addrs)
+		acc, err := tr.GetAccount(addr)
+		if err != nil {
+			return nil, fmt.Errorf("can not resume proof for address %s: %s", addrs, err)
+		}
+		accounts[addr] = acc
+	}
+
+	storages := make(map[common.Address]*trie.StateTrie)
+	for addrs := range storage.StorageProofs {
+		addr := common.HexToAddress(addrs)
+		accState, existed := accounts[addr]
+		if !existed {
+			// trace is malformed but currently we just warn about that, as the zk trie does
+			continue
+		}
+		root := types.EmptyRootHash
+		if accState != nil {
+			root = accState.Root
+		}
+		storages[addr], err = trie.NewStateTrie(trie.StorageTrieID(storage.RootBefore, crypto.Keccak256Hash(addr.Bytes()), root), db)
+		if err != nil {
+			return nil, fmt.Errorf("mpt create failure for storage in addr <%s>: %s", addrs, err)
+		}
+	}
+	return &mptProofWriter{
+		tracingTrie:         tr,
+		tracingStorageTries: storages,
+		tracingAccounts:     accounts,
+	}, nil
+}
+
+// proveMPTPath proves the hashed key in the trie.
+func proveMPTPath(tr *trie.StateTrie, key []byte) (*MPTPath, error) {
+	var proof proofList
+	if err := tr.Prove(crypto.Keccak256(key), &proof); err != nil {
+		return nil, err
+	}
+	root := tr.Hash()
+	path := &MPTPath{Root: root.Bytes(), Proof: make([]hexutil.Bytes, len(proof))}
+	for i, node := range proof {
+		path.Proof[i] = node
+	}
+	return path, nil
+}
+
+// update traced account state, and return the corresponding trace object which
+// is still opened for more infos
+func (w *mptProofWriter) traceAccountUpdate(addr common.Address, updateAccData func(*types.StateAccount) (*types.StateAccount, error)) (*MPTStorageTrace, error) {
+	accDataBefore, existed := w.tracingAccounts[addr]
+	if !existed {
+		return nil, fmt.Errorf("no initialized status for account %s", addr)
+	}
+	out := &MPTStorageTrace{
+		Address:    addr.Bytes(),
+		AccountKey: crypto.Keccak256(addr.Bytes()),
+	}
+
+	var err error
+	if out.AccountPath[0], err = proveMPTPath(w.tracingTrie, addr.Bytes()); err != nil {
+		return nil, fmt.Errorf("prove BEFORE state for <%x> fail: %s", addr.Bytes(), err)
+	}
+	if accDataBefore != nil {
+		out.AccountUpdate[0] = &StateAccount{
+			Nonce:    int(accDataBefore.Nonce),
+			Balance:  (*hexutil.Big)(new(big.Int).Set(accDataBefore.Balance)),
+			CodeHash: accDataBefore.CodeHash,
+		}
+	}
+
+	accData, err := updateAccData(accDataBefore)
+	if err != nil {
+		return nil, err
+	}
+	if accData != nil {
+		out.AccountUpdate[1] = &StateAccount{
+			Nonce:    int(accData.Nonce),
+			Balance:  (*hexutil.Big)(new(big.Int).Set(accData.Balance)),
+			CodeHash: accData.CodeHash,
+		}
+		if err := w.tracingTrie.UpdateAccount(addr, accData); err != nil {
+			return nil, fmt.Errorf("update mpt account state fail: %s", err)
+		}
+		w.tracingAccounts[addr] = accData
+	} else if accDataBefore != nil {
+		if err := w.tracingTrie.DeleteAccount(addr); err != nil {
+			return nil, fmt.Errorf("delete mpt account state fail: %s", err)
+		}
+		w.tracingAccounts[addr] = nil
+	} // notice if both before/after is nil, we do not touch the trie
+
+	if out.AccountPath[1], err = proveMPTPath(w.tracingTrie, addr.Bytes()); err != nil {
+		return nil, fmt.Errorf("prove AFTER state for <%x> fail: %s", addr.Bytes(), err)
+	}
+	return out, nil
+}
+
+// update traced storage state, and return the corresponding trace object
+func (w *mptProofWriter) traceStorageUpdate(addr common.Address, key, value common.Hash) (*MPTStorageTrace, error) {
+	tr := w.tracingStorageTries[addr]
+	if tr == nil {
+		return nil, fmt.Errorf("no trace storage trie for %s", addr)
+	}
+	enc, err := tr.GetStorage(addr, key.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("read mpt storage fail: %s", err)
+	}
+	valueBefore := common.BytesToHash(enc)
+
+	var (
+		statePath   [2]*MPTPath
+		stateUpdate [2]*StateStorage
+	)
+	if valueBefore != (common.Hash{}) {
+		stateUpdate[0] = &StateStorage{Key: key.Bytes(), Value: valueBefore.Bytes()}
+	}
+	if statePath[0], err = proveMPTPath(tr, key.Bytes()); err != nil {
+		return nil, fmt.Errorf("prove BEFORE storage state fail: %s", err)
+	}
+	if value != (common.Hash{}) {
+		if err := tr.UpdateStorage(addr, key.Bytes(), common.TrimLeftZeroes(value.Bytes())); err != nil {
+			return nil, fmt.Errorf("update mpt storage fail: %s", err)
+		}
+		stateUpdate[1] = &StateStorage{Key: key.Bytes(), Value: value.Bytes()}
+	} else if err := tr.DeleteStorage(addr, key.Bytes()); err != nil {
+		return nil, fmt.Errorf("delete mpt storage fail: %s", err)
+	}
+	if statePath[1], err = proveMPTPath(tr, key.Bytes()); err != nil {
+		return nil, fmt.Errorf("prove AFTER storage state fail: %s", err)
+	}
+
+	out, err := w.traceAccountUpdate(addr, func(acc *types.StateAccount) (*types.StateAccount, error) {
+		if acc == nil {
+			// in case we read an unexist account
+			if value != (common.Hash{}) {
+				return nil, fmt.Errorf("write to an unexist account [%s] which is not allowed", addr)
+			}
+			return nil, nil
+		}
+		//sanity check
+		if rootBefore := common.BytesToHash(statePath[0].Root); acc.Root != rootBefore {
+			return nil, fmt.Errorf("unexpected storage root before: [%s] vs [%s]", acc.Root, rootBefore)
+		}
+		return &types.StateAccount{
+			Nonce:    acc.Nonce,
+			Balance:  acc.Balance,
+			CodeHash: acc.CodeHash,
+			Root:     common.BytesToHash(statePath[1].Root),
+		}, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update account %s in SSTORE fail: %s", addr, err)
+	}
+
+	out.StateKey = crypto.Keccak256(key.Bytes())
+	if stateUpdate[0] == nil && stateUpdate[1] == nil {
+		// it occurs when we are handling SLOAD with non-exist value
+		stateUpdate[1] = &StateStorage{Key: key.Bytes(), Value: common.Hash{}.Bytes()}
+		stateUpdate[0] = stateUpdate[1]
+	}
+	out.StatePath = statePath
+	out.StateUpdate = stateUpdate
+	return out, nil
+}
+
+func (w *mptProofWriter) HandleNewState(accountState *types.AccountWrapper) (*MPTStorageTrace, error) {
+	if accountState.Storage != nil {
+		key := common.HexToHash(accountState.Storage.Key)
+		value := common.HexToHash(accountState.Storage.Value)
+		return w.traceStorageUpdate(accountState.Address, key, value)
+	}
+	stateRoot := types.EmptyRootHash
+	accData := getAccountDataFromLogState(accountState)
+
+	out, err := w.traceAccountUpdate(accountState.Address, func(accBefore *types.StateAccount) (*types.StateAccount, error) {
+		if accBefore != nil {
+			stateRoot = accBefore.Root
+		}
+		// we need to restore stateRoot from before
+		if accData != nil {
+			accData.Root = stateRoot
+		}
+		return accData, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update account state %s fail: %s", accountState.Address, err)
+	}
+	out.CommonStateRoot = stateRoot.Bytes()
+	return out, nil
+}
+
+// HandleBlockTraceMPT replays the ordered updates of the block trace on the MPT
+// resumed from its storage trace, recording the proofs before and after every
+// update.
+func HandleBlockTraceMPT(block *types.BlockTrace, ordererScheme MPTWitnessType) (outTrace []*MPTStorageTrace, err error) {
+	writer, err := NewMPTProofWriter(block.StorageTrace)
+	if err != nil {
+		return nil, err
+	}
+	// The orderers panic on malformed traces, which must not bring the node down.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed block trace: %v", r)
+		}
+	}()
+	opDisp, err := orderBlockOps(block, writer.tracingAccounts, ordererScheme)
+	if err != nil {
+		return nil, err
+	}
+	for op := opDisp.next(); op != nil; op = opDisp.next() {
+		trace, err := writer.HandleNewState(op)
+		if err != nil {
+			return nil, err
+		}
+		outTrace = append(outTrace, trace)
+	}
+
+	if finalHash := writer.tracingTrie.Hash(); finalHash != block.StorageTrace.RootAfter {
+		return outTrace, fmt.Errorf("unmatch hash: [%x] vs [%x]", finalHash, block.StorageTrace.RootAfter)
+	}
+	return outTrace, nil
+}
+
+// MPTDeletions returns the accounts and the storage slots written empty by the
+// ordered updates of the block trace, which collapse the MPT if they exist at the
+// time. The natural order is followed, since it applies every update, so that the
+// keys written empty in any other order are included as well.
+func MPTDeletions(block *types.BlockTrace) (accounts map[common.Address]struct{}, slots map[common.Address]map[common.Hash]struct{}, err error) {
+	// The orderers panic on malformed traces, which must not bring the node down.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed block trace: %v", r)
+		}
+	}()
+	opDisp, err := orderBlockOps(block, nil, MPTWitnessNatural)
+	if err != nil {
+		return nil, nil, err
+	}
+	accounts = make(map[common.Address]struct{})
+	slots = make(map[common.Address]map[common.Hash]struct{})
+	for op := opDisp.next(); op != nil; op = opDisp.next() {
+		if op.Storage == nil {
+			if isDeletedAccount(op) {
+				accounts[op.Address] = struct{}{}
+			}
+			continue
+		}
+		if common.HexToHash(op.Storage.Value) != (common.Hash{}) {
+			continue
+		}
+		if slots[op.Address] == nil {
+			slots[op.Address] = make(map[common.Hash]struct{})
+		}
+		slots[op.Address][common.HexToHash(op.Storage.Key)] = struct{}{}
+	}
+	return accounts, slots, nil
+}
+
+// FillBlockTraceForMPTStorageTrace fills the MPT witness of the block trace with
+// the ordered updates on the MPT, for the blocks after the KromaMPT fork.
+func FillBlockTraceForMPTStorageTrace(order MPTWitnessType, block *types.BlockTrace) error {
+	if order == MPTWitnessNothing {
+		return nil
+	}
+
+	trace, err := HandleBlockTraceMPT(block, order)
+	if err != nil {
+		return err
+	}
+
+	msg, err := json.Marshal(trace)
+	if err != nil {
+		return err
+	}
+
+	rawmsg := json.RawMessage(msg)
+
+	block.MPTWitness = &rawmsg
+	return nil
+}
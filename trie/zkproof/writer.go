@@ -673,9 +673,10 @@ func handleLogs(od opOrderer, currentContract common.Address, logs []*types.Stru
 			// notice the log only provide the value BEFORE store and it is not suitable for our protocol,
 			// here we change it into value AFTER update
 			before := accountState.Storage
+			// the stack items are quantities, which are unified into 32 bytes
 			accountState.Storage = &types.StorageWrapper{
-				Key:   sLog.Stack[len(sLog.Stack)-1],
-				Value: sLog.Stack[len(sLog.Stack)-2],
+				Key:   common.HexToHash(sLog.Stack[len(sLog.Stack)-1]).String(),
+				Value: common.HexToHash(sLog.Stack[len(sLog.Stack)-2]).String(),
 			}
 			od.absorbStorage(accountState, before)
 
@@ -736,6 +737,31 @@ func HandleBlockTraceEx(block *types.BlockTrace, ordererScheme MPTWitnessType) (
 		return nil, err
 	}
 
+	opDisp, err := orderBlockOps(block, writer.tracingAccounts, ordererScheme)
+	if err != nil {
+		return nil, err
+	}
+	var outTrace []*StorageTrace
+
+	for op := opDisp.next(); op != nil; op = opDisp.next() {
+		trace, err := writer.HandleNewState(op)
+		if err != nil {
+			return nil, err
+		}
+		outTrace = append(outTrace, trace)
+	}
+
+	finalHash := writer.tracingZktrie.Hash()
+	if !bytes.Equal(finalHash.Bytes(), block.StorageTrace.RootAfter.Bytes()) {
+		return outTrace, fmt.Errorf("unmatch hash: [%x] vs [%x]", finalHash.Bytes(), block.StorageTrace.RootAfter.Bytes())
+	}
+
+	return outTrace, nil
+}
+
+// orderBlockOps orders the updates on the state made by the block with the
+// given scheme, starting from the traced accounts.
+func orderBlockOps(block *types.BlockTrace, tracingAccounts map[common.Address]*types.StateAccount, ordererScheme MPTWitnessType) (opIterator, error) {
 	var od opOrderer
 	switch ordererScheme {
 	case MPTWitnessNothing:
@@ -743,7 +769,7 @@ func HandleBlockTraceEx(block *types.BlockTrace, ordererScheme MPTWitnessType) (
 	case MPTWitnessNatural:
 		od = &simpleOrderer{}
 	case MPTWitnessRWTbl:
-		od = NewRWTblOrderer(writer.tracingAccounts)
+		od = NewRWTblOrderer(tracingAccounts)
 	default:
 		return nil, fmt.Errorf("unrecognized scheme %d", ordererScheme)
 	}
@@ -754,27 +780,11 @@ func HandleBlockTraceEx(block *types.BlockTrace, ordererScheme MPTWitnessType) (
 
 	// notice some coinbase addr (like all zero) is in fact not exist and should not be update
 	// TODO: not a good solution, just for patch ...
-	if coinbaseData := writer.tracingAccounts[block.Coinbase.Address]; coinbaseData != nil {
+	if coinbaseData := tracingAccounts[block.Coinbase.Address]; coinbaseData != nil {
 		od.absorb(block.Coinbase)
 	}
 
-	opDisp := od.end_absorb()
-	var outTrace []*StorageTrace
-
-	for op := opDisp.next(); op != nil; op = opDisp.next() {
-		trace, err := writer.HandleNewState(op)
-		if err != nil {
-			return nil, err
-		}
-		outTrace = append(outTrace, trace)
-	}
-
-	finalHash := writer.tracingZktrie.Hash()
-	if !bytes.Equal(finalHash.Bytes(), block.StorageTrace.RootAfter.Bytes()) {
-		return outTrace, fmt.Errorf("unmatch hash: [%x] vs [%x]", finalHash.Bytes(), block.StorageTrace.RootAfter.Bytes())
-	}
-
-	return outTrace, nil
+	return od.end_absorb(), nil
 }
 
 func FillBlockTraceForMPTWitness(order MPTWitnessType, block *types.BlockTrace) error {